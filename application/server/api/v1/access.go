@@ -0,0 +1,183 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkDatasetAccess 查询用户能否下载数据集 (受限数据集需申请获批)
+func checkDatasetAccess(owner, name, user string) (bool, error) {
+	res, err := bc.ChannelQuery("checkDatasetAccess", [][]byte{
+		[]byte(owner),
+		[]byte(name),
+		[]byte(user),
+	})
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(string(res.Payload))
+}
+
+func SetDatasetGated(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string `json:"owner" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+		Gated    bool   `json:"gated"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute("setDatasetGated", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(body.Operator),
+		[]byte(strconv.FormatBool(body.Gated)),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func CreateAccessRequest(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner         string `json:"owner" binding:"required"`
+		Name          string `json:"name" binding:"required"`
+		User          string `json:"user" binding:"required"`
+		Justification string `json:"justification" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute("createAccessRequest", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(body.User),
+		[]byte(body.Justification),
+		[]byte(utils.GetTimeString()),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+// reviewAccessRequest 审批访问申请 (approveAccessRequest / denyAccessRequest)
+func reviewAccessRequest(c *gin.Context, fcn string) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string `json:"owner" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		User     string `json:"user" binding:"required"`
+		Reviewer string `json:"reviewer" binding:"required"`
+		Comment  string `json:"comment"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute(fcn, [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(body.User),
+		[]byte(body.Reviewer),
+		[]byte(body.Comment),
+		[]byte(utils.GetTimeString()),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func ApproveAccessRequest(c *gin.Context) {
+	reviewAccessRequest(c, "approveAccessRequest")
+}
+
+func DenyAccessRequest(c *gin.Context) {
+	reviewAccessRequest(c, "denyAccessRequest")
+}
+
+func QueryAccessRequestsByDataset(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner  string `json:"owner" binding:"required"`
+		Name   string `json:"name" binding:"required"`
+		Status string `json:"status"` // 状态过滤，为空时返回全部
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryAccessRequestsByDataset", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(body.Status),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var requests []model.AccessRequest
+	if err = json.Unmarshal(res.Payload, &requests); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", requests)
+}
+
+func QueryAccessRequestsByUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryAccessRequestsByUser", [][]byte{[]byte(body.User)})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var requests []model.AccessRequest
+	if err = json.Unmarshal(res.Payload, &requests); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", requests)
+}
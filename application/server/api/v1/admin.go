@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"application/pkg/app"
+	"application/pkg/cron"
+	"application/pkg/fsck"
+	"application/sql"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunFsck 在后台开始一次文件完整性检查，结果通过 QueryFsckRuns 查询
+func RunFsck(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Quarantine bool `json:"quarantine"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	if err := fsck.Start(body.Quarantine, cron.SaveFsck); err != nil {
+		appG.Response(http.StatusConflict, "失败", err.Error())
+		return
+	}
+
+	appG.Response(http.StatusAccepted, "成功", "文件检查已开始")
+}
+
+func QueryFsckRuns(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Limit int `json:"limit"` // 默认 20
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if body.Limit <= 0 {
+		body.Limit = 20
+	}
+
+	runs, err := sql.QueryFsckRuns(body.Limit)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", runs)
+}
+
+func QueryFsckIssues(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		RunID uint   `json:"run_id" binding:"required"`
+		Kind  string `json:"kind"` // 为空时返回全部
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	issues, err := sql.QueryBlobIssues(body.RunID, body.Kind)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", issues)
+}
@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"application/pkg/analytics"
+	"application/pkg/app"
+	"application/sql"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// analyticsQuery 统计接口的公共参数
+type analyticsQuery struct {
+	Owner    string `json:"owner" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Operator string `json:"operator" binding:"required"` // 数据集所有者或组织管理员
+	Interval string `json:"interval"`                    // day (默认) 或 week
+	From     string `json:"from"`                        // 开始日期 YYYY-MM-DD，默认结束日期前 29 天
+	To       string `json:"to"`                          // 结束日期 YYYY-MM-DD (含)，默认今天
+	Top      int    `json:"top"`                         // 返回的下载者数量，默认 10
+}
+
+// buildReport 校验权限并汇总下载统计
+func buildReport(query analyticsQuery) (*analytics.Report, int, error) {
+	if ok, err := canManageDataset(query.Owner, query.Operator); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("调用智能合约出错: %s", err)
+	} else if !ok {
+		return nil, http.StatusForbidden, fmt.Errorf("只有数据集管理者可以查看下载统计")
+	}
+	if query.Interval != "" && query.Interval != analytics.IntervalDay && query.Interval != analytics.IntervalWeek {
+		return nil, http.StatusBadRequest, fmt.Errorf("不支持的统计周期: %s", query.Interval)
+	}
+	if query.Top <= 0 {
+		query.Top = 10
+	}
+
+	from, to, err := analytics.ParseRange(query.From, query.To)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	dataset, err := queryDataset(query.Owner, query.Name)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	events, err := sql.QueryDownloadEvents(query.Owner, query.Name, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("数据库出错: %s", err)
+	}
+	report, err := analytics.Build(dataset, events, query.Interval, from, to, query.Top)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return report, http.StatusOK, nil
+}
+
+func QueryDatasetAnalytics(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body analyticsQuery
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	report, code, err := buildReport(body)
+	if err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", report)
+}
+
+// ExportDatasetAnalytics 将下载统计的一部分导出为 CSV
+func ExportDatasetAnalytics(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		analyticsQuery
+		Section string `json:"section"` // series (默认) | versions | files | users
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if body.Section == "" {
+		body.Section = analytics.SectionSeries
+	}
+	switch body.Section {
+	case analytics.SectionSeries, analytics.SectionVersions, analytics.SectionFiles, analytics.SectionUsers:
+	default:
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("不支持的导出内容: %s", body.Section))
+		return
+	}
+
+	report, code, err := buildReport(body.analyticsQuery)
+	if err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
+
+	setAttachmentHeader(c, fmt.Sprintf("%s-%s-%s-%s_%s.csv", report.Owner, report.Name, body.Section, report.From, report.To))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := analytics.WriteCSV(c.Writer, report, body.Section); err != nil {
+		c.Error(err)
+	}
+}
+
+// BackfillAnalytics 将链上已有的下载记录导入统计
+func BackfillAnalytics(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	imported, err := analytics.Backfill()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("已导入 %d 条记录后出错: %s", imported, err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", imported)
+}
@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"application/conf"
+	"application/model"
+	"application/pkg/blob"
+	"archive/tar"
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// 压缩包格式
+const (
+	archiveZip = "zip"
+	archiveTar = "tar"
+)
+
+// validFilePath 检查文件名是否为规范的相对路径 (与链码校验规则一致)
+func validFilePath(name string) bool {
+	if name == "" || len(name) > 255 || strings.HasPrefix(name, "/") || path.Clean(name) != name {
+		return false
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." || len(segment) > 64 || strings.ContainsAny(segment, `<>:;,?"*|\`) {
+			return false
+		}
+	}
+	return true
+}
+
+// archiveWriter 压缩包写入器
+type archiveWriter interface {
+	add(name string, size int64, r io.Reader) error
+	Close() error
+}
+
+type zipArchive struct{ *zip.Writer }
+
+func (a zipArchive) add(name string, size int64, r io.Reader) error {
+	w, err := a.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+type tarArchive struct{ *tar.Writer }
+
+func (a tarArchive) add(name string, size int64, r io.Reader) error {
+	if err := a.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(a.Writer, r)
+	return err
+}
+
+// writeArchive 将文件打包到临时文件，返回文件路径与压缩包的 SHA-256
+// 文件名中的目录结构原样保留
+// 开启 verify_on_read 时逐个校验文件哈希，校验失败返回 *blob.CorruptError
+func writeArchive(files []model.DatasetFile, format string) (string, string, error) {
+	out, err := os.CreateTemp("data", "download-*."+format)
+	if err != nil {
+		return "", "", fmt.Errorf("创建压缩文件出错: %s", err)
+	}
+	filePath := out.Name()
+	defer out.Close()
+
+	hash := sha256.New()
+	w := io.MultiWriter(out, hash)
+	var archive archiveWriter
+	if format == archiveTar {
+		archive = tarArchive{tar.NewWriter(w)}
+	} else {
+		archive = zipArchive{zip.NewWriter(w)}
+	}
+
+	for _, file := range files {
+		if err := addArchiveEntry(archive, file); err != nil {
+			os.Remove(filePath)
+			return "", "", err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		os.Remove(filePath)
+		return "", "", fmt.Errorf("写入压缩文件出错: %s", err)
+	}
+
+	return filePath, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func addArchiveEntry(archive archiveWriter, file model.DatasetFile) error {
+	fileReader, err := os.Open(blob.Path(file.Hash))
+	if err != nil {
+		return fmt.Errorf("打开文件出错: %s", err)
+	}
+	defer fileReader.Close()
+	info, err := fileReader.Stat()
+	if err != nil {
+		return fmt.Errorf("读取文件出错: %s", err)
+	}
+
+	var reader io.Reader = fileReader
+	if conf.Conf.DownloadConfig.VerifyOnRead {
+		reader = blob.NewVerifyingReader(fileReader, file.Hash)
+	}
+	if err := archive.add(file.FileName, info.Size(), reader); err != nil {
+		var corrupt *blob.CorruptError
+		if errors.As(err, &corrupt) {
+			return corrupt
+		}
+		return fmt.Errorf("写入压缩文件出错: %s", err)
+	}
+	return nil
+}
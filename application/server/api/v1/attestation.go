@@ -0,0 +1,181 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/attestation"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryAttesters 从链上查询认证者列表
+func queryAttesters() ([]model.Attester, error) {
+	var attesters []model.Attester
+	res, err := bc.ChannelQuery("queryAttesters", nil)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(res.Payload, &attesters)
+	return attesters, err
+}
+
+// queryAttestations 从链上查询数据集版本的认证
+func queryAttestations(owner, name string, version int) ([]model.Attestation, error) {
+	var attestations []model.Attestation
+	res, err := bc.ChannelQuery("queryAttestationsByVersion", [][]byte{
+		[]byte(owner),
+		[]byte(name),
+		[]byte(strconv.Itoa(version)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(res.Payload, &attestations)
+	return attestations, err
+}
+
+// updateAttester 授予或撤销认证者角色，只通过管理接口以平台管理员身份操作
+func updateAttester(c *gin.Context, fcn string) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute(fcn, [][]byte{
+		[]byte(model.AdminUser),
+		[]byte(body.User),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func GrantAttester(c *gin.Context) {
+	updateAttester(c, "grantAttester")
+}
+
+func RevokeAttester(c *gin.Context) {
+	updateAttester(c, "revokeAttester")
+}
+
+func QueryAttesters(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	attesters, err := queryAttesters()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", attesters)
+}
+
+func CreateAttestation(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string `json:"owner" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		Version  int    `json:"version"` // 版本序号，从 0 开始
+		Attester string `json:"attester" binding:"required"`
+		Claim    string `json:"claim" binding:"required"`    // 认证类型 (如 pii-scan)
+		Result   string `json:"result" binding:"required"`   // pass/warn/fail
+		Evidence string `json:"evidence" binding:"required"` // 证据的 SHA-256 哈希
+		Details  string `json:"details"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelExecute("createAttestation", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(strconv.Itoa(body.Version)),
+		[]byte(body.Attester),
+		[]byte(body.Claim),
+		[]byte(body.Result),
+		[]byte(body.Evidence),
+		[]byte(body.Details),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var created model.Attestation
+	if err = json.Unmarshal(res.Payload, &created); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", created)
+}
+
+// QueryAttestations 查询数据集版本的全部认证
+func QueryAttestations(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner   string `json:"owner" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		Version int    `json:"version"` // 版本序号，从 0 开始
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	attestations, err := queryAttestations(body.Owner, body.Name, body.Version)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", attestations)
+}
+
+// QueryAttestationBadges 按认证类型汇总数据集版本的认证，只计入当前认证者的认证
+func QueryAttestationBadges(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner   string `json:"owner" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		Version int    `json:"version"` // 版本序号，从 0 开始
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	attestations, err := queryAttestations(body.Owner, body.Name, body.Version)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	attesters, err := queryAttesters()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	active := map[string]bool{}
+	for _, attester := range attesters {
+		active[attester.User] = true
+	}
+
+	appG.Response(http.StatusOK, "成功", attestation.Badges(attestations, active))
+}
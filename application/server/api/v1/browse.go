@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"application/model"
+	"application/pkg/app"
+	"fmt"
+	"sort"
+	"strings"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// browseEntry 目录中的一项
+type browseEntry struct {
+	Name  string             `json:"name"`            // 本级名称
+	Path  string             `json:"path"`            // 完整路径
+	Type  string             `json:"type"`            // dir 或 file
+	Files int                `json:"files,omitempty"` // 目录下 (含子目录) 的文件数
+	File  *model.DatasetFile `json:"file,omitempty"`  // 文件信息
+	Split string             `json:"split,omitempty"` // 文件所属的数据划分
+}
+
+// listDirectory 列出版本中某个目录的直接子项，目录在前，按名称排序
+func listDirectory(version model.Version, dir string) ([]browseEntry, bool) {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+
+	found := dir == ""
+	dirs := map[string]*browseEntry{}
+	var entries []browseEntry
+	for i := range version.Files {
+		file := version.Files[i]
+		if !strings.HasPrefix(file.FileName, prefix) {
+			continue
+		}
+		found = true
+		rest := file.FileName[len(prefix):]
+		if i := strings.Index(rest, "/"); i >= 0 {
+			name := rest[:i]
+			if entry, ok := dirs[name]; ok {
+				entry.Files++
+			} else {
+				dirs[name] = &browseEntry{Name: name, Path: prefix + name, Type: "dir", Files: 1}
+			}
+			continue
+		}
+		entries = append(entries, browseEntry{
+			Name:  rest,
+			Path:  file.FileName,
+			Type:  "file",
+			File:  &file,
+			Split: versionSplit(version, file),
+		})
+	}
+
+	dirEntries := []browseEntry{}
+	for _, entry := range dirs {
+		dirEntries = append(dirEntries, *entry)
+	}
+	sort.Slice(dirEntries, func(i, j int) bool { return dirEntries[i].Name < dirEntries[j].Name })
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return append(dirEntries, entries...), found
+}
+
+func BrowseVersion(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner   string `json:"owner" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		Version int    `json:"version"` // 版本序号，从 0 开始
+		Path    string `json:"path"`    // 目录路径，为空表示根目录
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	dir := strings.Trim(body.Path, "/")
+	if dir != "" && !validFilePath(dir) {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("目录路径格式错误: %s", body.Path))
+		return
+	}
+
+	dataset, err := queryDataset(body.Owner, body.Name)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	if dataset.Deleted {
+		appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+		return
+	}
+	if body.Version < 0 || body.Version >= len(dataset.Versions) {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("版本不存在: %d", body.Version))
+		return
+	}
+
+	entries, ok := listDirectory(dataset.Versions[body.Version], dir)
+	if !ok {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("目录不存在: %s", dir))
+		return
+	}
+
+	type listing struct {
+		Path    string        `json:"path"`
+		Entries []browseEntry `json:"entries"`
+	}
+	appG.Response(http.StatusOK, "成功", listing{Path: dir, Entries: entries})
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/card"
+	"application/pkg/license"
+	"application/pkg/utils"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestURL 返回当前请求的完整地址 (不含查询参数)
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, c.Request.URL.Path)
+}
+
+// buildCard 汇总链上数据集、数据库元数据与文件信息
+func buildCard(owner, name string) (*card.Card, int, error) {
+	dataset, err := queryDataset(owner, name)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	if dataset.Deleted {
+		return nil, http.StatusNotFound, fmt.Errorf("该数据集已被删除")
+	}
+
+	cd := &card.Card{
+		Owner:       dataset.Owner,
+		Name:        dataset.Name,
+		Versions:    dataset.Versions,
+		License:     dataset.License,
+		LicenseName: license.Name(dataset.License),
+		Gated:       dataset.Gated,
+	}
+
+	metadata, err := sql.GetMetadata(owner, name)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("数据库出错: %s", err)
+	}
+	if metadata != nil {
+		cd.Metadata = metadata.Metadata
+		cd.Downloads = metadata.Downloads
+	}
+
+	latest := cd.Latest()
+	if latest == nil {
+		return cd, http.StatusOK, nil
+	}
+
+	// 查询文件大小
+	hashes := make([]string, 0, len(latest.Files))
+	for _, file := range latest.Files {
+		hashes = append(hashes, file.Hash)
+	}
+	res, err := bc.ChannelQuery("queryFiles", [][]byte{[]byte(utils.ToJson(hashes))})
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	var files []model.File
+	if err := json.Unmarshal(res.Payload, &files); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("反序列化出错: %s", err)
+	}
+	sizes := map[string]int64{}
+	for _, file := range files {
+		sizes[file.Hash] = file.Size
+	}
+
+	for _, file := range latest.Files {
+		size, ok := sizes[file.Hash]
+		if !ok {
+			size = -1
+		}
+		stats, err := savedFileStats(file)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		cd.Files = append(cd.Files, card.File{
+			DatasetFile: file,
+			Size:        size,
+			Split:       versionSplit(*latest, file),
+			Stats:       stats,
+		})
+	}
+	return cd, http.StatusOK, nil
+}
+
+// QueryDatasetCard 导出数据集卡片
+// GET /dataset/card/:owner/:name?format=markdown|croissant|dcat
+func QueryDatasetCard(c *gin.Context) {
+	appG := app.Gin{C: c}
+	format := c.DefaultQuery("format", card.FormatMarkdown)
+	if format != card.FormatMarkdown && format != card.FormatCroissant && format != card.FormatDCAT {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("不支持的格式: %s", format))
+		return
+	}
+
+	cd, code, err := buildCard(c.Param("owner"), c.Param("name"))
+	if err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
+	cd.URL = requestURL(c)
+
+	switch format {
+	case card.FormatMarkdown:
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(card.Markdown(*cd)))
+	case card.FormatCroissant:
+		c.Data(http.StatusOK, "application/ld+json", utils.ToJson(card.Croissant(*cd)))
+	case card.FormatDCAT:
+		c.Data(http.StatusOK, "application/ld+json", utils.ToJson(card.DCAT(*cd)))
+	}
+}
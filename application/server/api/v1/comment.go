@@ -0,0 +1,360 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/pkg/app"
+	"application/pkg/comment"
+	"application/pkg/utils"
+	"application/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userExists 检查用户是否存在
+func userExists(id string) bool {
+	_, err := bc.ChannelQuery("queryUser", [][]byte{[]byte(id)})
+	return err == nil
+}
+
+// commentView 返回展示给用户的评论，已删除的评论不显示内容，被隐藏的评论只对管理者显示内容
+func commentView(c sql.Comment, moderator bool) sql.Comment {
+	if c.Deleted || c.Hidden && !moderator {
+		c.Content = ""
+	}
+	return c
+}
+
+// requireDatasetAccess 检查用户能否访问数据集，失败时写入响应并返回 false
+func requireDatasetAccess(appG app.Gin, owner, name, user string) bool {
+	if ok, err := checkDatasetAccess(owner, name, user); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return false
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "受限数据集，访问申请未获批准")
+		return false
+	}
+	return true
+}
+
+// findComment 查询评论，不存在时写入响应并返回 nil
+func findComment(appG app.Gin, id uint) *sql.Comment {
+	c, err := sql.GetComment(id)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return nil
+	}
+	if c == nil {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("评论不存在: %d", id))
+		return nil
+	}
+	return c
+}
+
+// notifyComment 通知评论中提到的用户与被回复的作者，不通知作者本人
+// 编辑评论时重复调用，已通知过的用户不会重复通知
+func notifyComment(c sql.Comment, parent *sql.Comment) {
+	now := utils.GetTimeString()
+	newNotification := func(user, kind string) sql.Notification {
+		version := -1
+		if c.Version != nil {
+			version = *c.Version
+		}
+		return sql.Notification{
+			User:         user,
+			CommentID:    c.ID,
+			Type:         kind,
+			DatasetOwner: c.DatasetOwner,
+			DatasetName:  c.DatasetName,
+			Version:      version,
+			Time:         now,
+		}
+	}
+
+	var notifications []sql.Notification
+	if parent != nil && parent.Author != c.Author && !parent.Deleted {
+		notifications = append(notifications, newNotification(parent.Author, sql.NotificationCommentReply))
+	}
+	for _, user := range comment.Mentions(c.Content) {
+		if user == c.Author || !userExists(user) {
+			continue
+		}
+		notifications = append(notifications, newNotification(user, sql.NotificationCommentMention))
+	}
+	if err := sql.CreateNotifications(notifications); err != nil {
+		log.Printf("创建评论 %d 的通知出错: %s", c.ID, err)
+	}
+}
+
+// QueryComments 查询数据集的评论，客户端按 thread_id 与 parent_id 组织主题
+func QueryComments(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner   string `json:"owner" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		User    string `json:"user" binding:"required"`
+		Version *int   `json:"version"` // 版本序号，为空时查询全部评论
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if !requireDatasetAccess(appG, body.Owner, body.Name, body.User) {
+		return
+	}
+	moderator, err := canManageDataset(body.Owner, body.User)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	comments, err := sql.QueryComments(body.Owner, body.Name, body.Version)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	views := make([]sql.Comment, 0, len(comments))
+	for _, item := range comments {
+		views = append(views, commentView(item, moderator))
+	}
+	appG.Response(http.StatusOK, "成功", views)
+}
+
+func CreateComment(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string `json:"owner" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		User     string `json:"user" binding:"required"`
+		Version  *int   `json:"version"`   // 版本序号，为空表示针对整个数据集，回复时忽略
+		ParentID uint   `json:"parent_id"` // 回复的评论，为 0 时创建主题
+		Content  string `json:"content" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if !comment.ValidContent(body.Content) {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: 评论内容不能为空且不能超过 %d 个字符", comment.MaxLength))
+		return
+	}
+	if !userExists(body.User) {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("用户不存在: %s", body.User))
+		return
+	}
+
+	dataset, err := queryDataset(body.Owner, body.Name)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	if dataset.Deleted {
+		appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+		return
+	}
+	if !requireDatasetAccess(appG, body.Owner, body.Name, body.User) {
+		return
+	}
+
+	newComment := sql.Comment{
+		DatasetOwner: body.Owner,
+		DatasetName:  body.Name,
+		Version:      body.Version,
+		Author:       body.User,
+		Content:      body.Content,
+	}
+	var parent *sql.Comment
+	if body.ParentID != 0 {
+		if parent = findComment(appG, body.ParentID); parent == nil {
+			return
+		}
+		if parent.DatasetOwner != body.Owner || parent.DatasetName != body.Name || parent.Deleted {
+			appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: 不能回复该评论: %d", body.ParentID))
+			return
+		}
+		thread := parent
+		if parent.ThreadID != parent.ID {
+			if thread = findComment(appG, parent.ThreadID); thread == nil {
+				return
+			}
+		}
+		if thread.Locked {
+			appG.Response(http.StatusForbidden, "失败", "主题已锁定")
+			return
+		}
+		newComment.ThreadID = thread.ID
+		newComment.ParentID = parent.ID
+		newComment.Version = parent.Version
+	} else if body.Version != nil && (*body.Version < 0 || *body.Version >= len(dataset.Versions)) {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("版本不存在: %d", *body.Version))
+		return
+	}
+
+	if err := sql.CreateComment(&newComment); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	notifyComment(newComment, parent)
+	appG.Response(http.StatusOK, "成功", newComment)
+}
+
+// EditComment 修改评论内容，只有作者可以修改，修改前的内容保存在编辑历史中
+func EditComment(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID      uint   `json:"id" binding:"required"`
+		User    string `json:"user" binding:"required"`
+		Content string `json:"content" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if !comment.ValidContent(body.Content) {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: 评论内容不能为空且不能超过 %d 个字符", comment.MaxLength))
+		return
+	}
+
+	existing := findComment(appG, body.ID)
+	if existing == nil {
+		return
+	}
+	if existing.Author != body.User {
+		appG.Response(http.StatusForbidden, "失败", "只有作者可以修改评论")
+		return
+	}
+	if existing.Deleted || existing.Hidden {
+		appG.Response(http.StatusBadRequest, "失败", "评论已删除或被隐藏")
+		return
+	}
+	if existing.Content == body.Content {
+		appG.Response(http.StatusOK, "成功", existing)
+		return
+	}
+
+	if err := sql.EditComment(existing, body.Content, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	// 只通知新提到的用户
+	notifyComment(*existing, nil)
+	appG.Response(http.StatusOK, "成功", existing)
+}
+
+// DeleteComment 删除评论，作者或数据集管理者可以删除，回复仍然保留
+func DeleteComment(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID   uint   `json:"id" binding:"required"`
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	existing := findComment(appG, body.ID)
+	if existing == nil {
+		return
+	}
+	if existing.Author != body.User && !requireManage(appG, existing.DatasetOwner, body.User) {
+		return
+	}
+
+	existing.Deleted = true
+	existing.Content = ""
+	if err := sql.SaveComment(existing); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+// QueryCommentHistory 查询评论的编辑历史
+func QueryCommentHistory(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID   uint   `json:"id" binding:"required"`
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	existing := findComment(appG, body.ID)
+	if existing == nil {
+		return
+	}
+	if !requireDatasetAccess(appG, existing.DatasetOwner, existing.DatasetName, body.User) {
+		return
+	}
+	if existing.Deleted {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("评论已删除: %d", body.ID))
+		return
+	}
+	if existing.Hidden && !requireManage(appG, existing.DatasetOwner, body.User) {
+		return
+	}
+
+	revisions, err := sql.QueryCommentRevisions(existing.ID)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", revisions)
+}
+
+// ModerateComment 数据集管理者隐藏评论或锁定主题
+func ModerateComment(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID       uint   `json:"id" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+		Action   string `json:"action" binding:"required"` // hide/unhide/lock/unlock
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	existing := findComment(appG, body.ID)
+	if existing == nil {
+		return
+	}
+	if !requireManage(appG, existing.DatasetOwner, body.Operator) {
+		return
+	}
+
+	switch body.Action {
+	case "hide":
+		existing.Hidden = true
+		existing.HiddenBy = body.Operator
+	case "unhide":
+		existing.Hidden = false
+		existing.HiddenBy = ""
+	case "lock", "unlock":
+		if existing.ParentID != 0 {
+			appG.Response(http.StatusBadRequest, "失败", "参数错误: 只能锁定主题")
+			return
+		}
+		existing.Locked = body.Action == "lock"
+	default:
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: 未知操作: %s", body.Action))
+		return
+	}
+
+	if err := sql.SaveComment(existing); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", existing)
+}
@@ -4,22 +4,159 @@ import (
 	bc "application/blockchain"
 	"application/model"
 	"application/pkg/app"
+	"application/pkg/blob"
+	"application/pkg/license"
+	"application/pkg/merkle"
 	"application/pkg/utils"
 	"application/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
 
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// toActiveDatasets 过滤已删除的数据集，并附加下载次数
+func toActiveDatasets(datasets []model.Dataset) ([]model.DatasetEx, error) {
+	var activeDatasets []model.DatasetEx
+	for _, dataset := range datasets {
+		if dataset.Deleted {
+			continue
+		}
+		// Query the database for the download count
+		downloads, err := sql.QueryDownloads(dataset.Owner, dataset.Name)
+		if err != nil {
+			return nil, err
+		}
+		activeDatasets = append(activeDatasets, model.DatasetEx{
+			Owner:     dataset.Owner,
+			Name:      dataset.Name,
+			Versions:  dataset.Versions,
+			Downloads: downloads,
+			Deleted:   dataset.Deleted,
+			Gated:     dataset.Gated,
+
+			License:        dataset.License,
+			LicenseVersion: dataset.LicenseVersion,
+			RequireLicense: dataset.RequireLicense,
+		})
+	}
+	return activeDatasets, nil
+}
+
+// queryDataset 从链上查询数据集
+func queryDataset(owner, name string) (model.Dataset, error) {
+	var dataset model.Dataset
+	res, err := bc.ChannelQuery("queryDataset", [][]byte{
+		[]byte(owner),
+		[]byte(name),
+	})
+	if err != nil {
+		return dataset, err
+	}
+	err = json.Unmarshal(res.Payload, &dataset)
+	return dataset, err
+}
+
+// queryAllDatasets 从链上查询全部数据集
+func queryAllDatasets() ([]model.Dataset, error) {
+	var datasets []model.Dataset
+	res, err := bc.ChannelQuery("queryAllDatasets", nil)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(res.Payload, &datasets)
+	return datasets, err
+}
+
+// activeDataset 查询未删除的数据集并附加下载次数，已删除时返回 nil
+func activeDataset(owner, name string) (*model.DatasetEx, error) {
+	dataset, err := queryDataset(owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	active, err := toActiveDatasets([]model.Dataset{dataset})
+	if err != nil {
+		return nil, fmt.Errorf("数据库错误: %s", err)
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+	return &active[0], nil
+}
+
+// datasetHasFile 检查文件是否属于数据集的某个版本
+func datasetHasFile(dataset model.Dataset, file model.DatasetFile) bool {
+	for _, version := range dataset.Versions {
+		for _, f := range version.Files {
+			if f.SameFile(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requireDatasetFiles 检查文件均属于数据集，避免借公开数据集的名义下载其他数据集的文件
+func requireDatasetFiles(owner, name string, files []model.DatasetFile) (int, error) {
+	dataset, err := queryDataset(owner, name)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	if dataset.Deleted {
+		return http.StatusBadRequest, fmt.Errorf("该数据集已被删除")
+	}
+	for _, file := range files {
+		if !datasetHasFile(dataset, file) {
+			return http.StatusNotFound, fmt.Errorf("数据集中不存在该文件: %s", file.FileName)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// fileSplits 返回文件在各版本中所属的划分名
+func fileSplits(dataset model.Dataset, file model.DatasetFile) []string {
+	var splits []string
+	seen := map[string]bool{}
+	for _, version := range dataset.Versions {
+		if split := versionSplit(version, file); split != "" && !seen[split] {
+			seen[split] = true
+			splits = append(splits, split)
+		}
+	}
+	return splits
+}
+
+// versionSplit 返回文件在版本中所属的划分名，不属于任何划分时返回空字符串
+func versionSplit(version model.Version, file model.DatasetFile) string {
+	for _, f := range version.Files {
+		if !f.SameFile(file) {
+			continue
+		}
+		for _, split := range version.Splits {
+			for _, name := range split.Files {
+				if name == file.FileName {
+					return split.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
 func CreateDataset(c *gin.Context) {
 	appG := app.Gin{C: c}
 	var body struct {
 		Owner    string         `json:"owner" binding:"required"`
 		Name     string         `json:"name" binding:"required"`
+		Operator string         `json:"operator"` // 所有者为组织时必填
 		Metadata model.Metadata `json:"metadata" binding:"required"`
+
+		RequireLicense bool `json:"require_license"` // 下载前需明确接受许可证
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -27,9 +164,30 @@ func CreateDataset(c *gin.Context) {
 		return
 	}
 
+	if body.Metadata.License != "" && !license.Valid(body.Metadata.License) {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("许可证不是有效的 SPDX 标识符: %s", body.Metadata.License))
+		return
+	}
+	if body.RequireLicense && body.Metadata.License == "" {
+		appG.Response(http.StatusBadRequest, "失败", "要求接受许可证时必须指定许可证")
+		return
+	}
+	operator := body.Operator
+	if operator == "" {
+		operator = body.Owner
+	}
+
+	// 许可证与数据集在同一交易中写入链上，下载记录据此引用许可证条款
 	args := [][]byte{
 		[]byte(body.Owner),
 		[]byte(body.Name),
+		[]byte(operator),
+	}
+	if body.Metadata.License != "" {
+		args = append(args,
+			[]byte(body.Metadata.License),
+			[]byte(strconv.FormatBool(body.RequireLicense)),
+		)
 	}
 
 	_, err := bc.ChannelExecute("createDataset", args)
@@ -72,30 +230,52 @@ func QueryAllDatasets(c *gin.Context) {
 	}
 
 	// Filter out datasets where Deleted is true
-	var activeDatasets []model.DatasetEx
-	for _, dataset := range datasets {
-		if !dataset.Deleted {
-			// Query the database for the download count
-			downloads, err := sql.QueryDownloads(dataset.Owner, dataset.Name)
-			if err != nil {
-				appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库错误: %s", err.Error()))
-				return
-			}
-			// Append the dataset to the active datasets
-			activeDatasets = append(activeDatasets, model.DatasetEx{
-				Owner:     dataset.Owner,
-				Name:      dataset.Name,
-				Versions:  dataset.Versions,
-				Downloads: downloads,
-				Deleted:   dataset.Deleted,
-			})
-		}
+	activeDatasets, err := toActiveDatasets(datasets)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库错误: %s", err.Error()))
+		return
 	}
 
 	// Return the active datasets
 	appG.Response(http.StatusOK, "成功", activeDatasets)
 }
 
+func QueryDatasetsByUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User        string `json:"user" binding:"required"`
+		IncludeOrgs bool   `json:"include_orgs"` // 是否包含用户所属组织的数据集
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryDatasetsByUser", [][]byte{
+		[]byte(body.User),
+		[]byte(strconv.FormatBool(body.IncludeOrgs)),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var datasets []model.Dataset
+	if err = json.Unmarshal(res.Payload, &datasets); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	activeDatasets, err := toActiveDatasets(datasets)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库错误: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", activeDatasets)
+}
+
 func QueryDatasetMetadata(c *gin.Context) {
 	appG := app.Gin{C: c}
 	var body struct {
@@ -149,9 +329,41 @@ func AddDatasetVersion(c *gin.Context) {
 		appG.Response(http.StatusBadRequest, "失败", "文件不能为空")
 		return
 	}
+	// 文件哈希用于定位本地文件，统计与扫描前先校验格式
+	for _, file := range body.Version.Files {
+		if !blob.ValidHash(file.Hash) {
+			appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("文件哈希格式错误: %s", file.Hash))
+			return
+		}
+	}
+
+	// 由服务端统计表格文件的行数，不使用客户端提交的值
+	// 文件较大时在后台统计，完成前拒绝添加版本，避免以不准确的行数上链
+	rows, err := countRows(body.Version.Files)
+	if errors.Is(err, errStatsPending) {
+		appG.Response(http.StatusConflict, "失败", err.Error())
+		return
+	}
+	if err != nil {
+		appG.Response(http.StatusUnprocessableEntity, "失败", err.Error())
+		return
+	}
+	body.Version.Rows = rows
+
+	// 检查存储配额
+	if code, err := requireQuota(body.Owner, body.Version.Files); err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
+
+	// 检查文件中的敏感信息
+	if code, err := requireClean(body.Version.Files); err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
 
 	// 调用链码
-	_, err := bc.ChannelExecute("addDatasetVersion", [][]byte{
+	_, err = bc.ChannelExecute("addDatasetVersion", [][]byte{
 		[]byte(body.Owner),
 		[]byte(body.Name),
 		[]byte(utils.ToJson(body.Version)),
@@ -161,6 +373,11 @@ func AddDatasetVersion(c *gin.Context) {
 		return
 	}
 
+	// 更新搜索索引，失败不影响版本创建
+	if err := indexReadme(body.Owner, body.Name); err != nil {
+		log.Printf("更新说明文档索引失败 %s/%s: %s", body.Owner, body.Name, err)
+	}
+
 	// 成功响应
 	appG.Response(http.StatusOK, "成功", "")
 }
@@ -253,3 +470,43 @@ func DeleteDataset(c *gin.Context) {
 	// 成功响应
 	appG.Response(http.StatusOK, "成功", "success")
 }
+
+func QueryFileProof(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string `json:"owner" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		Version  int    `json:"version"` // 版本序号，从 0 开始
+		FileName string `json:"filename" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryFileProof", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(strconv.Itoa(body.Version)),
+		[]byte(body.FileName),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var proof merkle.Proof
+	if err = json.Unmarshal(res.Payload, &proof); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	// 返回前在服务端验证一次，客户端可使用 pkg/merkle 离线复核
+	if err := merkle.Verify(proof); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("包含证明验证失败: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", proof)
+}
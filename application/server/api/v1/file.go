@@ -2,17 +2,28 @@ package v1
 
 import (
 	bc "application/blockchain"
+	"application/conf"
 	"application/model"
 	"application/pkg/app"
-	"application/pkg/utils"
+	"application/pkg/blob"
+	"application/pkg/fsck"
+	"application/pkg/quota"
+	"application/pkg/receipt"
+	"application/pkg/recorder"
+	"application/pkg/scan"
 	"application/sql"
-	"archive/zip"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
+	"log"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"net/http"
 	"os"
@@ -21,9 +32,34 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// recordDownload 校验并提交下载记录，记录由后台批量上链，回执ID写入响应头
+func recordDownload(c *gin.Context, record model.Record) (int, error) {
+	if err := recorder.Check(record); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	id, err := recorder.Submit(record)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("数据库出错: %s", err)
+	}
+	c.Header(receipt.IDHeader, id)
+	return http.StatusOK, nil
+}
+
 func UploadFile(c *gin.Context) {
 	appG := app.Gin{C: c}
 
+	// 计入配额的所有者 (用户或组织)
+	owner := c.PostForm("owner")
+	if owner == "" {
+		appG.Response(http.StatusBadRequest, "失败", "所有者不能为空")
+		return
+	}
+	// 上传者，所有者为组织时需单独提供。服务端不验证该值，链上按声明的上传者记录
+	uploader := c.PostForm("user")
+	if uploader == "" {
+		uploader = owner
+	}
+
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
 		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("获取文件出错: %s", err.Error()))
@@ -46,18 +82,33 @@ func UploadFile(c *gin.Context) {
 		return
 	}
 
+	// 检查存储配额，上传者已上传但未被引用的文件一并计入
+	usage, err := quota.Compute(owner)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	pending, err := quota.Unreferenced(uploader)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	if err := usage.RequireFiles(append(pending, model.File{Hash: hashString, Size: header.Size})); err != nil {
+		appG.Response(http.StatusRequestEntityTooLarge, "失败", err.Error())
+		return
+	}
+
 	// 重新打开文件
 	file.Seek(0, io.SeekStart)
 
 	// 创建目录（如果不存在）
-	dir := "data/Files"
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+	if err := os.MkdirAll(blob.Dir, os.ModePerm); err != nil {
 		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("创建目录出错: %s", err.Error()))
 		return
 	}
 
 	// 保存文件到本地路径
-	filePath := filepath.Join(dir, hashString)
+	filePath := blob.Path(hashString)
 	outFile, err := os.Create(filePath)
 	if err != nil {
 		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("打开文件出错: %s", err.Error()))
@@ -75,6 +126,7 @@ func UploadFile(c *gin.Context) {
 	args := [][]byte{
 		[]byte(hashString),
 		[]byte(fmt.Sprintf("%d", header.Size)),
+		[]byte(uploader),
 	}
 
 	_, err = bc.ChannelExecute("createFile", args)
@@ -83,10 +135,39 @@ func UploadFile(c *gin.Context) {
 		return
 	}
 
+	// 后台扫描敏感信息
+	scan.Submit(hashString)
+
 	// 返回结果
 	appG.Response(http.StatusOK, "成功", hashString)
 }
 
+func QueryFilesByUploader(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryFilesByUploader", [][]byte{[]byte(body.User)})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var files []model.File
+	if err := json.Unmarshal(res.Payload, &files); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", files)
+}
+
 func DownloadFile(c *gin.Context) {
 	appG := app.Gin{C: c}
 
@@ -102,41 +183,53 @@ func DownloadFile(c *gin.Context) {
 		return
 	}
 
+	// 检查访问权限
+	if ok, err := checkDatasetAccess(body.DatasetOwner, body.DatasetName, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "受限数据集，访问申请未获批准")
+		return
+	}
+
+	// 只能下载数据集中的文件
+	if code, err := requireDatasetFiles(body.DatasetOwner, body.DatasetName, []model.DatasetFile{body.File}); err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
+
 	hash := body.File.Hash
-	fileName := body.File.FileName
+	fileName := path.Base(body.File.FileName)
 
 	// 检查 hash 是否为 SHA-256
-	if ok, _ := regexp.MatchString("^[a-f0-9]{64}$", hash); !ok {
+	if !blob.ValidHash(hash) {
 		appG.Response(http.StatusBadRequest, "失败", "文件哈希格式错误")
 		return
 	}
 
 	// 检查本地文件是否存在
-	filePath := filepath.Join("data/Files", hash)
+	filePath := blob.Path(hash)
 	if _, err := os.Stat(filePath); err != nil {
 		appG.Response(http.StatusNotFound, "失败", "文件不存在")
 		return
 	}
 
-	// 将 [File] 转换为 JSON 字符串
-	file, err := json.Marshal([]model.DatasetFile{body.File})
-	if err != nil {
-		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("序列化出错: %s", err.Error()))
-		return
+	record := model.Record{
+		DatasetOwner: body.DatasetOwner,
+		DatasetName:  body.DatasetName,
+		User:         body.User,
+		Files:        []model.DatasetFile{body.File},
 	}
 
-	// 上传下载记录
-	args := [][]byte{
-		[]byte(body.DatasetOwner),     // args[0]: 所有者ID | string
-		[]byte(body.DatasetName),      // args[1]: 数据集名 | string
-		[]byte(body.User),             // args[2]: 下载者ID | string
-		[]byte(file),                  // args[3]: 文件信息 []DatasetFile | string (JSON)
-		[]byte(utils.GetTimeString()), // args[4]: 下载时间 | string
+	// 边发送边校验，传输完成且校验通过后才记录下载，损坏的文件不产生下载记录
+	if conf.Conf.DownloadConfig.VerifyOnRead {
+		serveVerified(c, record, filePath, fileName)
+		return
 	}
 
-	_, err = bc.ChannelExecute("createRecord", args)
-	if err != nil {
-		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+	// 记录下载
+	if code, err := recordDownload(c, record); err != nil {
+		appG.Response(code, "失败", err.Error())
 		return
 	}
 
@@ -146,15 +239,102 @@ func DownloadFile(c *gin.Context) {
 		return
 	}
 
+	setDigestHeaders(c, hash)
 	appG.C.FileAttachment(filePath, fileName)
 }
 
+// serveVerified 边发送边校验文件哈希，校验通过后记录下载并增加下载计数
+// 回执ID在发送前生成并写入响应头。校验失败时不发送最后一段数据并中断连接，同时隔离损坏的文件
+func serveVerified(c *gin.Context, record model.Record, filePath string, fileName string) {
+	appG := app.Gin{C: c}
+	hash := record.Files[0].Hash
+
+	if err := recorder.Check(record); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err))
+		return
+	}
+	id, err := recorder.NewID()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("生成回执ID出错: %s", err))
+		return
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("打开文件出错: %s", err.Error()))
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("读取文件出错: %s", err.Error()))
+		return
+	}
+
+	c.Header(receipt.IDHeader, id)
+	setDigestHeaders(c, hash)
+	setAttachmentHeader(c, fileName)
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Length", strconv.FormatInt(info.Size(), 10))
+	c.Status(http.StatusOK)
+
+	// 响应头已发送，出错时只能中断传输且不记录下载
+	// 实际长度小于 Content-Length，net/http 随即关闭连接，客户端可据此发现
+	_, err = io.Copy(c.Writer, blob.NewVerifyingReader(f, hash))
+	var corrupt *blob.CorruptError
+	if errors.As(err, &corrupt) {
+		log.Printf("下载校验失败 %s", corrupt)
+		fsck.MarkCorrupt(corrupt)
+		c.Abort()
+		return
+	} else if err != nil {
+		log.Printf("发送文件出错 %s: %s", hash, err)
+		c.Abort()
+		return
+	}
+
+	// 传输已完成，记录失败只能写入日志
+	if err := recorder.SubmitID(id, record); err != nil {
+		log.Printf("记录下载出错 %s: %s", id, err)
+		return
+	}
+	if err := sql.IncrementDownloads(record.DatasetOwner, record.DatasetName); err != nil {
+		log.Printf("增加下载计数出错 %s/%s: %s", record.DatasetOwner, record.DatasetName, err)
+	}
+}
+
+// setDigestHeaders 设置内容的 SHA-256 摘要响应头，供客户端校验
+func setDigestHeaders(c *gin.Context, hash string) {
+	digest := blob.Digest(hash)
+	c.Header("Digest", "sha-256="+digest)
+	c.Header("Repr-Digest", "sha-256=:"+digest+":")
+}
+
+// setAttachmentHeader 设置下载文件名
+func setAttachmentHeader(c *gin.Context, fileName string) {
+	if isASCII(fileName) {
+		c.Header("Content-Disposition", `attachment; filename="`+strings.ReplaceAll(fileName, `"`, `\"`)+`"`)
+	} else {
+		c.Header("Content-Disposition", `attachment; filename*=UTF-8''`+url.QueryEscape(fileName))
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
 func DownloadFilesCompressed(c *gin.Context) {
 	appG := app.Gin{C: c}
 
 	var body struct {
 		Files        []model.DatasetFile `json:"files" binding:"required"`
 		ZipName      string              `json:"zipname" binding:"required"`
+		Format       string              `json:"format"` // zip (默认) 或 tar
 		DatasetOwner string              `json:"dataset_owner" binding:"required"`
 		DatasetName  string              `json:"dataset_name" binding:"required"`
 		User         string              `json:"user" binding:"required"`
@@ -166,87 +346,163 @@ func DownloadFilesCompressed(c *gin.Context) {
 		return
 	}
 
-	re := regexp.MustCompile("^[a-f0-9]{64}$")
-	for _, file := range body.Files {
-		// 检查 hash 是否为 SHA-256
-		if ok := re.MatchString(file.Hash); !ok {
-			appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("文件哈希格式错误: %s", file.Hash))
-			return
-		}
+	// 检查访问权限
+	if ok, err := checkDatasetAccess(body.DatasetOwner, body.DatasetName, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "受限数据集，访问申请未获批准")
+		return
+	}
 
-		// 检查本地文件是否存在
-		filePath := filepath.Join("data", "Files", file.Hash)
-		if _, err := os.Stat(filePath); err != nil {
-			appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("文件不存在: %s", file.Hash))
-			return
-		}
+	// 只能下载数据集中的文件
+	if code, err := requireDatasetFiles(body.DatasetOwner, body.DatasetName, body.Files); err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
 	}
 
-	// 将 Files 转换为 JSON 字符串
-	files, err := json.Marshal(body.Files)
-	if err != nil {
-		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("序列化出错: %s", err.Error()))
+	sendArchive(c, body.DatasetOwner, body.DatasetName, body.User, body.Files, body.ZipName, body.Format)
+}
+
+func DownloadSplit(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	var body struct {
+		DatasetOwner string `json:"dataset_owner" binding:"required"`
+		DatasetName  string `json:"dataset_name" binding:"required"`
+		User         string `json:"user" binding:"required"`
+		Version      int    `json:"version"` // 版本序号，从 0 开始
+		Split        string `json:"split" binding:"required"`
+		Format       string `json:"format"` // zip (默认) 或 tar
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数出错: %s", err.Error()))
 		return
 	}
 
-	// 上传下载记录
-	args := [][]byte{
-		[]byte(body.DatasetOwner),     // args[0]: 所有者ID | string
-		[]byte(body.DatasetName),      // args[1]: 数据集名 | string
-		[]byte(body.User),             // args[2]: 下载者ID | string
-		[]byte(files),                 // args[3]: 文件列表 []DatasetFile | string (JSON)
-		[]byte(utils.GetTimeString()), // args[4]: 下载时间 | string
+	// 检查访问权限
+	if ok, err := checkDatasetAccess(body.DatasetOwner, body.DatasetName, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "受限数据集，访问申请未获批准")
+		return
 	}
 
-	_, err = bc.ChannelExecute("createRecord", args)
+	dataset, err := queryDataset(body.DatasetOwner, body.DatasetName)
 	if err != nil {
 		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
 		return
 	}
+	if dataset.Deleted {
+		appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+		return
+	}
+	if body.Version < 0 || body.Version >= len(dataset.Versions) {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("版本不存在: %d", body.Version))
+		return
+	}
 
-	// 增加 Downloads 计数
-	if err := sql.IncrementDownloads(body.DatasetOwner, body.DatasetName); err != nil {
-		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+	files, ok := splitFiles(dataset.Versions[body.Version], body.Split)
+	if !ok {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("划分不存在: %s", body.Split))
 		return
 	}
 
-	// 1. 创建一个临时压缩文件
-	zipFile, err := os.CreateTemp("data", "download-*.zip")
-	filePath := zipFile.Name()
-	if err != nil {
-		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("创建压缩文件出错: %s", err.Error()))
+	archiveName := fmt.Sprintf("%s-v%d-%s", body.DatasetName, body.Version, body.Split)
+	sendArchive(c, body.DatasetOwner, body.DatasetName, body.User, files, archiveName, body.Format)
+}
+
+// splitFiles 返回版本中某个划分包含的文件
+func splitFiles(version model.Version, split string) ([]model.DatasetFile, bool) {
+	for _, s := range version.Splits {
+		if s.Name != split {
+			continue
+		}
+		names := make(map[string]bool, len(s.Files))
+		for _, name := range s.Files {
+			names[name] = true
+		}
+		var files []model.DatasetFile
+		for _, file := range version.Files {
+			if names[file.FileName] {
+				files = append(files, file)
+			}
+		}
+		return files, true
+	}
+	return nil, false
+}
+
+// sendArchive 打包文件、记录下载并返回压缩文件，保留文件的目录结构
+func sendArchive(c *gin.Context, owner string, name string, user string, files []model.DatasetFile, archiveName string, format string) {
+	appG := app.Gin{C: c}
+
+	if format == "" {
+		format = archiveZip
+	}
+	if format != archiveZip && format != archiveTar {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("不支持的压缩格式: %s", format))
 		return
 	}
 
-	// 2. 将所有文件添加到压缩文件
-	zipWriter := zip.NewWriter(zipFile)
-	for _, file := range body.Files {
-		fileReader, err := os.Open(filepath.Join("data", "Files", file.Hash))
-		if err != nil {
-			appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("打开文件出错: %s", err.Error()))
+	for _, file := range files {
+		// 检查 hash 是否为 SHA-256
+		if !blob.ValidHash(file.Hash) {
+			appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("文件哈希格式错误: %s", file.Hash))
 			return
 		}
 
-		zipFileWriter, err := zipWriter.Create(file.FileName)
-		if err != nil {
-			appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("添加压缩文件出错: %s", err.Error()))
+		// 压缩包内只允许规范的相对路径
+		if !validFilePath(file.FileName) {
+			appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("文件名格式错误: %s", file.FileName))
 			return
 		}
 
-		if _, err := io.Copy(zipFileWriter, fileReader); err != nil {
-			appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("写入压缩文件出错: %s", err.Error()))
+		// 检查本地文件是否存在
+		if !blob.Exists(file.Hash) {
+			appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("文件不存在: %s", file.Hash))
 			return
 		}
-		fileReader.Close()
 	}
-	zipWriter.Close()
 
-	// 3. 返回压缩文件
+	// 先打包再记录下载，文件损坏时不产生下载记录
+	filePath, archiveHash, err := writeArchive(files, format)
+	var corrupt *blob.CorruptError
+	if errors.As(err, &corrupt) {
+		log.Printf("下载校验失败 %s", corrupt)
+		fsck.MarkCorrupt(corrupt)
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("文件已损坏: %s", corrupt.Hash))
+		return
+	} else if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	defer os.Remove(filePath)
+
+	// 记录下载
+	if code, err := recordDownload(c, model.Record{
+		DatasetOwner: owner,
+		DatasetName:  name,
+		User:         user,
+		Files:        files,
+	}); err != nil {
+		appG.Response(code, "失败", err.Error())
+		return
+	}
+
+	// 增加 Downloads 计数
+	if err := sql.IncrementDownloads(owner, name); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
 	var filePathAbs string
 	if filePathAbs, err = filepath.Abs(filePath); err != nil {
 		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("获取文件路径出错: %s", err.Error()))
 		return
 	}
-	appG.C.FileAttachment(filePathAbs, fmt.Sprintf("%s.zip", body.ZipName))
-	defer os.Remove(filePath)
+	setDigestHeaders(c, archiveHash)
+	appG.C.FileAttachment(filePathAbs, fmt.Sprintf("%s.%s", archiveName, format))
 }
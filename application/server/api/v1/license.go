@@ -0,0 +1,161 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/license"
+	"application/pkg/utils"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func QueryLicenses(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	type licenseItem struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	var licenses []licenseItem
+	for _, id := range license.List() {
+		licenses = append(licenses, licenseItem{ID: id, Name: license.Name(id)})
+	}
+
+	appG.Response(http.StatusOK, "成功", licenses)
+}
+
+func SetDatasetLicense(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner          string `json:"owner" binding:"required"`
+		Name           string `json:"name" binding:"required"`
+		Operator       string `json:"operator" binding:"required"`
+		License        string `json:"license"` // 为空表示无许可证
+		RequireLicense bool   `json:"require_license"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	if body.License != "" && !license.Valid(body.License) {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("许可证不是有效的 SPDX 标识符: %s", body.License))
+		return
+	}
+
+	_, err := bc.ChannelExecute("setDatasetLicense", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(body.Operator),
+		[]byte(body.License),
+		[]byte(strconv.FormatBool(body.RequireLicense)),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	if err := sql.UpdateLicense(body.Owner, body.Name, body.License); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func AcceptLicense(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner string `json:"owner" binding:"required"`
+		Name  string `json:"name" binding:"required"`
+		User  string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelExecute("acceptLicense", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+		[]byte(body.User),
+		[]byte(utils.GetTimeString()),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var acceptance model.LicenseAcceptance
+	if err = json.Unmarshal(res.Payload, &acceptance); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", acceptance)
+}
+
+func QueryLicenseAcceptancesByDataset(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner string `json:"owner" binding:"required"`
+		Name  string `json:"name" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryLicenseAcceptancesByDataset", [][]byte{
+		[]byte(body.Owner),
+		[]byte(body.Name),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var acceptances []model.LicenseAcceptance
+	if err = json.Unmarshal(res.Payload, &acceptances); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", acceptances)
+}
+
+func QueryLicenseAcceptancesByUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryLicenseAcceptancesByUser", [][]byte{[]byte(body.User)})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var acceptances []model.LicenseAcceptance
+	if err = json.Unmarshal(res.Payload, &acceptances); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", acceptances)
+}
@@ -0,0 +1,218 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/app"
+	"encoding/json"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// canManageDataset 与链码的管理权限规则一致：
+// 用户数据集仅所有者本人可管理，组织数据集由组织所有者或管理员管理
+func canManageDataset(owner, operator string) (bool, error) {
+	if owner == operator {
+		return true, nil
+	}
+	res, err := bc.ChannelQuery("queryOrganization", [][]byte{[]byte(owner)})
+	if err != nil {
+		// 所有者不是组织
+		return false, nil
+	}
+	var org model.Organization
+	if err := json.Unmarshal(res.Payload, &org); err != nil {
+		return false, err
+	}
+	for _, member := range org.Members {
+		if member.User == operator {
+			return member.Role == "owner" || member.Role == "admin", nil
+		}
+	}
+	return false, nil
+}
+
+func CreateOrganization(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID      string `json:"id" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		Creator string `json:"creator" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute("createOrganization", [][]byte{
+		[]byte(body.ID),
+		[]byte(body.Name),
+		[]byte(body.Creator),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func QueryOrganization(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID string `json:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryOrganization", [][]byte{[]byte(body.ID)})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var org model.Organization
+	if err = json.Unmarshal(res.Payload, &org); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", org)
+}
+
+func QueryAllOrganizations(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	res, err := bc.ChannelQuery("queryAllOrganizations", nil)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var orgs []model.Organization
+	if err = json.Unmarshal(res.Payload, &orgs); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", orgs)
+}
+
+func QueryOrganizationsByUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryOrganizationsByUser", [][]byte{[]byte(body.User)})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var orgs []model.Organization
+	if err = json.Unmarshal(res.Payload, &orgs); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", orgs)
+}
+
+func AddOrganizationMember(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID       string `json:"id" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+		User     string `json:"user" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute("addOrganizationMember", [][]byte{
+		[]byte(body.ID),
+		[]byte(body.Operator),
+		[]byte(body.User),
+		[]byte(body.Role),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func RemoveOrganizationMember(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID       string `json:"id" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+		User     string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	_, err := bc.ChannelExecute("removeOrganizationMember", [][]byte{
+		[]byte(body.ID),
+		[]byte(body.Operator),
+		[]byte(body.User),
+	})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func QueryDatasetsByOrganization(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID string `json:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	res, err := bc.ChannelQuery("queryDatasetsByOrganization", [][]byte{[]byte(body.ID)})
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+
+	var datasets []model.Dataset
+	if err = json.Unmarshal(res.Payload, &datasets); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	activeDatasets, err := toActiveDatasets(datasets)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库错误: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", activeDatasets)
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/blob"
+	"application/pkg/preview"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func PreviewFile(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		File         model.DatasetFile `json:"file" binding:"required"`
+		DatasetOwner string            `json:"dataset_owner" binding:"required"`
+		DatasetName  string            `json:"dataset_name" binding:"required"`
+		User         string            `json:"user" binding:"required"`
+		Offset       int               `json:"offset"` // 起始行，不含表头
+		Limit        int               `json:"limit"`  // 默认 20，最多 100
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	if !blob.ValidHash(body.File.Hash) {
+		appG.Response(http.StatusBadRequest, "失败", "文件哈希格式错误")
+		return
+	}
+	format := preview.Format(body.File.FileName)
+	if format == "" {
+		appG.Response(http.StatusBadRequest, "失败", preview.ErrUnsupported.Error())
+		return
+	}
+
+	// 检查访问权限
+	if ok, err := checkDatasetAccess(body.DatasetOwner, body.DatasetName, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "受限数据集，访问申请未获批准")
+		return
+	}
+
+	// 只能预览数据集中的文件
+	dataset, err := queryDataset(body.DatasetOwner, body.DatasetName)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	if dataset.Deleted || !datasetHasFile(dataset, body.File) {
+		appG.Response(http.StatusNotFound, "失败", "数据集中不存在该文件")
+		return
+	}
+
+	if !blob.Exists(body.File.Hash) {
+		appG.Response(http.StatusNotFound, "失败", "文件不存在")
+		return
+	}
+
+	p, err := preview.Cached(body.File.Hash, blob.Path(body.File.Hash), format, body.Offset, body.Limit)
+	if err != nil {
+		appG.Response(http.StatusUnprocessableEntity, "失败", fmt.Sprintf("预览文件出错: %s", err.Error()))
+		return
+	}
+
+	// 缓存的结果为共享对象，复制后再附加划分信息
+	res := *p
+	res.Splits = fileSplits(dataset, body.File)
+
+	appG.Response(http.StatusOK, "成功", res)
+}
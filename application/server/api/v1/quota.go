@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/quota"
+	"application/sql"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireQuota 检查所有者引用这些文件后是否超出配额
+func requireQuota(owner string, files []model.DatasetFile) (int, error) {
+	usage, err := quota.Compute(owner)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	hashes := make([]string, 0, len(files))
+	for _, file := range files {
+		if !usage.Counted(file.Hash) {
+			hashes = append(hashes, file.Hash)
+		}
+	}
+	added, err := quota.Files(hashes)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err := usage.RequireFiles(added); err != nil {
+		return http.StatusRequestEntityTooLarge, err
+	}
+	return http.StatusOK, nil
+}
+
+func QueryUsage(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner string `json:"owner" binding:"required"` // 用户或组织ID
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	usage, err := quota.Compute(body.Owner)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", usage)
+}
+
+// SetQuota 为单个所有者设置配额，bytes 为空时恢复默认配额
+func SetQuota(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner string `json:"owner" binding:"required"`
+		Bytes *int64 `json:"bytes"` // 负数表示不限制
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	var err error
+	if body.Bytes == nil {
+		err = sql.DeleteQuota(body.Owner)
+	} else {
+		err = sql.SaveQuota(&sql.Quota{Owner: body.Owner, Bytes: *body.Bytes})
+	}
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", "")
+}
@@ -0,0 +1,201 @@
+package v1
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/readme"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// indexReadme 将数据集最新版本的说明文档写入搜索索引
+func indexReadme(owner, name string) error {
+	dataset, err := queryDataset(owner, name)
+	if err != nil {
+		return err
+	}
+	if dataset.Deleted || len(dataset.Versions) == 0 {
+		return sql.DeleteDatasetReadme(owner, name)
+	}
+	version := len(dataset.Versions) - 1
+	hash := dataset.Versions[version].Readme
+	if hash == "" {
+		return sql.DeleteDatasetReadme(owner, name)
+	}
+	content, err := readme.Load(hash)
+	if err != nil {
+		return err
+	}
+	return sql.SaveDatasetReadme(&sql.DatasetReadme{
+		Owner:   owner,
+		Name:    name,
+		Version: version,
+		Hash:    hash,
+		Content: string(content),
+	})
+}
+
+// snippet 截取说明文档中第一个关键词附近的文本
+func snippet(content string, terms []string) string {
+	const radius = 80
+	lower := strings.ToLower(content)
+	start := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, strings.ToLower(term)); i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	from, to := start-radius, start+radius
+	if from < 0 {
+		from = 0
+	}
+	if to > len(content) {
+		to = len(content)
+	}
+	// 避免截断多字节字符
+	for from > 0 && !utf8.RuneStart(content[from]) {
+		from--
+	}
+	for to < len(content) && !utf8.RuneStart(content[to]) {
+		to++
+	}
+	return strings.Join(strings.Fields(content[from:to]), " ")
+}
+
+func QueryDatasetReadme(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner   string `json:"owner" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		Version *int   `json:"version"` // 版本序号，为空时使用最新版本
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	dataset, err := queryDataset(body.Owner, body.Name)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	if dataset.Deleted {
+		appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+		return
+	}
+	version := len(dataset.Versions) - 1
+	if body.Version != nil {
+		version = *body.Version
+	}
+	if version < 0 || version >= len(dataset.Versions) {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("版本不存在: %d", version))
+		return
+	}
+	hash := dataset.Versions[version].Readme
+	if hash == "" {
+		appG.Response(http.StatusNotFound, "失败", "该版本没有说明文档")
+		return
+	}
+
+	r, err := readme.Render(hash)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", r)
+}
+
+func SearchDatasets(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Query string `json:"query" binding:"required"`
+		Limit int    `json:"limit"` // 默认 20，最大 100
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	terms := strings.Fields(body.Query)
+	if len(terms) == 0 {
+		appG.Response(http.StatusBadRequest, "失败", "搜索关键词不能为空")
+		return
+	}
+	if len(terms) > 8 {
+		terms = terms[:8]
+	}
+	if body.Limit <= 0 {
+		body.Limit = 20
+	}
+	if body.Limit > 100 {
+		body.Limit = 100
+	}
+
+	hits, err := sql.SearchDatasets(terms, body.Limit)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	type result struct {
+		Dataset model.DatasetEx `json:"dataset"`
+		Snippet string          `json:"snippet"` // 说明文档摘录
+	}
+	results := []result{}
+	for _, hit := range hits {
+		dataset, err := activeDataset(hit.Owner, hit.Name)
+		if err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", err.Error())
+			return
+		}
+		if dataset == nil {
+			continue
+		}
+		results = append(results, result{Dataset: *dataset, Snippet: snippet(hit.Content, terms)})
+	}
+
+	appG.Response(http.StatusOK, "成功", results)
+}
+
+// ReindexReadmes 重建全部数据集的说明文档索引
+func ReindexReadmes(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	res, err := bc.ChannelQuery("queryAllDatasets", nil)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	var datasets []model.Dataset
+	if err = json.Unmarshal(res.Payload, &datasets); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+		return
+	}
+
+	type failure struct {
+		Owner string `json:"owner"`
+		Name  string `json:"name"`
+		Error string `json:"error"`
+	}
+	failures := []failure{}
+	for _, dataset := range datasets {
+		if err := indexReadme(dataset.Owner, dataset.Name); err != nil {
+			failures = append(failures, failure{Owner: dataset.Owner, Name: dataset.Name, Error: err.Error()})
+		}
+	}
+
+	appG.Response(http.StatusOK, "成功", failures)
+}
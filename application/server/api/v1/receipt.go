@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"application/pkg/app"
+	"application/pkg/receipt"
+	"application/pkg/recorder"
+	"application/pkg/utils"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func QueryReceiptKey(c *gin.Context) {
+	appG := app.Gin{C: c}
+	appG.Response(http.StatusOK, "成功", receipt.PublicKey())
+}
+
+func VerifyReceipt(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Receipt   receipt.Receipt `json:"receipt" binding:"required"`
+		PublicKey string          `json:"public_key"` // 为空时使用本服务公钥
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", receipt.Verify(body.Receipt, body.PublicKey))
+}
+
+// QueryReceipt 凭回执ID获取下载回执，记录尚未上链时返回 202
+func QueryReceipt(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID string `json:"id" binding:"required"` // 下载响应头中的回执ID
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	event, err := sql.GetDownloadEvent(body.ID)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	if event == nil {
+		appG.Response(http.StatusNotFound, "失败", "回执不存在")
+		return
+	}
+	switch event.Status {
+	case sql.EventPending:
+		appG.Response(http.StatusAccepted, "失败", "下载记录尚未上链，请稍后重试")
+		return
+	case sql.EventFailed:
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("下载记录上链失败: %s", event.Error))
+		return
+	}
+
+	// 首次查询时签发回执并保存
+	if event.Receipt != "" {
+		var r receipt.Receipt
+		if err := json.Unmarshal([]byte(event.Receipt), &r); err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("反序列化出错: %s", err.Error()))
+			return
+		}
+		appG.Response(http.StatusOK, "成功", r)
+		return
+	}
+	record, err := recorder.Record(*event)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	r, err := receipt.Issue(event.TxID, record)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("签发下载回执失败: %s", err.Error()))
+		return
+	}
+	event.Receipt = string(utils.ToJson(r))
+	if err := sql.SaveDownloadEvent(event); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	appG.Response(http.StatusOK, "成功", r)
+}
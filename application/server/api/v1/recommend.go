@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/recommend"
+	"application/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scoredDataset 带分数的数据集
+type scoredDataset struct {
+	Dataset model.DatasetEx `json:"dataset"`
+	Score   float64         `json:"score"`
+}
+
+// clampLimit 返回数量默认 10，最大 50
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return 10
+	}
+	if limit > 50 {
+		return 50
+	}
+	return limit
+}
+
+// toScoredDatasets 查询数据集详情，跳过已删除的数据集
+func toScoredDatasets(items []recommend.Scored) ([]scoredDataset, error) {
+	results := []scoredDataset{}
+	for _, item := range items {
+		dataset, err := activeDataset(item.Owner, item.Name)
+		if err != nil {
+			return nil, err
+		}
+		if dataset == nil {
+			continue
+		}
+		results = append(results, scoredDataset{Dataset: *dataset, Score: item.Score})
+	}
+	return results, nil
+}
+
+func QueryTrendingDatasets(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Limit int `json:"limit"` // 默认 10，最大 50
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	datasets, err := queryAllDatasets()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	now := time.Now()
+	events, err := sql.QueryDownloadTimes(now.Add(-recommend.Window))
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	results, err := toScoredDatasets(recommend.Trending(datasets, events, now, clampLimit(body.Limit)))
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	appG.Response(http.StatusOK, "成功", results)
+}
+
+func QueryRelatedDatasets(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner string `json:"owner" binding:"required"`
+		Name  string `json:"name" binding:"required"`
+		Limit int    `json:"limit"` // 每类默认 10，最大 50
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	limit := clampLimit(body.Limit)
+
+	if dataset, err := activeDataset(body.Owner, body.Name); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	} else if dataset == nil {
+		appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+		return
+	}
+
+	// 下载过该数据集的用户还下载过的数据集
+	counts, err := sql.QueryCoDownloads(body.Owner, body.Name, limit)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	coDownloaded := []recommend.Scored{}
+	for _, count := range counts {
+		coDownloaded = append(coDownloaded, recommend.Scored{Owner: count.Owner, Name: count.Name, Score: float64(count.Count)})
+	}
+	alsoDownloaded, err := toScoredDatasets(coDownloaded)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+
+	// 任务与标签相近的数据集
+	tables, err := sql.QueryActiveMetadata()
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	similar := []scoredDataset{}
+	for _, table := range tables {
+		if table.Owner != body.Owner || table.Name != body.Name {
+			continue
+		}
+		if similar, err = toScoredDatasets(recommend.Similar(table, tables, limit)); err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", err.Error())
+			return
+		}
+		break
+	}
+
+	type result struct {
+		AlsoDownloaded []scoredDataset `json:"also_downloaded"` // 分数为共同下载的用户数
+		Similar        []scoredDataset `json:"similar"`         // 分数为 Jaccard 相似度
+	}
+	appG.Response(http.StatusOK, "成功", result{AlsoDownloaded: alsoDownloaded, Similar: similar})
+}
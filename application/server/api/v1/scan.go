@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"application/conf"
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/blob"
+	"application/pkg/cron"
+	"application/pkg/scan"
+	"application/sql"
+	"fmt"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireClean 配置了 block_severity 时，检查文件中是否存在该级别及以上的敏感信息
+// 尚未扫描的文件在此同步扫描
+func requireClean(files []model.DatasetFile) (int, error) {
+	min := conf.Conf.ScanConfig.BlockSeverity
+	if min == "" {
+		return http.StatusOK, nil
+	}
+	for _, file := range files {
+		result, err := scan.Ensure(file.Hash)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if scan.Blocked(result, min) {
+			return http.StatusUnprocessableEntity, fmt.Errorf("文件 %s 中检测到敏感信息 (高 %d, 中 %d, 低 %d)，请处理后重新上传",
+				file.FileName, result.High, result.Medium, result.Low)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// QueryFileScan 查询文件的敏感信息扫描结果，尚未扫描时立即扫描
+func QueryFileScan(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Hash string `json:"hash" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	if !blob.ValidHash(body.Hash) || !blob.Exists(body.Hash) {
+		appG.Response(http.StatusNotFound, "失败", "文件不存在")
+		return
+	}
+
+	result, err := scan.Ensure(body.Hash)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	findings, err := sql.QueryScanFindings(body.Hash)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	type scanResult struct {
+		*sql.FileScan
+		Findings []sql.ScanFinding `json:"findings"`
+	}
+	appG.Response(http.StatusOK, "成功", scanResult{FileScan: result, Findings: findings})
+}
+
+// RunScan 在后台扫描尚未用当前扫描器版本扫描的文件
+func RunScan(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	if err := scan.Start(cron.LogScan); err != nil {
+		appG.Response(http.StatusConflict, "失败", err.Error())
+		return
+	}
+
+	appG.Response(http.StatusAccepted, "成功", "文件扫描已开始")
+}
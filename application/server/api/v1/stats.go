@@ -0,0 +1,244 @@
+package v1
+
+import (
+	"application/model"
+	"application/pkg/app"
+	"application/pkg/blob"
+	"application/pkg/preview"
+	"application/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// savedFileStats 返回数据库中已有的统计信息，尚未统计或不是表格文件时返回 nil
+func savedFileStats(file model.DatasetFile) (*preview.Stats, error) {
+	if !blob.ValidHash(file.Hash) {
+		return nil, fmt.Errorf("文件哈希格式错误: %s", file.Hash)
+	}
+	format := preview.Format(file.FileName)
+	if format == "" {
+		return nil, nil
+	}
+
+	saved, err := sql.GetFileStats(file.Hash, format)
+	if err != nil {
+		return nil, fmt.Errorf("数据库出错: %s", err)
+	}
+	if saved == nil {
+		return nil, nil
+	}
+	stats := &preview.Stats{Format: format, Rows: saved.Rows}
+	if err := json.Unmarshal([]byte(saved.Columns), &stats.Columns); err != nil {
+		return nil, fmt.Errorf("反序列化出错: %s", err)
+	}
+	return stats, nil
+}
+
+// fileStats 返回表格文件的统计信息，优先使用数据库中的结果
+// 不是可识别的表格格式时返回 nil
+func fileStats(file model.DatasetFile) (*preview.Stats, error) {
+	if !blob.ValidHash(file.Hash) {
+		return nil, fmt.Errorf("文件哈希格式错误: %s", file.Hash)
+	}
+	format := preview.Format(file.FileName)
+	if format == "" {
+		return nil, nil
+	}
+
+	if stats, err := savedFileStats(file); err != nil || stats != nil {
+		return stats, err
+	}
+	return analyzeFile(file, format)
+}
+
+// analyzeFile 完整读取文件统计并保存结果
+func analyzeFile(file model.DatasetFile, format string) (*preview.Stats, error) {
+	if !blob.Exists(file.Hash) {
+		return nil, fmt.Errorf("文件不存在: %s", file.Hash)
+	}
+	stats, err := preview.Analyze(blob.Path(file.Hash), format)
+	if err != nil {
+		return nil, fmt.Errorf("统计文件 %s 出错: %s", file.FileName, err)
+	}
+
+	columns, err := json.Marshal(stats.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("序列化出错: %s", err)
+	}
+	if err := sql.SaveFileStats(&sql.FileStats{
+		Hash:       file.Hash,
+		Format:     format,
+		Rows:       stats.Rows,
+		Columns:    string(columns),
+		ComputedAt: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("数据库出错: %s", err)
+	}
+	return stats, nil
+}
+
+// errStatsPending 表格文件超过同步统计上限，已转入后台统计
+var errStatsPending = errors.New("表格文件较大，正在后台统计，请稍后重试")
+
+var (
+	statsRunning sync.Map                 // hash/format -> struct{}，正在后台统计的文件
+	statsSlots   = make(chan struct{}, 2) // 同时进行的后台统计数
+)
+
+// analyzeLater 在后台统计文件并保存结果，同一文件同时只统计一次
+// 失败时只记录日志，下次请求会重新提交
+func analyzeLater(file model.DatasetFile) {
+	format := preview.Format(file.FileName)
+	key := file.Hash + "/" + format
+	if _, running := statsRunning.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer statsRunning.Delete(key)
+		statsSlots <- struct{}{}
+		defer func() { <-statsSlots }()
+		if _, err := analyzeFile(file, format); err != nil {
+			log.Printf("后台统计文件失败 %s: %s", file.Hash, err)
+		}
+	}()
+}
+
+// unanalyzed 返回尚未统计的表格文件及其总大小
+func unanalyzed(files []model.DatasetFile) ([]model.DatasetFile, int64, error) {
+	var pending []model.DatasetFile
+	var size int64
+	for _, file := range files {
+		if preview.Format(file.FileName) == "" {
+			continue
+		}
+		if stats, err := savedFileStats(file); err != nil {
+			return nil, 0, err
+		} else if stats != nil {
+			continue
+		}
+		info, err := os.Stat(blob.Path(file.Hash))
+		if err != nil {
+			return nil, 0, fmt.Errorf("文件不存在: %s", file.Hash)
+		}
+		pending = append(pending, file)
+		size += info.Size()
+	}
+	return pending, size, nil
+}
+
+// countRows 统计版本中表格文件的总行数，没有可识别的表格文件时返回 0
+// 尚未统计的表格文件总大小超过 preview.MaxStatsBytes 时转入后台统计并返回 errStatsPending
+// 避免添加版本的请求长时间阻塞，也不以猜测的行数上链
+func countRows(files []model.DatasetFile) (int32, error) {
+	pending, size, err := unanalyzed(files)
+	if err != nil {
+		return 0, err
+	}
+	if size > preview.MaxStatsBytes {
+		for _, file := range pending {
+			analyzeLater(file)
+		}
+		return 0, errStatsPending
+	}
+
+	var total int64
+	for _, file := range files {
+		stats, err := fileStats(file)
+		if err != nil {
+			return 0, err
+		}
+		if stats != nil {
+			total += stats.Rows
+		}
+	}
+	if total > math.MaxInt32 {
+		return 0, fmt.Errorf("总行数超出范围: %d", total)
+	}
+	return int32(total), nil
+}
+
+func QueryVersionStats(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner   string `json:"owner" binding:"required"`
+		Name    string `json:"name" binding:"required"`
+		User    string `json:"user" binding:"required"`
+		Version int    `json:"version"` // 版本序号，从 0 开始
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	// 检查访问权限
+	if ok, err := checkDatasetAccess(body.Owner, body.Name, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "受限数据集，访问申请未获批准")
+		return
+	}
+
+	dataset, err := queryDataset(body.Owner, body.Name)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return
+	}
+	if dataset.Deleted {
+		appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+		return
+	}
+	if body.Version < 0 || body.Version >= len(dataset.Versions) {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("版本不存在: %d", body.Version))
+		return
+	}
+
+	type fileItem struct {
+		File    model.DatasetFile `json:"file"`
+		Split   string            `json:"split,omitempty"`   // 所属的数据划分
+		Stats   *preview.Stats    `json:"stats"`             // 非表格文件或正在后台统计时为 null
+		Pending bool              `json:"pending,omitempty"` // 是否正在后台统计
+	}
+	version := dataset.Versions[body.Version]
+
+	// 尚未统计的文件超过同步统计上限时转入后台统计，本次只返回已有的结果
+	pending, size, err := unanalyzed(version.Files)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", err.Error())
+		return
+	}
+	deferred := map[string]bool{}
+	if size > preview.MaxStatsBytes {
+		for _, file := range pending {
+			analyzeLater(file)
+			deferred[file.Hash+"/"+file.FileName] = true
+		}
+	}
+
+	items := []fileItem{}
+	for _, file := range version.Files {
+		if deferred[file.Hash+"/"+file.FileName] {
+			items = append(items, fileItem{File: file, Split: versionSplit(version, file), Pending: true})
+			continue
+		}
+		stats, err := fileStats(file)
+		if err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", err.Error())
+			return
+		}
+		items = append(items, fileItem{File: file, Split: versionSplit(version, file), Stats: stats})
+	}
+
+	appG.Response(http.StatusOK, "成功", items)
+}
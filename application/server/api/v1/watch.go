@@ -0,0 +1,184 @@
+package v1
+
+import (
+	"application/pkg/app"
+	"application/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// datasetUserBody 数据集与用户
+type datasetUserBody struct {
+	Owner string `json:"owner" binding:"required"`
+	Name  string `json:"name" binding:"required"`
+	User  string `json:"user" binding:"required"`
+}
+
+// updateDatasetRelation 收藏或关注数据集 (及取消)，收藏与关注只针对未删除的数据集
+func updateDatasetRelation(c *gin.Context, update func(owner, name, user string) error, requireActive bool) {
+	appG := app.Gin{C: c}
+	var body datasetUserBody
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	if requireActive {
+		if dataset, err := activeDataset(body.Owner, body.Name); err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", err.Error())
+			return
+		} else if dataset == nil {
+			appG.Response(http.StatusBadRequest, "失败", "该数据集已被删除")
+			return
+		}
+	}
+
+	if err := update(body.Owner, body.Name, body.User); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+func StarDataset(c *gin.Context) {
+	updateDatasetRelation(c, sql.StarDataset, true)
+}
+
+func UnstarDataset(c *gin.Context) {
+	updateDatasetRelation(c, sql.UnstarDataset, false)
+}
+
+func WatchDataset(c *gin.Context) {
+	updateDatasetRelation(c, sql.WatchDataset, true)
+}
+
+func UnwatchDataset(c *gin.Context) {
+	updateDatasetRelation(c, sql.UnwatchDataset, false)
+}
+
+// QueryDatasetStars 查询数据集的收藏数、关注数，以及指定用户是否已收藏或关注
+func QueryDatasetStars(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner string `json:"owner" binding:"required"`
+		Name  string `json:"name" binding:"required"`
+		User  string `json:"user"` // 可选
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	stars, watchers, err := sql.CountStars(body.Owner, body.Name)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	type result struct {
+		Stars    int64 `json:"stars"`
+		Watchers int64 `json:"watchers"`
+		Starred  bool  `json:"starred"`
+		Watching bool  `json:"watching"`
+	}
+	res := result{Stars: stars, Watchers: watchers}
+
+	if body.User != "" {
+		if res.Starred, res.Watching, err = sql.QueryStarred(body.Owner, body.Name, body.User); err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+			return
+		}
+	}
+
+	appG.Response(http.StatusOK, "成功", res)
+}
+
+// QueryUserStars 查询用户收藏与关注的数据集
+func QueryUserStars(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	stars, err := sql.QueryStarsByUser(body.User)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	watches, err := sql.QueryWatchesByUser(body.User)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	type result struct {
+		Stars   []sql.Star  `json:"stars"`
+		Watches []sql.Watch `json:"watches"`
+	}
+	appG.Response(http.StatusOK, "成功", result{Stars: stars, Watches: watches})
+}
+
+func QueryNotifications(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User       string `json:"user" binding:"required"`
+		UnreadOnly bool   `json:"unread_only"`
+		Limit      int    `json:"limit"` // 默认 50，最大 200
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if body.Limit <= 0 {
+		body.Limit = 50
+	}
+	if body.Limit > 200 {
+		body.Limit = 200
+	}
+
+	notifications, err := sql.QueryNotifications(body.User, body.UnreadOnly, body.Limit)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	unread, err := sql.CountUnreadNotifications(body.User)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	type result struct {
+		Unread        int64              `json:"unread"` // 未读通知总数
+		Notifications []sql.Notification `json:"notifications"`
+	}
+	appG.Response(http.StatusOK, "成功", result{Unread: unread, Notifications: notifications})
+}
+
+func MarkNotificationsRead(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		User string `json:"user" binding:"required"`
+		IDs  []uint `json:"ids"` // 为空时标记全部
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	updated, err := sql.MarkNotificationsRead(body.User, body.IDs)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", updated)
+}
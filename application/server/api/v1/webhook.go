@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"application/pkg/app"
+	"application/pkg/webhook"
+	"application/sql"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireManage 检查操作者能否管理所有者的数据集，无权限时写入响应并返回 false
+func requireManage(appG app.Gin, owner, operator string) bool {
+	if ok, err := canManageDataset(owner, operator); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("调用智能合约出错: %s", err.Error()))
+		return false
+	} else if !ok {
+		appG.Response(http.StatusForbidden, "失败", "权限不足")
+		return false
+	}
+	return true
+}
+
+// ownedWebhook 查询 webhook 并检查操作者的权限，失败时写入响应并返回 nil
+func ownedWebhook(appG app.Gin, id uint, operator string) *sql.Webhook {
+	hook, err := sql.GetWebhook(id)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return nil
+	}
+	if hook == nil {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("webhook 不存在: %d", id))
+		return nil
+	}
+	if !requireManage(appG, hook.Owner, operator) {
+		return nil
+	}
+	return hook
+}
+
+func CreateWebhook(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string   `json:"owner" binding:"required"`
+		Name     string   `json:"name"` // 为空表示所有者的全部数据集
+		Operator string   `json:"operator" binding:"required"`
+		URL      string   `json:"url" binding:"required"`
+		Secret   string   `json:"secret"` // 为空时自动生成
+		Events   []string `json:"events"` // 为空表示全部事件
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if err := webhook.CheckURL(c.Request.Context(), body.URL); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	for _, event := range body.Events {
+		known := false
+		for _, e := range webhook.Events {
+			known = known || e == event
+		}
+		if !known {
+			appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: 未知事件: %s", event))
+			return
+		}
+	}
+	if !requireManage(appG, body.Owner, body.Operator) {
+		return
+	}
+
+	if body.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("生成密钥出错: %s", err.Error()))
+			return
+		}
+		body.Secret = hex.EncodeToString(secret)
+	}
+	hook := sql.Webhook{
+		Owner:   body.Owner,
+		Name:    body.Name,
+		URL:     body.URL,
+		Secret:  body.Secret,
+		Events:  strings.Join(body.Events, ","),
+		Creator: body.Operator,
+	}
+	if err := sql.CreateWebhook(&hook); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+
+	// 密钥只在创建时返回
+	type result struct {
+		Webhook sql.Webhook `json:"webhook"`
+		Secret  string      `json:"secret"`
+	}
+	appG.Response(http.StatusOK, "成功", result{Webhook: hook, Secret: hook.Secret})
+}
+
+func QueryWebhooks(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		Owner    string `json:"owner" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if !requireManage(appG, body.Owner, body.Operator) {
+		return
+	}
+
+	hooks, err := sql.QueryWebhooks(body.Owner)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", hooks)
+}
+
+func DeleteWebhook(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID       uint   `json:"id" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if ownedWebhook(appG, body.ID, body.Operator) == nil {
+		return
+	}
+
+	if err := sql.DeleteWebhook(body.ID); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", "")
+}
+
+// QueryWebhookDeliveries 查询 webhook 的投递记录
+func QueryWebhookDeliveries(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		ID       uint   `json:"id" binding:"required"`
+		Operator string `json:"operator" binding:"required"`
+		Limit    int    `json:"limit"` // 默认 50，最大 200
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+	if body.Limit <= 0 {
+		body.Limit = 50
+	}
+	if body.Limit > 200 {
+		body.Limit = 200
+	}
+	if ownedWebhook(appG, body.ID, body.Operator) == nil {
+		return
+	}
+
+	deliveries, err := sql.QueryWebhookDeliveries(body.ID, body.Limit)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", deliveries)
+}
+
+// RedeliverWebhook 重新投递，投递记录ID不变
+func RedeliverWebhook(c *gin.Context) {
+	appG := app.Gin{C: c}
+	var body struct {
+		DeliveryID uint   `json:"delivery_id" binding:"required"`
+		Operator   string `json:"operator" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		appG.Response(http.StatusBadRequest, "失败", fmt.Sprintf("参数错误: %s", err.Error()))
+		return
+	}
+
+	delivery, err := sql.GetWebhookDelivery(body.DeliveryID)
+	if err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	if delivery == nil {
+		appG.Response(http.StatusNotFound, "失败", fmt.Sprintf("投递记录不存在: %d", body.DeliveryID))
+		return
+	}
+	if ownedWebhook(appG, delivery.WebhookID, body.Operator) == nil {
+		return
+	}
+
+	if err := webhook.Redeliver(delivery); err != nil {
+		appG.Response(http.StatusInternalServerError, "失败", fmt.Sprintf("数据库出错: %s", err.Error()))
+		return
+	}
+	appG.Response(http.StatusOK, "成功", delivery)
+}
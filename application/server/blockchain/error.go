@@ -0,0 +1,29 @@
+package blockchain
+
+import (
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+)
+
+// Rejected 判断调用是否被链码拒绝 (参数错误或权限不足)，这类错误重试也不会成功
+// 连接失败、背书超时等错误返回 false
+func Rejected(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok || err == nil {
+		return false
+	}
+	// 多个背书节点均返回错误时，全部为链码拒绝才算拒绝
+	if s.Group == status.ClientStatus && s.Code == status.MultipleErrors.ToInt32() {
+		for _, detail := range s.Details {
+			if e, ok := detail.(error); !ok || !Rejected(e) {
+				return false
+			}
+		}
+		return len(s.Details) > 0
+	}
+	if s.Group != status.ChaincodeStatus {
+		return false
+	}
+	return strings.Contains(s.Message, "参数错误") || strings.Contains(s.Message, "权限不足")
+}
@@ -0,0 +1,28 @@
+package blockchain
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+)
+
+// RegisterChaincodeEvents 订阅链码事件，eventFilter 为事件名的正则表达式
+// fromBlock 为 0 时只接收新区块中的事件，否则从该区块开始重放
+// 使用完整区块订阅，过滤区块中的链码事件不含负载
+func RegisterChaincodeEvents(eventFilter string, fromBlock uint64) (<-chan *fab.CCEvent, func(), error) {
+	ctx := sdk.ChannelContext(channelName, fabsdk.WithUser(user))
+	opts := []event.ClientOption{event.WithBlockEvents()}
+	if fromBlock > 0 {
+		opts = append(opts, event.WithSeekType(seek.FromBlock), event.WithBlockNum(fromBlock))
+	}
+	cli, err := event.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	reg, events, err := cli.RegisterChaincodeEvent(chainCodeName, eventFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events, func() { cli.Unregister(reg) }, nil
+}
@@ -0,0 +1,73 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+)
+
+// ledgerClient 创建账本查询客户端
+func ledgerClient() (*ledger.Client, error) {
+	ctx := sdk.ChannelContext(channelName, fabsdk.WithUser(user))
+	return ledger.New(ctx)
+}
+
+// QueryTransaction 根据交易ID查询已提交的交易
+func QueryTransaction(txID string) (*pb.ProcessedTransaction, error) {
+	cli, err := ledgerClient()
+	if err != nil {
+		return nil, err
+	}
+	return cli.QueryTransaction(fab.TransactionID(txID), ledger.WithTargetEndpoints(endpoints...))
+}
+
+// QueryBlockByTxID 查询包含指定交易的区块
+func QueryBlockByTxID(txID string) (*common.Block, error) {
+	cli, err := ledgerClient()
+	if err != nil {
+		return nil, err
+	}
+	return cli.QueryBlockByTxID(fab.TransactionID(txID), ledger.WithTargetEndpoints(endpoints...))
+}
+
+// InvocationArgs 解析交易信封，返回交易ID与链码调用参数 (args[0] 为函数名)
+func InvocationArgs(env *common.Envelope) (string, [][]byte, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(env.GetPayload(), payload); err != nil {
+		return "", nil, fmt.Errorf("解析交易负载出错: %s", err)
+	}
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+		return "", nil, fmt.Errorf("解析通道头出错: %s", err)
+	}
+	if common.HeaderType(channelHeader.GetType()) != common.HeaderType_ENDORSER_TRANSACTION {
+		return "", nil, fmt.Errorf("不是链码调用交易: %s", common.HeaderType(channelHeader.GetType()))
+	}
+
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(payload.GetData(), tx); err != nil {
+		return "", nil, fmt.Errorf("解析交易出错: %s", err)
+	}
+	if len(tx.GetActions()) == 0 {
+		return "", nil, fmt.Errorf("交易不包含链码调用")
+	}
+	actionPayload := &pb.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(tx.GetActions()[0].GetPayload(), actionPayload); err != nil {
+		return "", nil, fmt.Errorf("解析链码调用出错: %s", err)
+	}
+	proposalPayload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(actionPayload.GetChaincodeProposalPayload(), proposalPayload); err != nil {
+		return "", nil, fmt.Errorf("解析提案负载出错: %s", err)
+	}
+	invocationSpec := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(proposalPayload.GetInput(), invocationSpec); err != nil {
+		return "", nil, fmt.Errorf("解析调用参数出错: %s", err)
+	}
+
+	return channelHeader.GetTxId(), invocationSpec.GetChaincodeSpec().GetInput().GetArgs(), nil
+}
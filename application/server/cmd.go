@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"application/blockchain"
+	"application/conf"
+	"application/pkg/fsck"
+	"application/pkg/receipt"
+	"application/sql"
+)
+
+// runCommand 执行命令行子命令，返回进程退出码
+func runCommand(name string, args []string) int {
+	switch name {
+	case "verify-receipt":
+		return verifyReceipt(args)
+	case "fsck":
+		return runFsck(args)
+	default:
+		log.Printf("未知命令: %s", name)
+		return 2
+	}
+}
+
+// verifyReceipt 不经过 HTTP 服务，直接从账本验证下载回执
+// 用法: server verify-receipt -key 公钥 <回执文件>
+// 回执文件可以是 JSON (/receipt 接口返回的 data)，也可以是 base64 编码的 JSON
+// 公钥可从签发回执的服务的 /receipt/key 接口获取
+func verifyReceipt(args []string) int {
+	fs := flag.NewFlagSet("verify-receipt", flag.ContinueOnError)
+	publicKey := fs.String("key", "", "签名公钥 (base64)，必填")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || *publicKey == "" {
+		log.Printf("用法: server verify-receipt -key 公钥 <回执文件>")
+		return 2
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Printf("读取回执文件失败 %s", err)
+		return 1
+	}
+	var r receipt.Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		if r, err = receipt.Decode(strings.TrimSpace(string(data))); err != nil {
+			log.Printf("解析回执失败 %s", err)
+			return 1
+		}
+	}
+
+	blockchain.Init()
+	res := receipt.Verify(r, *publicKey)
+	for _, check := range res.Checks {
+		status := "OK"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Printf("%-10s %-4s %s\n", check.Name, status, check.Detail)
+	}
+	if !res.Valid {
+		fmt.Println("回执验证失败")
+		return 1
+	}
+	fmt.Println("回执验证通过")
+	return 0
+}
+
+// runFsck 检查本地文件与链上记录是否一致
+// 用法: server fsck [-quarantine] [-save]
+// 发现问题时退出码为 1
+func runFsck(args []string) int {
+	fs := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	quarantine := fs.Bool("quarantine", false, "将损坏的文件移入隔离目录")
+	save := fs.Bool("save", false, "将检查结果保存到数据库，供管理接口查询")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		log.Printf("用法: server fsck [-quarantine] [-save]")
+		return 2
+	}
+
+	blockchain.Init()
+	report, err := fsck.Run(*quarantine)
+	if err != nil {
+		log.Printf("文件检查失败 %s", err)
+		return 1
+	}
+	for _, issue := range report.Issues {
+		fmt.Printf("%-14s %s %s\n", issue.Kind, issue.Hash, issue.Detail)
+	}
+	fmt.Printf("检查 %d 个文件，发现 %d 个问题\n", report.Checked, len(report.Issues))
+
+	if *save {
+		sql.InitMysql(conf.Conf.MysqlConfig)
+		if err := sql.Migrate(); err != nil {
+			log.Printf("数据库迁移失败 %s", err)
+			return 1
+		}
+		id, err := fsck.Save(report)
+		if err != nil {
+			log.Printf("保存检查结果失败 %s", err)
+			return 1
+		}
+		fmt.Printf("检查结果已保存 #%d\n", id)
+	}
+
+	if len(report.Issues) > 0 {
+		return 1
+	}
+	return 0
+}
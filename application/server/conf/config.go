@@ -7,8 +7,16 @@ import (
 var Conf = new(Config)
 
 type Config struct {
-	*MysqlConfig  `ini:"mysql"`
-	*ServerConfig `ini:"server"`
+	*MysqlConfig    `ini:"mysql"`
+	*ServerConfig   `ini:"server"`
+	*ReceiptConfig  `ini:"receipt"`
+	*FsckConfig     `ini:"fsck"`
+	*AdminConfig    `ini:"admin"`
+	*DownloadConfig `ini:"download"`
+	*QuotaConfig    `ini:"quota"`
+	*RecordConfig   `ini:"record"`
+	*WebhookConfig  `ini:"webhook"`
+	*ScanConfig     `ini:"scan"`
 }
 
 type MysqlConfig struct {
@@ -20,6 +28,87 @@ type ServerConfig struct {
 	Port string `ini:"port"`
 }
 
+// ReceiptConfig 下载回执签名配置
+type ReceiptConfig struct {
+	KeyFile string `ini:"key_file"` // Ed25519 私钥路径 (PEM)，不存在时自动生成
+}
+
+// FsckConfig 文件完整性检查配置
+type FsckConfig struct {
+	Spec       string `ini:"spec"`       // 定时检查的 cron 表达式，为空时不定时检查
+	Quarantine bool   `ini:"quarantine"` // 是否将损坏的文件移入隔离目录
+}
+
+// DownloadConfig 文件下载配置
+type DownloadConfig struct {
+	VerifyOnRead bool `ini:"verify_on_read"` // 下载时边发送边计算哈希，不一致时中断传输、不记录下载并隔离文件
+}
+
+// QuotaConfig 默认存储配额，可通过管理接口为单个所有者单独设置
+type QuotaConfig struct {
+	UserBytes         int64 `ini:"user_bytes"`         // 用户配额 (字节)，默认 10GiB，负数表示不限制
+	OrganizationBytes int64 `ini:"organization_bytes"` // 组织配额 (字节)，默认 100GiB，负数表示不限制
+}
+
+// RecordConfig 下载记录上链配置
+type RecordConfig struct {
+	FlushInterval int `ini:"flush_interval"` // 批量写入间隔 (秒)，默认 2
+	BatchSize     int `ini:"batch_size"`     // 每笔交易的记录数，默认 64，最大 256
+}
+
+// WebhookConfig 数据集事件 webhook 投递配置
+type WebhookConfig struct {
+	Timeout       int `ini:"timeout"`        // 单次投递的超时时间 (秒)，默认 10
+	MaxAttempts   int `ini:"max_attempts"`   // 投递次数上限，默认 6
+	RetryInterval int `ini:"retry_interval"` // 首次重试间隔 (秒)，之后每次加倍，默认 30
+}
+
+// ScanConfig 敏感信息扫描配置
+type ScanConfig struct {
+	Spec          string `ini:"spec"`           // 定时扫描已有文件的 cron 表达式，为空时只能通过管理接口触发
+	BlockSeverity string `ini:"block_severity"` // 文件存在该级别及以上的发现时拒绝添加版本 (low/medium/high)，为空时不拒绝
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Token string `ini:"token"` // 管理接口令牌 (请求头 X-Admin-Token)，为空时禁用管理接口
+}
+
 func Init() error {
-	return ini.MapTo(Conf, "config.ini")
+	if err := ini.MapTo(Conf, "config.ini"); err != nil {
+		return err
+	}
+	setDefaults()
+	return nil
+}
+
+// setDefaults 为配置文件中缺省的配置项设置默认值
+func setDefaults() {
+	if Conf.ReceiptConfig.KeyFile == "" {
+		Conf.ReceiptConfig.KeyFile = "data/receipt.pem"
+	}
+	if Conf.RecordConfig.FlushInterval <= 0 {
+		Conf.RecordConfig.FlushInterval = 2
+	}
+	if Conf.RecordConfig.BatchSize <= 0 {
+		Conf.RecordConfig.BatchSize = 64
+	}
+	if Conf.RecordConfig.BatchSize > 256 {
+		Conf.RecordConfig.BatchSize = 256
+	}
+	if Conf.WebhookConfig.Timeout <= 0 {
+		Conf.WebhookConfig.Timeout = 10
+	}
+	if Conf.WebhookConfig.MaxAttempts <= 0 {
+		Conf.WebhookConfig.MaxAttempts = 6
+	}
+	if Conf.WebhookConfig.RetryInterval <= 0 {
+		Conf.WebhookConfig.RetryInterval = 30
+	}
+	if Conf.QuotaConfig.UserBytes == 0 {
+		Conf.QuotaConfig.UserBytes = 10 << 30
+	}
+	if Conf.QuotaConfig.OrganizationBytes == 0 {
+		Conf.QuotaConfig.OrganizationBytes = 100 << 30
+	}
 }
@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"application/blockchain"
 	"application/conf"
 	"application/pkg/cron"
+	"application/pkg/notify"
+	"application/pkg/receipt"
+	"application/pkg/recorder"
+	"application/pkg/webhook"
 	"application/routers"
 	"application/sql"
 
@@ -31,6 +36,16 @@ func main() {
 		return
 	}
 
+	// 命令行子命令，不加载回执签名私钥，避免在离线验证的机器上生成新的私钥
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	if err := receipt.Init(conf.Conf.ReceiptConfig.KeyFile); err != nil {
+		log.Printf("下载回执签名密钥初始化失败 %s", err)
+		return
+	}
+
 	sql.InitMysql(conf.Conf.MysqlConfig)
 	err = sql.Migrate()
 	if err != nil {
@@ -40,6 +55,9 @@ func main() {
 
 	blockchain.Init()
 	go cron.Init()
+	go recorder.Run()
+	go notify.Run()
+	go webhook.Run()
 
 	endPoint := fmt.Sprintf("%s:%s", conf.Conf.ServerConfig.Host, conf.Conf.ServerConfig.Port)
 	server := &http.Server{
@@ -18,33 +18,73 @@ type User struct {
 	Name string `json:"name"` // 用户名
 }
 
+// Member 组织成员
+type Member struct {
+	User string `json:"user"` // 用户ID
+	Role string `json:"role"` // 角色 (owner/admin/member)
+}
+
+// Organization 组织
+type Organization struct {
+	ID      string   `json:"id"`      // 组织ID
+	Name    string   `json:"name"`    // 组织名
+	Members []Member `json:"members"` // 成员列表
+}
+
 // File 文件
 type File struct {
 	Hash           string `json:"hash"`            // 文件哈希 (key)
 	Size           int64  `json:"size"`            // 文件大小
 	ReferenceCount int32  `json:"reference_count"` // 引用计数
+	Uploader       string `json:"uploader"`        // 上传者ID (上传请求中声明，未经验证)，早期记录为 unknown
+	UploadTime     string `json:"upload_time"`     // 上传时间 (交易时间)，早期记录为 unknown
+
+	Submitter string `json:"submitter,omitempty"` // 提交交易的客户端证书身份 (MSP ID/证书 ID)
 }
 
 // DatasetFile 数据集文件
 type DatasetFile struct {
 	Hash     string `json:"hash"`     // 文件哈希
 	FileName string `json:"filename"` // 文件名
+
+	ContentType string            `json:"content_type,omitempty"` // MIME 类型
+	Description string            `json:"description,omitempty"`  // 文件说明
+	Attributes  map[string]string `json:"attributes,omitempty"`   // 自定义属性 (如 language)
+}
+
+// SameFile 判断是否为同一文件 (文件名与哈希相同)，不比较文件说明等附加信息
+func (f DatasetFile) SameFile(other DatasetFile) bool {
+	return f.Hash == other.Hash && f.FileName == other.FileName
 }
 
 // Version 数据集的一个版本
 type Version struct {
-	Files        []DatasetFile `json:"files"`         // 文件列表
-	Rows         int32         `json:"rows"`          // 行数
-	CreationTime string        `json:"creation_time"` // 创建时间
-	ChangeLog    string        `json:"change_log"`    // 版本说明
+	Files        []DatasetFile `json:"files"`            // 文件列表
+	Rows         int32         `json:"rows"`             // 行数
+	CreationTime string        `json:"creation_time"`    // 创建时间
+	ChangeLog    string        `json:"change_log"`       // 版本说明
+	MerkleRoot   string        `json:"merkle_root"`      // 默克尔根 (由链码计算)
+	Splits       []Split       `json:"splits,omitempty"` // 数据划分 (如 train/validation/test)
+	Readme       string        `json:"readme,omitempty"` // 说明文档哈希，为空时由链码使用根目录的 README.md
+}
+
+// Split 数据划分，将版本中的部分文件命名为一个子集
+type Split struct {
+	Name  string   `json:"name"`  // 划分名
+	Files []string `json:"files"` // 文件名列表
 }
 
 // Dataset 数据集
 type Dataset struct {
-	Owner    string    `json:"owner"`    // 所有者ID
+	Owner    string    `json:"owner"`    // 所有者ID (用户或组织)
 	Name     string    `json:"name"`     // 数据集名
 	Versions []Version `json:"versions"` // 版本列表
 	Deleted  bool      `json:"deleted"`  // 已删除
+	Gated    bool      `json:"gated"`    // 受限访问，下载前需申请
+
+	License        string `json:"license"`         // 许可证 (SPDX 标识符)
+	LicenseVersion int32  `json:"license_version"` // 许可证条款版本
+	RequireLicense bool   `json:"require_license"` // 下载前需明确接受许可证
 }
 
 type DatasetEx struct {
@@ -53,13 +93,95 @@ type DatasetEx struct {
 	Versions  []Version `json:"versions"`  // 版本列表
 	Downloads int       `json:"downloads"` // 下载次数
 	Deleted   bool      `json:"deleted"`   // 已删除
+	Gated     bool      `json:"gated"`     // 受限访问，下载前需申请
+
+	License        string `json:"license"`         // 许可证 (SPDX 标识符)
+	LicenseVersion int32  `json:"license_version"` // 许可证条款版本
+	RequireLicense bool   `json:"require_license"` // 下载前需明确接受许可证
 }
 
 // Record 下载记录
 type Record struct {
+	ID           string        `json:"id,omitempty"`  // 记录ID (交易ID，批量写入时附加序号)
 	DatasetOwner string        `json:"dataset_owner"` // 数据集所有者
 	DatasetName  string        `json:"dataset_name"`  // 数据集名
 	User         string        `json:"user"`          // 下载者ID
 	Files        []DatasetFile `json:"files"`         // 文件列表
 	Time         string        `json:"time"`          // 下载时间
+
+	License        string `json:"license,omitempty"`         // 下载时数据集的许可证
+	LicenseVersion int32  `json:"license_version,omitempty"` // 下载时的许可证条款版本
+
+	EventID string `json:"event_id,omitempty"` // 下载事件ID (回执ID)，链码据此忽略重复提交
+}
+
+// DatasetEvent 数据集变更的链码事件负载
+type DatasetEvent struct {
+	Owner   string `json:"owner"`   // 数据集所有者
+	Name    string `json:"name"`    // 数据集名
+	Version int    `json:"version"` // 变更后的版本数量
+	License string `json:"license"` // 变更后的许可证
+	Time    string `json:"time"`    // 交易时间
+}
+
+// 链码事件名
+const (
+	EventDatasetCreated        = "dataset-created"
+	EventDatasetVersionAdded   = "dataset-version-added"
+	EventDatasetDeleted        = "dataset-deleted"
+	EventDatasetLicenseChanged = "dataset-license-changed"
+	EventRecordsCreated        = "records-created" // 负载为 []Record
+)
+
+// LicenseAcceptance 用户对数据集许可证的接受记录
+type LicenseAcceptance struct {
+	DatasetOwner   string `json:"dataset_owner"`   // 数据集所有者
+	DatasetName    string `json:"dataset_name"`    // 数据集名
+	User           string `json:"user"`            // 用户ID
+	License        string `json:"license"`         // 接受的许可证 (SPDX 标识符)
+	LicenseVersion int32  `json:"license_version"` // 接受的许可证条款版本
+	Time           string `json:"time"`            // 接受时间
+}
+
+// Attester 持有认证者角色的用户
+type Attester struct {
+	User      string `json:"user"`       // 用户ID
+	GrantedBy string `json:"granted_by"` // 授予者ID
+	Time      string `json:"time"`       // 授予时间
+}
+
+// Attestation 第三方对数据集版本某项属性的认证
+type Attestation struct {
+	DatasetOwner string `json:"dataset_owner"`     // 数据集所有者
+	DatasetName  string `json:"dataset_name"`      // 数据集名
+	Version      int    `json:"version"`           // 版本序号，从 0 开始
+	MerkleRoot   string `json:"merkle_root"`       // 认证时版本的默克尔根
+	Attester     string `json:"attester"`          // 认证者ID
+	Claim        string `json:"claim"`             // 认证类型 (如 pii-scan、schema-v3)
+	Result       string `json:"result"`            // 结果 (pass/warn/fail)
+	Evidence     string `json:"evidence"`          // 证据 (如检查报告) 的 SHA-256 哈希
+	Details      string `json:"details,omitempty"` // 说明
+	Time         string `json:"time"`              // 认证时间 (交易时间)
+}
+
+const (
+	AttestationPass = "pass" // 通过
+	AttestationWarn = "warn" // 通过但有警告
+	AttestationFail = "fail" // 未通过
+)
+
+// AdminUser 平台管理员，管理接口以该身份授予和撤销认证者角色
+const AdminUser = "admin"
+
+// AccessRequest 受限数据集的访问申请
+type AccessRequest struct {
+	DatasetOwner  string `json:"dataset_owner"` // 数据集所有者
+	DatasetName   string `json:"dataset_name"`  // 数据集名
+	User          string `json:"user"`          // 申请者ID
+	Justification string `json:"justification"` // 申请理由
+	Status        string `json:"status"`        // 状态 (pending/approved/denied)
+	RequestTime   string `json:"request_time"`  // 申请时间
+	Reviewer      string `json:"reviewer"`      // 审批者ID
+	Comment       string `json:"comment"`       // 审批意见
+	DecisionTime  string `json:"decision_time"` // 审批时间
 }
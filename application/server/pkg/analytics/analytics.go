@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"application/model"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 统计周期
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week" // 以周一为一周的开始
+)
+
+// DateLayout 时间序列与查询范围使用的日期格式
+const DateLayout = "2006-01-02"
+
+// MaxDays 单次查询的最大天数
+const MaxDays = 366
+
+// Point 时间序列中的一个周期
+type Point struct {
+	Period    string `json:"period"`    // 周期开始日期
+	Downloads int    `json:"downloads"` // 下载次数
+	Users     int    `json:"users"`     // 去重下载者数
+}
+
+// VersionCount 版本下载次数
+type VersionCount struct {
+	Version   int `json:"version"` // 版本序号，从 0 开始
+	Downloads int `json:"downloads"`
+}
+
+// FileCount 文件下载次数
+type FileCount struct {
+	Hash      string `json:"hash"`
+	FileName  string `json:"filename"`
+	Downloads int    `json:"downloads"`
+}
+
+// UserCount 下载者的下载次数
+type UserCount struct {
+	User      string `json:"user"`
+	Downloads int    `json:"downloads"`
+}
+
+// Report 数据集在一段时间内的下载统计
+type Report struct {
+	Owner       string         `json:"owner"`
+	Name        string         `json:"name"`
+	Interval    string         `json:"interval"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Downloads   int            `json:"downloads"`    // 下载次数 (一次下载可包含多个文件)
+	UniqueUsers int            `json:"unique_users"` // 去重下载者数
+	Series      []Point        `json:"series"`
+	Versions    []VersionCount `json:"versions"`
+	Files       []FileCount    `json:"files"`
+	TopUsers    []UserCount    `json:"top_users"`
+}
+
+// periodStart 返回时间所在周期的开始日期 (本地时区)
+func periodStart(t time.Time, interval string) time.Time {
+	t = t.In(time.Local)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	if interval == IntervalWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+func nextPeriod(t time.Time, interval string) time.Time {
+	if interval == IntervalWeek {
+		return t.AddDate(0, 0, 7)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// ParseRange 解析查询范围 [from, to]，均为本地日期，为空时默认最近 30 天
+func ParseRange(from, to string) (time.Time, time.Time, error) {
+	end := periodStart(time.Now(), IntervalDay)
+	if to != "" {
+		t, err := time.ParseInLocation(DateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("结束日期格式错误: %s", to)
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, -29)
+	if from != "" {
+		t, err := time.ParseInLocation(DateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("开始日期格式错误: %s", from)
+		}
+		start = t
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始日期晚于结束日期")
+	}
+	if end.Sub(start) >= MaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("查询范围不能超过 %d 天", MaxDays)
+	}
+	return start, end, nil
+}
+
+// versionAt 返回下载时包含该文件的最新版本，找不到时返回 -1
+func versionAt(dataset model.Dataset, file model.DatasetFile, at time.Time) int {
+	for i := len(dataset.Versions) - 1; i >= 0; i-- {
+		version := dataset.Versions[i]
+		if created, err := time.Parse(time.RFC3339, version.CreationTime); err == nil && created.After(at) {
+			continue
+		}
+		for _, f := range version.Files {
+			if f.SameFile(file) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Build 汇总下载事件，from 与 to 为本地日期 (含)
+// 版本按下载时包含所下载文件的最新版本计
+func Build(dataset model.Dataset, events []sql.DownloadEvent, interval string, from, to time.Time, top int) (*Report, error) {
+	if interval != IntervalWeek {
+		interval = IntervalDay
+	}
+	report := &Report{
+		Owner:    dataset.Owner,
+		Name:     dataset.Name,
+		Interval: interval,
+		From:     from.Format(DateLayout),
+		To:       to.Format(DateLayout),
+		Series:   []Point{},
+		Versions: []VersionCount{},
+		Files:    []FileCount{},
+		TopUsers: []UserCount{},
+	}
+
+	type bucket struct {
+		downloads int
+		users     map[string]bool
+	}
+	buckets := map[string]*bucket{}
+	users := map[string]int{}
+	versions := map[int]int{}
+	files := map[string]*FileCount{}
+
+	for _, event := range events {
+		var eventFiles []model.DatasetFile
+		if err := json.Unmarshal([]byte(event.Files), &eventFiles); err != nil {
+			return nil, fmt.Errorf("解析下载事件 %s 出错: %s", event.ID, err)
+		}
+
+		period := periodStart(event.CreatedAt, interval).Format(DateLayout)
+		b := buckets[period]
+		if b == nil {
+			b = &bucket{users: map[string]bool{}}
+			buckets[period] = b
+		}
+		b.downloads++
+		b.users[event.User] = true
+		users[event.User]++
+		report.Downloads++
+
+		counted := map[int]bool{}
+		for _, file := range eventFiles {
+			key := file.Hash + "/" + file.FileName
+			if files[key] == nil {
+				files[key] = &FileCount{Hash: file.Hash, FileName: file.FileName}
+			}
+			files[key].Downloads++
+			if v := versionAt(dataset, file, event.CreatedAt); v >= 0 && !counted[v] {
+				counted[v] = true
+				versions[v]++
+			}
+		}
+	}
+
+	// 补齐没有下载的周期，便于绘图
+	for t := periodStart(from, interval); !t.After(to); t = nextPeriod(t, interval) {
+		period := t.Format(DateLayout)
+		point := Point{Period: period}
+		if b := buckets[period]; b != nil {
+			point.Downloads = b.downloads
+			point.Users = len(b.users)
+		}
+		report.Series = append(report.Series, point)
+	}
+
+	report.UniqueUsers = len(users)
+	for i := range dataset.Versions {
+		report.Versions = append(report.Versions, VersionCount{Version: i, Downloads: versions[i]})
+	}
+	for _, file := range files {
+		report.Files = append(report.Files, *file)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		if report.Files[i].Downloads != report.Files[j].Downloads {
+			return report.Files[i].Downloads > report.Files[j].Downloads
+		}
+		return report.Files[i].FileName < report.Files[j].FileName
+	})
+	for user, downloads := range users {
+		report.TopUsers = append(report.TopUsers, UserCount{User: user, Downloads: downloads})
+	}
+	sort.Slice(report.TopUsers, func(i, j int) bool {
+		if report.TopUsers[i].Downloads != report.TopUsers[j].Downloads {
+			return report.TopUsers[i].Downloads > report.TopUsers[j].Downloads
+		}
+		return report.TopUsers[i].User < report.TopUsers[j].User
+	})
+	if top > 0 && len(report.TopUsers) > top {
+		report.TopUsers = report.TopUsers[:top]
+	}
+	return report, nil
+}
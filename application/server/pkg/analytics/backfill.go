@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/sql"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// legacyEventID 早期记录没有记录ID，按内容生成固定的事件ID，重复导入时跳过
+func legacyEventID(record model.Record) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		record.DatasetOwner, record.DatasetName, record.User, record.Time,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Backfill 将链上已有的下载记录导入下载事件表，返回导入的记录数
+func Backfill() (int, error) {
+	res, err := bc.ChannelQuery("queryAllDatasets", nil)
+	if err != nil {
+		return 0, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	var datasets []model.Dataset
+	if err := json.Unmarshal(res.Payload, &datasets); err != nil {
+		return 0, fmt.Errorf("反序列化出错: %s", err)
+	}
+
+	imported := 0
+	for _, dataset := range datasets {
+		res, err := bc.ChannelQuery("queryRecordsByDataset", [][]byte{
+			[]byte(dataset.Owner),
+			[]byte(dataset.Name),
+		})
+		if err != nil {
+			return imported, fmt.Errorf("调用智能合约出错: %s", err)
+		}
+		var records []model.Record
+		if err := json.Unmarshal(res.Payload, &records); err != nil {
+			return imported, fmt.Errorf("反序列化出错: %s", err)
+		}
+
+		for _, record := range records {
+			id := legacyEventID(record)
+			if exist, err := sql.DownloadEventExists(id, record.ID); err != nil {
+				return imported, fmt.Errorf("数据库出错: %s", err)
+			} else if exist {
+				continue
+			}
+
+			createdAt, err := time.Parse(time.RFC3339, record.Time)
+			if err != nil {
+				return imported, fmt.Errorf("下载时间格式错误: %s", record.Time)
+			}
+			files, err := json.Marshal(record.Files)
+			if err != nil {
+				return imported, fmt.Errorf("序列化出错: %s", err)
+			}
+			// 批量写入的记录ID为 "交易ID-序号"
+			txID, _, _ := strings.Cut(record.ID, "-")
+			event := sql.DownloadEvent{
+				ID:           id,
+				DatasetOwner: record.DatasetOwner,
+				DatasetName:  record.DatasetName,
+				User:         record.User,
+				Files:        string(files),
+				CreatedAt:    createdAt,
+				Status:       sql.EventCommitted,
+				TxID:         txID,
+				RecordID:     record.ID,
+			}
+			if err := sql.CreateDownloadEvent(&event); err != nil {
+				return imported, fmt.Errorf("数据库出错: %s", err)
+			}
+			imported++
+		}
+	}
+	return imported, nil
+}
@@ -0,0 +1,51 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSV 导出的内容
+const (
+	SectionSeries   = "series"
+	SectionVersions = "versions"
+	SectionFiles    = "files"
+	SectionUsers    = "users"
+)
+
+// WriteCSV 将统计结果的一部分导出为 CSV
+func WriteCSV(w io.Writer, report *Report, section string) error {
+	var rows [][]string
+	switch section {
+	case SectionSeries:
+		rows = append(rows, []string{"period", "downloads", "users"})
+		for _, p := range report.Series {
+			rows = append(rows, []string{p.Period, strconv.Itoa(p.Downloads), strconv.Itoa(p.Users)})
+		}
+	case SectionVersions:
+		rows = append(rows, []string{"version", "downloads"})
+		for _, v := range report.Versions {
+			rows = append(rows, []string{strconv.Itoa(v.Version), strconv.Itoa(v.Downloads)})
+		}
+	case SectionFiles:
+		rows = append(rows, []string{"hash", "filename", "downloads"})
+		for _, f := range report.Files {
+			rows = append(rows, []string{f.Hash, f.FileName, strconv.Itoa(f.Downloads)})
+		}
+	case SectionUsers:
+		rows = append(rows, []string{"user", "downloads"})
+		for _, u := range report.TopUsers {
+			rows = append(rows, []string{u.User, strconv.Itoa(u.Downloads)})
+		}
+	default:
+		return fmt.Errorf("不支持的导出内容: %s", section)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
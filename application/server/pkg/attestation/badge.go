@@ -0,0 +1,64 @@
+package attestation
+
+import (
+	"application/model"
+	"sort"
+)
+
+// Badge 数据集版本某一认证类型的汇总
+type Badge struct {
+	Claim     string   `json:"claim"`      // 认证类型
+	Result    string   `json:"result"`     // 汇总结果: 任一认证者未通过为 fail，否则有警告为 warn，否则为 pass
+	Pass      int      `json:"pass"`       // 通过的认证者数
+	Warn      int      `json:"warn"`       // 有警告的认证者数
+	Fail      int      `json:"fail"`       // 未通过的认证者数
+	Attesters []string `json:"attesters"`  // 认证者列表
+	UpdatedAt string   `json:"updated_at"` // 最近一次认证的时间
+}
+
+// severity 结果的严重程度，汇总时取最严重的结果
+var severity = map[string]int{
+	model.AttestationPass: 0,
+	model.AttestationWarn: 1,
+	model.AttestationFail: 2,
+}
+
+// Badges 按认证类型汇总认证结果，按认证类型排序
+// active 为当前持有认证者角色的用户，角色已撤销的认证者的认证不计入汇总
+func Badges(attestations []model.Attestation, active map[string]bool) []Badge {
+	byClaim := map[string]*Badge{}
+	for _, attestation := range attestations {
+		if !active[attestation.Attester] {
+			continue
+		}
+		badge := byClaim[attestation.Claim]
+		if badge == nil {
+			badge = &Badge{Claim: attestation.Claim, Result: model.AttestationPass, Attesters: []string{}}
+			byClaim[attestation.Claim] = badge
+		}
+		switch attestation.Result {
+		case model.AttestationPass:
+			badge.Pass++
+		case model.AttestationWarn:
+			badge.Warn++
+		case model.AttestationFail:
+			badge.Fail++
+		}
+		if severity[attestation.Result] > severity[badge.Result] {
+			badge.Result = attestation.Result
+		}
+		badge.Attesters = append(badge.Attesters, attestation.Attester)
+		// 交易时间均为 UTC RFC 3339，可以按字符串比较
+		if attestation.Time > badge.UpdatedAt {
+			badge.UpdatedAt = attestation.Time
+		}
+	}
+
+	badges := make([]Badge, 0, len(byClaim))
+	for _, badge := range byClaim {
+		sort.Strings(badge.Attesters)
+		badges = append(badges, *badge)
+	}
+	sort.Slice(badges, func(i, j int) bool { return badges[i].Claim < badges[j].Claim })
+	return badges
+}
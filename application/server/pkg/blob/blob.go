@@ -0,0 +1,45 @@
+package blob
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+const (
+	Dir           = "data/Files"      // 文件存储目录，文件名为 SHA-256
+	QuarantineDir = "data/Quarantine" // 隔离目录，存放校验失败的文件
+)
+
+var hashPattern = regexp.MustCompile("^[a-f0-9]{64}$")
+
+// ValidHash 检查是否为小写十六进制 SHA-256
+func ValidHash(hash string) bool {
+	return hashPattern.MatchString(hash)
+}
+
+// Path 返回文件在本地的存储路径
+func Path(hash string) string {
+	return filepath.Join(Dir, hash)
+}
+
+// Exists 检查本地文件是否存在
+func Exists(hash string) bool {
+	info, err := os.Stat(Path(hash))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Quarantine 将文件移入隔离目录，返回隔离后的路径
+// 隔离文件名附加时间戳，避免同一文件多次隔离时互相覆盖
+func Quarantine(hash string) (string, error) {
+	if err := os.MkdirAll(QuarantineDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	dst := filepath.Join(QuarantineDir, fmt.Sprintf("%s.%d", hash, time.Now().Unix()))
+	if err := os.Rename(Path(hash), dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
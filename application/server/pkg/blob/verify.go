@@ -0,0 +1,91 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// holdSize 校验完成前扣留的数据量
+// 文件损坏时客户端收不到最后一段数据，响应长度与 Content-Length 不符
+const holdSize = 64 * 1024
+
+// CorruptError 文件内容与哈希不一致
+type CorruptError struct {
+	Hash   string // 期望的哈希 (文件名)
+	Actual string // 实际内容的哈希
+}
+
+func (e *CorruptError) Error() string {
+	return fmt.Sprintf("文件已损坏: %s 实际哈希 %s", e.Hash, e.Actual)
+}
+
+// verifyingReader 边读边计算哈希，读完并校验通过后才放出最后一段数据
+type verifyingReader struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected string
+	chunk    []byte
+	buf      []byte
+	verified bool
+	err      error
+}
+
+// NewVerifyingReader 返回校验哈希的 Reader，内容与 expected 不一致时返回 *CorruptError
+func NewVerifyingReader(r io.Reader, expected string) io.Reader {
+	return &verifyingReader{
+		r:        r,
+		hash:     sha256.New(),
+		expected: expected,
+		chunk:    make([]byte, 32*1024),
+	}
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	for !v.verified && v.err == nil && len(v.buf) <= holdSize {
+		n, err := v.r.Read(v.chunk)
+		v.hash.Write(v.chunk[:n])
+		v.buf = append(v.buf, v.chunk[:n]...)
+		if err == io.EOF {
+			v.verify()
+		} else if err != nil {
+			v.err = err
+		}
+	}
+
+	avail := len(v.buf) - holdSize
+	if v.verified {
+		avail = len(v.buf)
+	}
+	if avail > 0 {
+		n := copy(p, v.buf[:avail])
+		v.buf = v.buf[n:]
+		return n, nil
+	}
+	if v.verified {
+		return 0, io.EOF
+	}
+	return 0, v.err
+}
+
+func (v *verifyingReader) verify() {
+	actual := hex.EncodeToString(v.hash.Sum(nil))
+	if actual != v.expected {
+		v.buf = nil
+		v.err = &CorruptError{Hash: v.expected, Actual: actual}
+		return
+	}
+	v.verified = true
+}
+
+// Digest 返回 SHA-256 摘要的 base64 编码，用于 Digest / Repr-Digest 响应头
+func Digest(hash string) string {
+	sum, err := hex.DecodeString(hash)
+	if err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(sum)
+}
@@ -0,0 +1,122 @@
+package card
+
+import (
+	"application/model"
+	"application/pkg/preview"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// 导出格式
+const (
+	FormatMarkdown  = "markdown"
+	FormatCroissant = "croissant"
+	FormatDCAT      = "dcat"
+)
+
+// File 数据集卡片中的文件 (最新版本)
+type File struct {
+	model.DatasetFile
+	Size  int64          // 文件大小，未知时为 -1
+	Split string         // 所属的数据划分
+	Stats *preview.Stats // 表格文件的统计信息，未统计时为 nil
+}
+
+// Card 生成数据集卡片所需的信息
+type Card struct {
+	Owner       string
+	Name        string
+	Metadata    model.Metadata
+	Versions    []model.Version
+	Files       []File // 最新版本的文件
+	License     string // SPDX 标识符
+	LicenseName string
+	Gated       bool
+	Downloads   int
+	URL         string // 卡片地址，作为导出文档的标识
+}
+
+// ID 数据集标识 owner/name
+func (c Card) ID() string {
+	return c.Owner + "/" + c.Name
+}
+
+// Latest 最新版本，没有版本时返回 nil
+func (c Card) Latest() *model.Version {
+	if len(c.Versions) == 0 {
+		return nil
+	}
+	return &c.Versions[len(c.Versions)-1]
+}
+
+// Keywords 合并元数据中的标签、任务与模态
+func (c Card) Keywords() []string {
+	var keywords []string
+	seen := map[string]bool{}
+	for _, list := range [][]string{c.Metadata.Tags, c.Metadata.Tasks, c.Metadata.SubTasks, c.Metadata.Modalities} {
+		for _, keyword := range nonEmpty(list) {
+			if !seen[keyword] {
+				seen[keyword] = true
+				keywords = append(keywords, keyword)
+			}
+		}
+	}
+	return keywords
+}
+
+// Description 数据集描述
+func (c Card) Description() string {
+	description := c.ID() + " dataset"
+	if latest := c.Latest(); latest != nil && latest.ChangeLog != "" {
+		description += ": " + latest.ChangeLog
+	}
+	return description
+}
+
+// LicenseURL SPDX 许可证地址
+func (c Card) LicenseURL() string {
+	if c.License == "" {
+		return ""
+	}
+	return "https://spdx.org/licenses/" + c.License + ".html"
+}
+
+// nonEmpty 过滤空字符串 (数据库中空列表会拆分为 [""])
+func nonEmpty(list []string) []string {
+	var res []string
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// MediaType 文件的 MIME 类型，未指定时根据文件名推断
+func (f File) MediaType() string {
+	if f.ContentType != "" {
+		return f.ContentType
+	}
+	return MediaType(f.FileName)
+}
+
+// MediaType 根据文件名推断 MIME 类型
+func MediaType(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return "text/csv"
+	case ".tsv", ".tab":
+		return "text/tab-separated-values"
+	case ".jsonl", ".ndjson":
+		return "application/jsonl"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	case ".md":
+		return "text/markdown"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(fileName)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,134 @@
+package card
+
+import (
+	"fmt"
+
+	"application/pkg/preview"
+)
+
+// croissantContext MLCommons Croissant 1.0 的 JSON-LD 上下文
+var croissantContext = map[string]interface{}{
+	"@language":     "en",
+	"@vocab":        "https://schema.org/",
+	"citeAs":        "cr:citeAs",
+	"column":        "cr:column",
+	"conformsTo":    "dct:conformsTo",
+	"cr":            "http://mlcommons.org/croissant/",
+	"rai":           "http://mlcommons.org/croissant/RAI/",
+	"data":          map[string]string{"@id": "cr:data", "@type": "@json"},
+	"dataType":      map[string]string{"@id": "cr:dataType", "@type": "@vocab"},
+	"dct":           "http://purl.org/dc/terms/",
+	"examples":      map[string]string{"@id": "cr:examples", "@type": "@json"},
+	"extract":       "cr:extract",
+	"field":         "cr:field",
+	"fileProperty":  "cr:fileProperty",
+	"fileObject":    "cr:fileObject",
+	"fileSet":       "cr:fileSet",
+	"format":        "cr:format",
+	"includes":      "cr:includes",
+	"isLiveDataset": "cr:isLiveDataset",
+	"jsonPath":      "cr:jsonPath",
+	"key":           "cr:key",
+	"md5":           "cr:md5",
+	"parentField":   "cr:parentField",
+	"path":          "cr:path",
+	"recordSet":     "cr:recordSet",
+	"references":    "cr:references",
+	"regex":         "cr:regex",
+	"repeated":      "cr:repeated",
+	"replace":       "cr:replace",
+	"sc":            "https://schema.org/",
+	"separator":     "cr:separator",
+	"source":        "cr:source",
+	"subField":      "cr:subField",
+	"transform":     "cr:transform",
+}
+
+// croissantTypes 推断的列类型到 Croissant dataType 的映射
+var croissantTypes = map[string]string{
+	preview.TypeBoolean: "sc:Boolean",
+	preview.TypeInteger: "sc:Integer",
+	preview.TypeNumber:  "sc:Float",
+	preview.TypeString:  "sc:Text",
+	"date":              "sc:Date",
+	"timestamp":         "sc:DateTime",
+}
+
+// Croissant 生成 MLCommons Croissant JSON-LD
+func Croissant(c Card) map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context":    croissantContext,
+		"@type":       "sc:Dataset",
+		"@id":         c.URL,
+		"name":        c.Name,
+		"description": c.Description(),
+		"conformsTo":  "http://mlcommons.org/croissant/1.0",
+		"url":         c.URL,
+		"creator":     map[string]string{"@type": "sc:Person", "name": c.Owner},
+	}
+	if c.License != "" {
+		doc["license"] = c.LicenseURL()
+	}
+	if keywords := c.Keywords(); len(keywords) > 0 {
+		doc["keywords"] = keywords
+	}
+	if languages := nonEmpty(c.Metadata.Languages); len(languages) > 0 {
+		doc["inLanguage"] = languages
+	}
+	if len(c.Versions) > 0 {
+		doc["version"] = fmt.Sprintf("%d", len(c.Versions)-1)
+		doc["datePublished"] = c.Versions[0].CreationTime
+		doc["dateModified"] = c.Latest().CreationTime
+	}
+
+	distribution := []interface{}{}
+	recordSets := []interface{}{}
+	for _, file := range c.Files {
+		object := map[string]interface{}{
+			"@type":          "cr:FileObject",
+			"@id":            file.FileName,
+			"name":           file.FileName,
+			"contentUrl":     file.FileName,
+			"encodingFormat": file.MediaType(),
+			"sha256":         file.Hash,
+		}
+		if file.Description != "" {
+			object["description"] = file.Description
+		}
+		if file.Size >= 0 {
+			object["contentSize"] = fmt.Sprintf("%d B", file.Size)
+		}
+		distribution = append(distribution, object)
+
+		if file.Stats == nil || len(file.Stats.Columns) == 0 {
+			continue
+		}
+		fields := []interface{}{}
+		for _, column := range file.Stats.Columns {
+			field := map[string]interface{}{
+				"@type": "cr:Field",
+				"@id":   file.FileName + "/" + column.Name,
+				"name":  column.Name,
+				"source": map[string]interface{}{
+					"fileObject": map[string]string{"@id": file.FileName},
+					"extract":    map[string]string{"column": column.Name},
+				},
+			}
+			if dataType, ok := croissantTypes[column.Type]; ok {
+				field["dataType"] = dataType
+			}
+			fields = append(fields, field)
+		}
+		recordSets = append(recordSets, map[string]interface{}{
+			"@type": "cr:RecordSet",
+			"@id":   file.FileName,
+			"name":  file.FileName,
+			"field": fields,
+		})
+	}
+	doc["distribution"] = distribution
+	if len(recordSets) > 0 {
+		doc["recordSet"] = recordSets
+	}
+	return doc
+}
@@ -0,0 +1,79 @@
+package card
+
+// dcatContext DCAT 3 的 JSON-LD 上下文
+var dcatContext = map[string]string{
+	"dcat": "http://www.w3.org/ns/dcat#",
+	"dct":  "http://purl.org/dc/terms/",
+	"foaf": "http://xmlns.com/foaf/0.1/",
+	"spdx": "http://spdx.org/rdf/terms#",
+	"xsd":  "http://www.w3.org/2001/XMLSchema#",
+}
+
+// DCAT 生成 W3C DCAT JSON-LD
+func DCAT(c Card) map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context":        dcatContext,
+		"@type":           "dcat:Dataset",
+		"@id":             c.URL,
+		"dct:identifier":  c.ID(),
+		"dct:title":       c.Name,
+		"dct:description": c.Description(),
+		"dct:publisher": map[string]string{
+			"@type":     "foaf:Agent",
+			"foaf:name": c.Owner,
+		},
+	}
+	if keywords := c.Keywords(); len(keywords) > 0 {
+		doc["dcat:keyword"] = keywords
+	}
+	if languages := nonEmpty(c.Metadata.Languages); len(languages) > 0 {
+		doc["dct:language"] = languages
+	}
+	if c.License != "" {
+		doc["dct:license"] = map[string]string{"@id": c.LicenseURL()}
+	}
+	if c.Gated {
+		doc["dct:accessRights"] = "restricted"
+	} else {
+		doc["dct:accessRights"] = "public"
+	}
+	if len(c.Versions) > 0 {
+		doc["dct:issued"] = dateTime(c.Versions[0].CreationTime)
+		doc["dct:modified"] = dateTime(c.Latest().CreationTime)
+		doc["dcat:version"] = len(c.Versions) - 1
+	}
+
+	distributions := []interface{}{}
+	for _, file := range c.Files {
+		distribution := map[string]interface{}{
+			"@type":          "dcat:Distribution",
+			"dct:title":      file.FileName,
+			"dcat:mediaType": file.MediaType(),
+			"dcat:accessURL": map[string]string{"@id": c.URL},
+			"spdx:checksum": map[string]interface{}{
+				"@type":              "spdx:Checksum",
+				"spdx:algorithm":     map[string]string{"@id": "spdx:checksumAlgorithm_sha256"},
+				"spdx:checksumValue": file.Hash,
+			},
+		}
+		if file.Description != "" {
+			distribution["dct:description"] = file.Description
+		}
+		if file.Size >= 0 {
+			distribution["dcat:byteSize"] = map[string]interface{}{
+				"@value": file.Size,
+				"@type":  "xsd:nonNegativeInteger",
+			}
+		}
+		if c.License != "" {
+			distribution["dct:license"] = map[string]string{"@id": c.LicenseURL()}
+		}
+		distributions = append(distributions, distribution)
+	}
+	doc["dcat:distribution"] = distributions
+	return doc
+}
+
+func dateTime(value string) map[string]string {
+	return map[string]string{"@value": value, "@type": "xsd:dateTime"}
+}
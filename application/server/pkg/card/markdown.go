@@ -0,0 +1,118 @@
+package card
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown 生成带 YAML 头信息的 Markdown 数据集卡片
+func Markdown(c Card) string {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	if c.License != "" {
+		fmt.Fprintf(&b, "license: %s\n", yamlString(strings.ToLower(c.License)))
+	}
+	writeYAMLList(&b, "task_categories", c.Metadata.Tasks)
+	writeYAMLList(&b, "task_ids", c.Metadata.SubTasks)
+	writeYAMLList(&b, "modalities", c.Metadata.Modalities)
+	writeYAMLList(&b, "language", c.Metadata.Languages)
+	writeYAMLList(&b, "library_name", c.Metadata.Libraries)
+	writeYAMLList(&b, "tags", c.Metadata.Tags)
+	if latest := c.Latest(); latest != nil && len(latest.Splits) > 0 {
+		b.WriteString("configs:\n- config_name: default\n  data_files:\n")
+		for _, split := range latest.Splits {
+			fmt.Fprintf(&b, "  - split: %s\n    path:\n", yamlString(split.Name))
+			for _, file := range split.Files {
+				fmt.Fprintf(&b, "    - %s\n", yamlString(file))
+			}
+		}
+	}
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", c.ID())
+	b.WriteString(c.Description() + "\n\n")
+
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Owner | %s |\n", c.Owner)
+	if c.License != "" {
+		fmt.Fprintf(&b, "| License | [%s](%s) |\n", escapeCell(c.LicenseName), c.LicenseURL())
+	} else {
+		b.WriteString("| License | - |\n")
+	}
+	fmt.Fprintf(&b, "| Versions | %d |\n", len(c.Versions))
+	fmt.Fprintf(&b, "| Downloads | %d |\n", c.Downloads)
+	if c.Gated {
+		b.WriteString("| Access | gated (request required) |\n")
+	}
+	if latest := c.Latest(); latest != nil {
+		fmt.Fprintf(&b, "| Rows | %d |\n", latest.Rows)
+		if latest.MerkleRoot != "" {
+			fmt.Fprintf(&b, "| Merkle root | `%s` |\n", latest.MerkleRoot)
+		}
+	}
+	b.WriteString("\n")
+
+	if len(c.Files) > 0 {
+		b.WriteString("## Files\n\n| File | Split | Size | Rows | SHA-256 |\n|---|---|---|---|---|\n")
+		for _, file := range c.Files {
+			size, rows := "-", "-"
+			if file.Size >= 0 {
+				size = fmt.Sprintf("%d", file.Size)
+			}
+			if file.Stats != nil {
+				rows = fmt.Sprintf("%d", file.Stats.Rows)
+			}
+			split := file.Split
+			if split == "" {
+				split = "-"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | `%s` |\n", escapeCell(file.FileName), split, size, rows, file.Hash)
+		}
+		b.WriteString("\n")
+
+		for _, file := range c.Files {
+			if file.Stats == nil || len(file.Stats.Columns) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "### %s\n\n| Column | Type | Nulls |\n|---|---|---|\n", escapeCell(file.FileName))
+			for _, column := range file.Stats.Columns {
+				fmt.Fprintf(&b, "| %s | %s | %d |\n", escapeCell(column.Name), column.Type, column.Nulls)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(c.Versions) > 0 {
+		b.WriteString("## Versions\n\n| Version | Created | Files | Rows | Change log |\n|---|---|---|---|---|\n")
+		for i := len(c.Versions) - 1; i >= 0; i-- {
+			v := c.Versions[i]
+			fmt.Fprintf(&b, "| %d | %s | %d | %d | %s |\n", i, v.CreationTime, len(v.Files), v.Rows, escapeCell(v.ChangeLog))
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeYAMLList(b *strings.Builder, key string, list []string) {
+	list = nonEmpty(list)
+	if len(list) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, item := range list {
+		fmt.Fprintf(b, "- %s\n", yamlString(item))
+	}
+}
+
+// yamlString 以双引号形式输出 YAML 字符串
+func yamlString(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+// escapeCell 转义 Markdown 表格单元格
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,43 @@
+package comment
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxLength 评论内容的最大字符数
+	MaxLength = 10000
+	// MaxMentions 一条评论最多通知的用户数
+	MaxMentions = 10
+)
+
+// mentionPattern 匹配 @用户ID，用户ID为 3-16 位字母、数字或下划线
+// 前一个字符不能是单词字符或 '.'，避免匹配邮箱地址
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\w{3,16})\b`)
+
+// Mentions 解析评论中提到的用户，去重并保持出现顺序，最多返回 MaxMentions 个
+func Mentions(content string) []string {
+	users := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		user := match[1]
+		if seen[user] {
+			continue
+		}
+		seen[user] = true
+		users = append(users, user)
+		if len(users) == MaxMentions {
+			break
+		}
+	}
+	return users
+}
+
+// ValidContent 检查评论内容不为空白且不超过 MaxLength 个字符
+func ValidContent(content string) bool {
+	return strings.TrimSpace(content) != "" &&
+		utf8.ValidString(content) &&
+		utf8.RuneCountInString(content) <= MaxLength
+}
@@ -0,0 +1,74 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	var many strings.Builder
+	for i := 0; i < MaxMentions+3; i++ {
+		fmt.Fprintf(&many, "@user_%02d ", i)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "none", content: "no mentions here", want: []string{}},
+		{name: "start of text", content: "@alice thanks", want: []string{"alice"}},
+		{name: "after space", content: "thanks @alice and @bob_2", want: []string{"alice", "bob_2"}},
+		{name: "punctuation", content: "(@alice), cc:@bob; @carol!", want: []string{"alice", "bob", "carol"}},
+		{name: "after newline", content: "line\n@alice", want: []string{"alice"}},
+		{name: "after non-ascii", content: "你好@alice", want: []string{"alice"}},
+		{name: "email", content: "mail bob@example.com or a.b@example.org", want: []string{}},
+		{name: "after dot", content: "see.@alice", want: []string{}},
+		{name: "double at", content: "@@alice", want: []string{}},
+		{name: "minimum length", content: "@abc", want: []string{"abc"}},
+		{name: "too short", content: "@ab", want: []string{}},
+		{name: "maximum length", content: "@" + strings.Repeat("a", 16), want: []string{strings.Repeat("a", 16)}},
+		{name: "too long", content: "@" + strings.Repeat("a", 17), want: []string{}},
+		{name: "too long with suffix", content: "@" + strings.Repeat("a", 17) + " ok", want: []string{}},
+		{name: "trailing punctuation", content: "@alice.", want: []string{"alice"}},
+		{name: "non-ascii id", content: "@张三", want: []string{}},
+		{name: "dedup keeps order", content: "@bob @alice @bob @alice", want: []string{"bob", "alice"}},
+		{name: "case sensitive", content: "@Alice @alice", want: []string{"Alice", "alice"}},
+		{name: "capped", content: many.String(), want: []string{
+			"user_00", "user_01", "user_02", "user_03", "user_04",
+			"user_05", "user_06", "user_07", "user_08", "user_09",
+		}},
+		{name: "duplicates do not count towards cap", content: strings.Repeat("@alice ", 20) + "@bob", want: []string{"alice", "bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Mentions(tt.content)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || got == nil {
+				t.Fatalf("Mentions(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "text", content: "looks good", want: true},
+		{name: "empty", content: "", want: false},
+		{name: "whitespace", content: " \n\t ", want: false},
+		{name: "max length", content: strings.Repeat("a", MaxLength), want: true},
+		{name: "too long", content: strings.Repeat("a", MaxLength+1), want: false},
+		// 按字符而不是字节计算长度
+		{name: "max length multibyte", content: strings.Repeat("评", MaxLength), want: true},
+		{name: "invalid utf-8", content: "ok \xff", want: false},
+	}
+	for _, tt := range tests {
+		if got := ValidContent(tt.content); got != tt.want {
+			t.Errorf("%s: ValidContent() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"log"
 	// "time"
 
+	"application/conf"
+	"application/pkg/fsck"
+	"application/pkg/scan"
+
 	// bc "application/blockchain"
 	// "application/model"
 
@@ -22,6 +26,16 @@ func Init() {
 	if err != nil {
 		log.Printf("定时任务开启失败 %s", err)
 	}
+	if spec := conf.Conf.FsckConfig.Spec; spec != "" {
+		if _, err := c.AddFunc(spec, RunFsck); err != nil {
+			log.Printf("文件检查定时任务开启失败 %s", err)
+		}
+	}
+	if spec := conf.Conf.ScanConfig.Spec; spec != "" {
+		if _, err := c.AddFunc(spec, RunScan); err != nil {
+			log.Printf("文件扫描定时任务开启失败 %s", err)
+		}
+	}
 	c.Start()
 	log.Printf("定时任务已开启")
 	select {}
@@ -72,3 +86,37 @@ func GoRun() {
 	// 	}
 	// }
 }
+
+// RunFsck 检查本地文件与链上记录是否一致，并保存检查结果
+func RunFsck() {
+	report, err := fsck.Run(conf.Conf.FsckConfig.Quarantine)
+	SaveFsck(report, err)
+}
+
+// SaveFsck 保存检查结果并记录日志
+func SaveFsck(report fsck.Report, err error) {
+	if err != nil {
+		log.Printf("文件检查失败 %s", err)
+		return
+	}
+	id, err := fsck.Save(report)
+	if err != nil {
+		log.Printf("保存文件检查结果失败 %s", err)
+		return
+	}
+	log.Printf("文件检查完成 #%d: 检查 %d 个文件，发现 %d 个问题", id, report.Checked, len(report.Issues))
+}
+
+// RunScan 扫描尚未扫描的文件中的敏感信息
+func RunScan() {
+	LogScan(scan.Run())
+}
+
+// LogScan 记录后台扫描的结果，扫描结果已按文件保存
+func LogScan(count int, err error) {
+	if err != nil {
+		log.Printf("文件扫描失败 %s", err)
+		return
+	}
+	log.Printf("文件扫描完成: 扫描 %d 个文件", count)
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	bc "application/blockchain"
+	"application/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// retryInterval 订阅断开后重新订阅的间隔
+const retryInterval = 10 * time.Second
+
+// Handler 处理一个链码事件，返回错误时断开订阅，稍后从该事件所在区块重放
+type Handler func(event *fab.CCEvent) error
+
+// Run 订阅名称匹配 filter 的链码事件并逐个处理
+// 处理进度以 name 保存在数据库中，服务重启后从上次处理到的区块继续
+// 该区块中已处理过的事件会再次送达，handler 须能容忍重复
+func Run(name string, filter string, handler Handler) {
+	for {
+		if err := subscribe(name, filter, handler); err != nil {
+			log.Printf("[%s] 订阅链码事件出错: %s", name, err)
+		}
+		time.Sleep(retryInterval)
+	}
+}
+
+func subscribe(name string, filter string, handler Handler) error {
+	from, err := sql.GetEventCursor(name)
+	if err != nil {
+		return fmt.Errorf("查询事件进度出错: %s", err)
+	}
+	events, unregister, err := bc.RegisterChaincodeEvents(filter, from)
+	if err != nil {
+		return err
+	}
+	defer unregister()
+
+	for event := range events {
+		if err := handler(event); err != nil {
+			return fmt.Errorf("处理事件 %s (%s) 出错: %s", event.EventName, event.TxID, err)
+		}
+		if err := sql.SaveEventCursor(name, event.BlockNumber); err != nil {
+			return fmt.Errorf("保存事件进度出错: %s", err)
+		}
+	}
+	return fmt.Errorf("事件订阅已关闭")
+}
@@ -0,0 +1,199 @@
+package fsck
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/blob"
+	"application/sql"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// 问题类型
+const (
+	KindMissing      = "missing"       // 链上有记录，本地文件不存在
+	KindCorrupt      = "corrupt"       // 文件内容哈希与文件名不一致
+	KindSizeMismatch = "size_mismatch" // 文件大小与链上记录不一致
+	KindOrphaned     = "orphaned"      // 本地文件在链上没有记录
+)
+
+// Issue 单个文件的检查问题
+type Issue struct {
+	Hash        string `json:"hash"`
+	Kind        string `json:"kind"`
+	Detail      string `json:"detail"`
+	Quarantined bool   `json:"quarantined"`
+}
+
+// Report 一次检查的结果
+type Report struct {
+	StartTime  time.Time `json:"start_time"`
+	FinishTime time.Time `json:"finish_time"`
+	Checked    int       `json:"checked"`    // 检查的链上文件数
+	Quarantine bool      `json:"quarantine"` // 是否隔离损坏文件
+	Issues     []Issue   `json:"issues"`
+}
+
+// ErrRunning 已有检查正在进行
+var ErrRunning = errors.New("文件检查正在进行")
+
+var running sync.Mutex
+
+// Run 对照链上文件记录检查本地存储的文件
+// quarantine 为 true 时将损坏的文件移入隔离目录
+func Run(quarantine bool) (Report, error) {
+	if !running.TryLock() {
+		return Report{}, ErrRunning
+	}
+	defer running.Unlock()
+	return run(quarantine)
+}
+
+// Start 在后台开始检查，结束后调用 done
+func Start(quarantine bool, done func(Report, error)) error {
+	if !running.TryLock() {
+		return ErrRunning
+	}
+	go func() {
+		defer running.Unlock()
+		done(run(quarantine))
+	}()
+	return nil
+}
+
+func run(quarantine bool) (Report, error) {
+	report := Report{StartTime: time.Now(), Quarantine: quarantine}
+
+	res, err := bc.ChannelQuery("queryAllFiles", [][]byte{})
+	if err != nil {
+		return report, fmt.Errorf("查询链上文件出错: %s", err)
+	}
+	var files []model.File
+	if err := json.Unmarshal(res.Payload, &files); err != nil {
+		return report, fmt.Errorf("反序列化出错: %s", err)
+	}
+
+	known := make(map[string]bool, len(files))
+	for _, file := range files {
+		known[file.Hash] = true
+		report.Checked++
+		if issue, ok := checkFile(file, quarantine); !ok {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	entries, err := os.ReadDir(blob.Dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return report, fmt.Errorf("读取文件目录出错: %s", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || known[entry.Name()] {
+			continue
+		}
+		report.Issues = append(report.Issues, Issue{
+			Hash:   entry.Name(),
+			Kind:   KindOrphaned,
+			Detail: "链上没有该文件的记录",
+		})
+	}
+
+	report.FinishTime = time.Now()
+	return report, nil
+}
+
+// checkFile 重新计算文件哈希并核对大小，返回 false 表示发现问题
+func checkFile(file model.File, quarantine bool) (Issue, bool) {
+	issue := Issue{Hash: file.Hash}
+
+	f, err := os.Open(blob.Path(file.Hash))
+	if errors.Is(err, os.ErrNotExist) {
+		issue.Kind = KindMissing
+		issue.Detail = "本地文件不存在"
+		return issue, false
+	}
+	if err != nil {
+		issue.Kind = KindMissing
+		issue.Detail = fmt.Sprintf("打开文件出错: %s", err)
+		return issue, false
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	f.Close()
+	if err != nil {
+		issue.Kind = KindCorrupt
+		issue.Detail = fmt.Sprintf("读取文件出错: %s", err)
+		return issue, false
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != file.Hash {
+		issue.Kind = KindCorrupt
+		issue.Detail = fmt.Sprintf("哈希不一致: %s", sum)
+	} else if size != file.Size {
+		issue.Kind = KindSizeMismatch
+		issue.Detail = fmt.Sprintf("大小不一致: 本地 %d, 链上 %d", size, file.Size)
+	} else {
+		return issue, true
+	}
+
+	// 大小不一致但哈希正确时内容完好，只隔离哈希不一致的文件
+	if quarantine && issue.Kind == KindCorrupt {
+		if dst, err := blob.Quarantine(file.Hash); err != nil {
+			issue.Detail += fmt.Sprintf("; 隔离失败: %s", err)
+		} else {
+			issue.Quarantined = true
+			issue.Detail += fmt.Sprintf("; 已隔离至 %s", dst)
+		}
+	}
+	return issue, false
+}
+
+// Save 保存检查结果到数据库，返回检查ID
+func Save(report Report) (uint, error) {
+	run := sql.FsckRun{
+		StartTime:  report.StartTime,
+		FinishTime: report.FinishTime,
+		Checked:    report.Checked,
+		Issues:     len(report.Issues),
+		Quarantine: report.Quarantine,
+	}
+	issues := make([]sql.BlobIssue, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues = append(issues, sql.BlobIssue{
+			Hash:        issue.Hash,
+			Kind:        issue.Kind,
+			Detail:      issue.Detail,
+			Quarantined: issue.Quarantined,
+		})
+	}
+	if err := sql.CreateFsckRun(&run, issues); err != nil {
+		return 0, err
+	}
+	return run.ID, nil
+}
+
+// MarkCorrupt 记录读取时发现的损坏文件并将其隔离
+func MarkCorrupt(e *blob.CorruptError) {
+	issue := sql.BlobIssue{
+		Hash:   e.Hash,
+		Kind:   KindCorrupt,
+		Detail: fmt.Sprintf("读取校验失败，实际哈希 %s", e.Actual),
+	}
+	if dst, err := blob.Quarantine(e.Hash); err != nil {
+		issue.Detail += fmt.Sprintf("; 隔离失败: %s", err)
+	} else {
+		issue.Quarantined = true
+		issue.Detail += fmt.Sprintf("; 已隔离至 %s", dst)
+	}
+	if err := sql.CreateBlobIssue(&issue); err != nil {
+		log.Printf("保存损坏文件记录失败 %s: %s", e.Hash, err)
+	}
+}
@@ -0,0 +1,89 @@
+package license
+
+import "sort"
+
+// spdxIDs 支持的 SPDX 许可证标识符 (https://spdx.org/licenses/)
+// 收录常见的软件许可证与数据/内容许可证
+var spdxIDs = map[string]string{
+	// 公共领域与宽松许可证
+	"0BSD":         "BSD Zero Clause License",
+	"Unlicense":    "The Unlicense",
+	"MIT":          "MIT License",
+	"MIT-0":        "MIT No Attribution",
+	"ISC":          "ISC License",
+	"BSD-2-Clause": "BSD 2-Clause \"Simplified\" License",
+	"BSD-3-Clause": "BSD 3-Clause \"New\" or \"Revised\" License",
+	"Apache-2.0":   "Apache License 2.0",
+	"Zlib":         "zlib License",
+	"BSL-1.0":      "Boost Software License 1.0",
+	"PSF-2.0":      "Python Software Foundation License 2.0",
+	"WTFPL":        "Do What The F*ck You Want To Public License",
+
+	// Copyleft 许可证
+	"GPL-2.0-only":      "GNU General Public License v2.0 only",
+	"GPL-2.0-or-later":  "GNU General Public License v2.0 or later",
+	"GPL-3.0-only":      "GNU General Public License v3.0 only",
+	"GPL-3.0-or-later":  "GNU General Public License v3.0 or later",
+	"LGPL-2.1-only":     "GNU Lesser General Public License v2.1 only",
+	"LGPL-2.1-or-later": "GNU Lesser General Public License v2.1 or later",
+	"LGPL-3.0-only":     "GNU Lesser General Public License v3.0 only",
+	"LGPL-3.0-or-later": "GNU Lesser General Public License v3.0 or later",
+	"AGPL-3.0-only":     "GNU Affero General Public License v3.0 only",
+	"AGPL-3.0-or-later": "GNU Affero General Public License v3.0 or later",
+	"MPL-2.0":           "Mozilla Public License 2.0",
+	"EPL-2.0":           "Eclipse Public License 2.0",
+	"EUPL-1.2":          "European Union Public License 1.2",
+	"GFDL-1.3-only":     "GNU Free Documentation License v1.3 only",
+	"GFDL-1.3-or-later": "GNU Free Documentation License v1.3 or later",
+
+	// Creative Commons
+	"CC0-1.0":         "Creative Commons Zero v1.0 Universal",
+	"CC-BY-3.0":       "Creative Commons Attribution 3.0 Unported",
+	"CC-BY-4.0":       "Creative Commons Attribution 4.0 International",
+	"CC-BY-SA-3.0":    "Creative Commons Attribution Share Alike 3.0 Unported",
+	"CC-BY-SA-4.0":    "Creative Commons Attribution Share Alike 4.0 International",
+	"CC-BY-NC-3.0":    "Creative Commons Attribution Non Commercial 3.0 Unported",
+	"CC-BY-NC-4.0":    "Creative Commons Attribution Non Commercial 4.0 International",
+	"CC-BY-NC-SA-3.0": "Creative Commons Attribution Non Commercial Share Alike 3.0 Unported",
+	"CC-BY-NC-SA-4.0": "Creative Commons Attribution Non Commercial Share Alike 4.0 International",
+	"CC-BY-ND-4.0":    "Creative Commons Attribution No Derivatives 4.0 International",
+	"CC-BY-NC-ND-3.0": "Creative Commons Attribution Non Commercial No Derivatives 3.0 Unported",
+	"CC-BY-NC-ND-4.0": "Creative Commons Attribution Non Commercial No Derivatives 4.0 International",
+	"CC-PDDC":         "Creative Commons Public Domain Dedication and Certification",
+
+	// 开放数据许可证
+	"ODbL-1.0":            "Open Data Commons Open Database License v1.0",
+	"ODC-By-1.0":          "Open Data Commons Attribution License v1.0",
+	"PDDL-1.0":            "Open Data Commons Public Domain Dedication & License 1.0",
+	"CDLA-Permissive-1.0": "Community Data License Agreement Permissive 1.0",
+	"CDLA-Permissive-2.0": "Community Data License Agreement Permissive 2.0",
+	"CDLA-Sharing-1.0":    "Community Data License Agreement Sharing 1.0",
+	"C-UDA-1.0":           "Computational Use of Data Agreement v1.0",
+	"OGL-UK-3.0":          "Open Government Licence v3.0",
+	"OGL-Canada-2.0":      "Open Government Licence - Canada",
+	"etalab-2.0":          "Etalab Open License 2.0",
+	"DL-DE-BY-2.0":        "Data licence Germany – attribution – version 2.0",
+	"NLOD-2.0":            "Norwegian Licence for Open Government Data (NLOD) 2.0",
+	"O-UDA-1.0":           "Open Use of Data Agreement v1.0",
+}
+
+// Valid 判断是否为支持的 SPDX 许可证标识符
+func Valid(id string) bool {
+	_, ok := spdxIDs[id]
+	return ok
+}
+
+// Name 返回许可证全名
+func Name(id string) string {
+	return spdxIDs[id]
+}
+
+// List 返回全部支持的许可证标识符 (按字母排序)
+func List() []string {
+	ids := make([]string, 0, len(spdxIDs))
+	for id := range spdxIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
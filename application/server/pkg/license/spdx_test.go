@@ -0,0 +1,33 @@
+package license
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+// 链码独立校验许可证，两份列表必须一致
+const chaincodeList = "../../../../chaincode/pkg/utils/spdx.go"
+
+func TestMatchesChaincode(t *testing.T) {
+	src, err := os.ReadFile(chaincodeList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, m := range regexp.MustCompile(`(?m)^\s*"([^"]+)":\s*true,`).FindAllStringSubmatch(string(src), -1) {
+		ids = append(ids, m[1])
+	}
+	sort.Strings(ids)
+
+	want := List()
+	if len(ids) != len(want) {
+		t.Fatalf("chaincode lists %d licenses, server lists %d", len(ids), len(want))
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("license lists differ: chaincode %q, server %q", ids[i], want[i])
+		}
+	}
+}
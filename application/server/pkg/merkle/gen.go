@@ -0,0 +1,5 @@
+package merkle
+
+// merkle.go 由链码 chaincode/pkg/merkle/merkle.go 生成，链码与服务端必须使用相同的树结构
+// 修改时只改链码中的文件，然后在本目录执行 go generate
+//go:generate sh -c "{ echo '// Code generated from chaincode/pkg/merkle/merkle.go by go generate. DO NOT EDIT.'; echo; cat ../../../../chaincode/pkg/merkle/merkle.go; } > merkle.go"
@@ -0,0 +1,29 @@
+package merkle
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// 链码中的文件是唯一来源，服务端副本去掉生成头后必须与之一致
+const chaincodeSource = "../../../../chaincode/pkg/merkle/merkle.go"
+
+func TestMatchesChaincode(t *testing.T) {
+	want, err := os.ReadFile(chaincodeSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("merkle.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := []byte("// Code generated ")
+	if !bytes.HasPrefix(got, header) {
+		t.Fatal("merkle.go is missing the generated header, run go generate")
+	}
+	got = got[bytes.Index(got, []byte("\n\n"))+2:]
+	if !bytes.Equal(got, want) {
+		t.Fatal("merkle.go differs from the chaincode copy, run go generate")
+	}
+}
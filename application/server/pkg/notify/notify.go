@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"application/model"
+	"application/pkg/events"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// cursorName 事件处理进度在数据库中的名称
+const cursorName = "notify"
+
+// eventFilter 订阅的链码事件名
+const eventFilter = "^dataset-"
+
+// Run 订阅数据集变更的链码事件，为关注该数据集的用户生成通知
+// 同一交易对同一用户只生成一条通知，重放的事件不会重复生成
+func Run() {
+	events.Run(cursorName, eventFilter, Handle)
+}
+
+// Handle 为一个链码事件生成通知
+func Handle(event *fab.CCEvent) error {
+	switch event.EventName {
+	case model.EventDatasetVersionAdded, model.EventDatasetDeleted, model.EventDatasetLicenseChanged:
+	default:
+		return nil
+	}
+
+	var payload model.DatasetEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("反序列化出错: %s", err)
+	}
+	watchers, err := sql.QueryWatchers(payload.Owner, payload.Name)
+	if err != nil {
+		return fmt.Errorf("查询关注者出错: %s", err)
+	}
+
+	notifications := make([]sql.Notification, 0, len(watchers))
+	for _, user := range watchers {
+		notifications = append(notifications, sql.Notification{
+			User:         user,
+			TxID:         event.TxID,
+			Type:         event.EventName,
+			DatasetOwner: payload.Owner,
+			DatasetName:  payload.Name,
+			Version:      payload.Version,
+			License:      payload.License,
+			Time:         payload.Time,
+		})
+	}
+	return sql.CreateNotifications(notifications)
+}
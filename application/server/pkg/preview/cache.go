@@ -0,0 +1,56 @@
+package preview
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// cacheSize 缓存的预览结果数量
+// 文件按哈希存储且内容不可变，缓存无需失效
+const cacheSize = 256
+
+type cacheEntry struct {
+	key     string
+	preview *Preview
+}
+
+var cache = struct {
+	sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}{
+	entries: map[string]*list.Element{},
+	order:   list.New(),
+}
+
+// Cached 预览哈希为 hash 的文件，相同参数的结果从缓存返回
+func Cached(hash string, path string, format string, offset int, limit int) (*Preview, error) {
+	limit = normalizeLimit(limit)
+	key := fmt.Sprintf("%s:%s:%d:%d", hash, format, offset, limit)
+
+	cache.Lock()
+	if e, ok := cache.entries[key]; ok {
+		cache.order.MoveToFront(e)
+		cache.Unlock()
+		return e.Value.(*cacheEntry).preview, nil
+	}
+	cache.Unlock()
+
+	p, err := File(path, format, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if _, ok := cache.entries[key]; !ok {
+		cache.entries[key] = cache.order.PushFront(&cacheEntry{key: key, preview: p})
+		if cache.order.Len() > cacheSize {
+			oldest := cache.order.Back()
+			cache.order.Remove(oldest)
+			delete(cache.entries, oldest.Value.(*cacheEntry).key)
+		}
+	}
+	return p, nil
+}
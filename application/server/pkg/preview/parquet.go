@@ -0,0 +1,173 @@
+package preview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// parquetFile 只读的本地 Parquet 文件
+type parquetFile struct {
+	*os.File
+}
+
+func (f parquetFile) Open(name string) (source.ParquetFile, error) {
+	if name == "" {
+		name = f.Name()
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return parquetFile{file}, nil
+}
+
+func (f parquetFile) Create(string) (source.ParquetFile, error) {
+	return nil, errors.New("只读文件")
+}
+
+// readParquet 读取 Parquet 文件的顶层列
+func readParquet(f *os.File, offset int, limit int) (p *Preview, err error) {
+	// 第三方库遇到损坏的文件可能 panic
+	defer func() {
+		if r := recover(); r != nil {
+			p, err = nil, fmt.Errorf("解析 Parquet 文件出错: %v", r)
+		}
+	}()
+
+	pr, err := reader.NewParquetReader(parquetFile{f}, nil, 1)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Parquet 文件出错: %s", err)
+	}
+	defer pr.ReadStop()
+
+	total := pr.GetNumRows()
+	p = &Preview{TotalRows: &total, Columns: parquetColumns(pr)}
+	if int64(offset) >= total {
+		return p, nil
+	}
+	if err := pr.SkipRows(int64(offset)); err != nil {
+		return nil, fmt.Errorf("跳过行出错: %s", err)
+	}
+
+	n := limit
+	if remain := total - int64(offset); remain < int64(n) {
+		n = int(remain)
+	}
+	rows, err := pr.ReadByNumber(n)
+	if err != nil {
+		return nil, fmt.Errorf("读取行出错: %s", err)
+	}
+
+	size := 0
+	for _, row := range rows {
+		values := parquetRow(row)
+		data, _ := json.Marshal(values)
+		size += len(data)
+		if size > ByteBudget && len(p.Rows) > 0 {
+			p.Truncated = true
+			break
+		}
+		p.Rows = append(p.Rows, values)
+	}
+	p.HasMore = int64(offset+len(p.Rows)) < total
+	return p, nil
+}
+
+// parquetColumns 返回根节点的直接子节点，嵌套结构作为一列
+func parquetColumns(pr *reader.ParquetReader) []Column {
+	elements := pr.SchemaHandler.SchemaElements
+	columns := []Column{}
+	if len(elements) == 0 {
+		return columns
+	}
+
+	// 按深度优先顺序遍历，跳过嵌套子节点
+	idx := 1
+	for i := int32(0); i < elements[0].GetNumChildren() && idx < len(elements); i++ {
+		columns = append(columns, Column{
+			Name: pr.SchemaHandler.Infos[idx].ExName,
+			Type: parquetType(elements[idx]),
+		})
+		idx = skipSubtree(elements, idx)
+	}
+	return columns
+}
+
+// skipSubtree 返回 idx 节点之后第一个不属于其子树的节点
+func skipSubtree(elements []*parquet.SchemaElement, idx int) int {
+	children := elements[idx].GetNumChildren()
+	idx++
+	for i := int32(0); i < children && idx < len(elements); i++ {
+		idx = skipSubtree(elements, idx)
+	}
+	return idx
+}
+
+func parquetType(e *parquet.SchemaElement) string {
+	if e.IsSetConvertedType() {
+		switch e.GetConvertedType() {
+		case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON:
+			return TypeString
+		case parquet.ConvertedType_LIST:
+			return TypeArray
+		case parquet.ConvertedType_MAP, parquet.ConvertedType_MAP_KEY_VALUE:
+			return TypeObject
+		case parquet.ConvertedType_DECIMAL:
+			return "decimal"
+		case parquet.ConvertedType_DATE:
+			return "date"
+		case parquet.ConvertedType_TIMESTAMP_MILLIS, parquet.ConvertedType_TIMESTAMP_MICROS:
+			return "timestamp"
+		}
+	}
+	if e.GetNumChildren() > 0 {
+		if e.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			return TypeArray
+		}
+		return TypeObject
+	}
+	if e.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+		return TypeArray
+	}
+	switch e.GetType() {
+	case parquet.Type_BOOLEAN:
+		return TypeBoolean
+	case parquet.Type_INT32, parquet.Type_INT64:
+		return TypeInteger
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return TypeNumber
+	case parquet.Type_INT96:
+		return "timestamp"
+	}
+	return TypeBinary
+}
+
+// parquetRow 将动态生成的结构体转换为按列排列的值
+func parquetRow(row interface{}) []interface{} {
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return []interface{}{row}
+	}
+	values := make([]interface{}, v.NumField())
+	for i := range values {
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr && field.IsNil() {
+			continue
+		}
+		values[i] = field.Interface()
+	}
+	return values
+}
@@ -0,0 +1,132 @@
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20      // 默认返回行数
+	MaxLimit     = 100     // 单次最多返回行数
+	ByteBudget   = 1 << 20 // 单次返回的行数据上限 (字节)
+)
+
+// 支持的文件格式
+const (
+	FormatCSV     = "csv"
+	FormatTSV     = "tsv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// 推断的列类型
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeBinary  = "binary"
+	TypeMixed   = "mixed"
+)
+
+// ErrUnsupported 不支持预览的文件格式
+var ErrUnsupported = errors.New("不支持预览该文件格式")
+
+// Column 列信息
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Preview 文件预览结果
+type Preview struct {
+	Format    string          `json:"format"`
+	Columns   []Column        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`                 // 按 Columns 顺序排列
+	Offset    int             `json:"offset"`               // 起始行 (不含表头)
+	HasMore   bool            `json:"has_more"`             // 之后是否还有数据
+	Truncated bool            `json:"truncated"`            // 是否因字节上限提前结束
+	TotalRows *int64          `json:"total_rows,omitempty"` // 总行数 (仅 Parquet)
+	Splits    []string        `json:"splits,omitempty"`     // 文件所属的数据划分
+}
+
+// Format 根据文件名判断预览格式，不支持时返回空字符串
+func Format(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV
+	case ".tsv", ".tab":
+		return FormatTSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".parquet":
+		return FormatParquet
+	}
+	return ""
+}
+
+// File 预览本地文件，从第 offset 行开始最多返回 limit 行
+func File(path string, format string, offset int, limit int) (*Preview, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("起始行不能为负数: %d", offset)
+	}
+	limit = normalizeLimit(limit)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p *Preview
+	switch format {
+	case FormatCSV:
+		p, err = readDelimited(f, ',', offset, limit)
+	case FormatTSV:
+		p, err = readDelimited(f, '\t', offset, limit)
+	case FormatJSONL:
+		p, err = readJSONLines(f, offset, limit)
+	case FormatParquet:
+		p, err = readParquet(f, offset, limit)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Format = format
+	p.Offset = offset
+	if p.Rows == nil {
+		p.Rows = [][]interface{}{}
+	}
+	return p, nil
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+// mergeType 合并同一列不同行推断出的类型
+func mergeType(a, b string, conflict string) string {
+	switch {
+	case a == "" || a == TypeNull:
+		return b
+	case b == "" || b == TypeNull || a == b:
+		return a
+	case (a == TypeInteger && b == TypeNumber) || (a == TypeNumber && b == TypeInteger):
+		return TypeNumber
+	}
+	return conflict
+}
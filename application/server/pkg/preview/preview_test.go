@@ -0,0 +1,358 @@
+package preview
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// columnsString 以 name:type 形式输出列，便于比较
+func columnsString(columns []Column) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = column.Name + ":" + column.Type
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestReadDelimited(t *testing.T) {
+	const table = "id,name,score\n1,alice,1.5\n2,bob,2\n3,carol,\n"
+	long := strings.Repeat("x", 400<<10)
+
+	tests := []struct {
+		name      string
+		input     string
+		comma     rune
+		offset    int
+		limit     int
+		columns   string
+		rows      string
+		hasMore   bool
+		truncated bool
+		err       bool
+	}{
+		{name: "empty", input: "", comma: ',', limit: 10, columns: "", rows: "[]"},
+		{name: "header only", input: "a,b\n", comma: ',', limit: 10, columns: "a:null b:null", rows: "[]"},
+		{name: "types", input: table, comma: ',', limit: 10,
+			columns: "id:integer name:string score:number",
+			rows:    "[[1 alice 1.5] [2 bob 2] [3 carol ]]"},
+		{name: "tsv", input: "a\tb\ntrue\tx y\n", comma: '\t', limit: 10,
+			columns: "a:boolean b:string", rows: "[[true x y]]"},
+		{name: "limit", input: table, comma: ',', limit: 2,
+			columns: "id:integer name:string score:number",
+			rows:    "[[1 alice 1.5] [2 bob 2]]", hasMore: true},
+		// 类型只根据返回的行推断
+		{name: "offset", input: table, comma: ',', offset: 1, limit: 1,
+			columns: "id:integer name:string score:integer",
+			rows:    "[[2 bob 2]]", hasMore: true},
+		{name: "offset past end", input: table, comma: ',', offset: 5, limit: 10,
+			columns: "id:null name:null score:null", rows: "[]"},
+		// 多出的字段补充为无名列，缺少的字段补 nil
+		{name: "ragged rows", input: "a,b\n1\n2,3,4\n", comma: ',', limit: 10,
+			columns: "a:integer b:integer column_3:integer",
+			rows:    "[[1 <nil> <nil>] [2 3 4]]"},
+		{name: "quoted newline", input: "a,b\n\"x\ny\",1\n", comma: ',', limit: 10,
+			columns: "a:string b:integer", rows: "[[x\ny 1]]"},
+		{name: "byte budget", input: "a\n" + long + "\n" + long + "\n" + long + "\n", comma: ',', limit: 10,
+			columns: "a:string", rows: fmt.Sprintf("[[%s] [%s]]", long, long), hasMore: true, truncated: true},
+		{name: "row over budget", input: "a\n1\n" + strings.Repeat("x", 2*ByteBudget) + "\n2\n", comma: ',', limit: 10,
+			columns: "a:integer", rows: "[[1]]", hasMore: true, truncated: true},
+		{name: "first row over budget", input: "a\n" + strings.Repeat("x", 2*ByteBudget) + "\n", comma: ',', limit: 10,
+			columns: "a:null", rows: "[]", hasMore: true, truncated: true},
+		{name: "header over budget", input: strings.Repeat("x", 2*ByteBudget) + "\n1\n", comma: ',', limit: 10, err: true},
+		{name: "skipped row over budget", input: "a\n" + strings.Repeat("x", 2*ByteBudget) + "\n1\n", comma: ',',
+			offset: 1, limit: 10, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := readDelimited(strings.NewReader(tt.input), tt.comma, tt.offset, tt.limit)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("readDelimited() = %+v, want error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := columnsString(p.Columns); got != tt.columns {
+				t.Errorf("columns = %q, want %q", got, tt.columns)
+			}
+			if p.Rows == nil {
+				p.Rows = [][]interface{}{}
+			}
+			if got := fmt.Sprint(p.Rows); got != tt.rows {
+				t.Errorf("rows = %.80q, want %.80q", got, tt.rows)
+			}
+			if p.HasMore != tt.hasMore || p.Truncated != tt.truncated {
+				t.Errorf("hasMore %v truncated %v, want %v %v", p.HasMore, p.Truncated, tt.hasMore, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestReadJSONLines(t *testing.T) {
+	const lines = "{\"b\":1,\"a\":\"x\"}\n\n{\"a\":null,\"c\":[1]}\n5\n"
+
+	tests := []struct {
+		name      string
+		input     string
+		offset    int
+		limit     int
+		columns   string
+		rows      string
+		hasMore   bool
+		truncated bool
+		err       bool
+	}{
+		{name: "empty", input: "", limit: 10, columns: "", rows: "[]"},
+		// 列按首次出现的顺序排列，非对象的行放入 value 列，空行跳过
+		{name: "objects", input: lines, limit: 10,
+			columns: "b:integer a:string c:array value:integer",
+			rows:    "[[1 x <nil> <nil>] [<nil> <nil> [1] <nil>] [<nil> <nil> <nil> 5]]"},
+		{name: "mixed types", input: "{\"a\":1}\n{\"a\":\"x\"}\n{\"a\":1.5}\n", limit: 10,
+			columns: "a:mixed", rows: "[[1] [x] [1.5]]"},
+		{name: "integer and number", input: "{\"a\":1}\n{\"a\":1.5}\n", limit: 10,
+			columns: "a:number", rows: "[[1] [1.5]]"},
+		{name: "limit", input: lines, limit: 1, columns: "b:integer a:string", rows: "[[1 x]]", hasMore: true},
+		{name: "offset", input: lines, offset: 1, limit: 1, columns: "a:null c:array", rows: "[[<nil> [1]]]", hasMore: true},
+		{name: "offset past end", input: lines, offset: 3, limit: 10, columns: "", rows: "[]"},
+		{name: "byte budget", input: strings.Repeat(fmt.Sprintf("{\"a\":%q}\n", strings.Repeat("x", 400<<10)), 3), limit: 10,
+			columns: "a:string", rows: "", hasMore: true, truncated: true},
+		{name: "invalid json", input: "{\"a\":1}\n{\"a\":\n", limit: 10, err: true},
+		{name: "line over budget", input: "{\"a\":\"" + strings.Repeat("x", ByteBudget) + "\"}\n", limit: 10, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := readJSONLines(strings.NewReader(tt.input), tt.offset, tt.limit)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("readJSONLines() = %+v, want error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := columnsString(p.Columns); got != tt.columns {
+				t.Errorf("columns = %q, want %q", got, tt.columns)
+			}
+			if p.Rows == nil {
+				p.Rows = [][]interface{}{}
+			}
+			if got := fmt.Sprint(p.Rows); tt.rows != "" && got != tt.rows {
+				t.Errorf("rows = %.80q, want %.80q", got, tt.rows)
+			}
+			if tt.truncated && len(p.Rows) != 2 {
+				t.Errorf("rows = %d, want 2 within the byte budget", len(p.Rows))
+			}
+			if p.HasMore != tt.hasMore || p.Truncated != tt.truncated {
+				t.Errorf("hasMore %v truncated %v, want %v %v", p.HasMore, p.Truncated, tt.hasMore, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestInferString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", TypeNull},
+		{"  ", TypeNull},
+		{"42", TypeInteger},
+		{"-7", TypeInteger},
+		{"1.5", TypeNumber},
+		{"1e3", TypeNumber},
+		{"true", TypeBoolean},
+		{"FALSE", TypeBoolean},
+		{"t", TypeString}, // 单个字母不视为布尔值
+		{"abc", TypeString},
+	}
+	for _, tt := range tests {
+		if got := inferString(tt.value); got != tt.want {
+			t.Errorf("inferString(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMergeType(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", TypeInteger, TypeInteger},
+		{TypeNull, TypeString, TypeString},
+		{TypeInteger, TypeNull, TypeInteger},
+		{TypeInteger, TypeInteger, TypeInteger},
+		{TypeInteger, TypeNumber, TypeNumber},
+		{TypeNumber, TypeInteger, TypeNumber},
+		{TypeInteger, TypeBoolean, TypeMixed},
+	}
+	for _, tt := range tests {
+		if got := mergeType(tt.a, tt.b, TypeMixed); got != tt.want {
+			t.Errorf("mergeType(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+type parquetRecord struct {
+	Name  string   `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Score *float64 `parquet:"name=score, type=DOUBLE, repetitiontype=OPTIONAL"`
+}
+
+// writeFile 在临时目录中写入测试文件
+func writeFile(t *testing.T, name string, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeParquet 写入 n 行，偶数行的 score 为空
+func writeParquet(t *testing.T, n int) string {
+	path := filepath.Join(t.TempDir(), "data.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	pw, err := writer.NewParquetWriterFromWriter(f, new(parquetRecord), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		record := parquetRecord{Name: fmt.Sprintf("row%d", i)}
+		if i%2 == 1 {
+			score := float64(i)
+			record.Score = &score
+		}
+		if err := pw.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFile(t *testing.T) {
+	csvPath := writeFile(t, "data.csv", "a,b\n1,x\n2,y\n3,z\n")
+	parquetPath := writeParquet(t, 5)
+
+	tests := []struct {
+		name    string
+		path    string
+		format  string
+		offset  int
+		limit   int
+		columns string
+		rows    string
+		hasMore bool
+		total   int64
+		err     bool
+	}{
+		{name: "csv", path: csvPath, format: FormatCSV, offset: 1, limit: 1,
+			columns: "a:integer b:string", rows: "[[2 y]]", hasMore: true},
+		{name: "default limit", path: csvPath, format: FormatCSV, limit: 0,
+			columns: "a:integer b:string", rows: "[[1 x] [2 y] [3 z]]"},
+		{name: "parquet", path: parquetPath, format: FormatParquet, offset: 2, limit: 2,
+			columns: "name:string score:number", rows: "[[row2 <nil>] [row3 3]]", hasMore: true, total: 5},
+		{name: "parquet offset past end", path: parquetPath, format: FormatParquet, offset: 5, limit: 2,
+			columns: "name:string score:number", rows: "[]", total: 5},
+		{name: "negative offset", path: csvPath, format: FormatCSV, offset: -1, err: true},
+		{name: "unsupported", path: csvPath, format: "xlsx", err: true},
+		{name: "corrupt parquet", path: csvPath, format: FormatParquet, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := File(tt.path, tt.format, tt.offset, tt.limit)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("File() = %+v, want error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := columnsString(p.Columns); got != tt.columns {
+				t.Errorf("columns = %q, want %q", got, tt.columns)
+			}
+			rows := make([]string, len(p.Rows))
+			for i, row := range p.Rows {
+				values := make([]string, len(row))
+				for j, v := range row {
+					if f, ok := v.(*float64); ok {
+						v = *f
+					}
+					values[j] = fmt.Sprint(v)
+				}
+				rows[i] = "[" + strings.Join(values, " ") + "]"
+			}
+			if got := "[" + strings.Join(rows, " ") + "]"; got != tt.rows {
+				t.Errorf("rows = %q, want %q", got, tt.rows)
+			}
+			if p.HasMore != tt.hasMore || p.Offset != tt.offset || p.Format != tt.format {
+				t.Errorf("File() = %+v", p)
+			}
+			if tt.total > 0 && (p.TotalRows == nil || *p.TotalRows != tt.total) {
+				t.Errorf("total rows = %v, want %d", p.TotalRows, tt.total)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		format  string
+		rows    int64
+		columns string // name:type:nulls
+		err     bool
+	}{
+		{name: "csv", path: writeFile(t, "a.csv", "a,b\n1,x\n2.5,\n3\n"), format: FormatCSV,
+			rows: 3, columns: "a:number:0 b:string:2"},
+		{name: "csv ragged", path: writeFile(t, "b.csv", "a\n1\n2,x\n"), format: FormatCSV,
+			rows: 2, columns: "a:integer:0 column_2:string:1"},
+		{name: "csv empty", path: writeFile(t, "c.csv", ""), format: FormatCSV, rows: 0, columns: ""},
+		{name: "tsv", path: writeFile(t, "d.tsv", "a\tb\ntrue\t1\n"), format: FormatTSV,
+			rows: 1, columns: "a:boolean:0 b:integer:0"},
+		{name: "jsonl", path: writeFile(t, "e.jsonl", "{\"a\":1}\n{\"b\":\"x\",\"a\":null}\n\n"), format: FormatJSONL,
+			rows: 2, columns: "a:integer:1 b:string:1"},
+		{name: "parquet", path: writeParquet(t, 5), format: FormatParquet,
+			rows: 5, columns: "name:string:0 score:number:3"},
+		{name: "csv row over budget", path: writeFile(t, "f.csv", "a\n"+strings.Repeat("x", 2*ByteBudget)+"\n"),
+			format: FormatCSV, err: true},
+		{name: "invalid jsonl", path: writeFile(t, "g.jsonl", "{\n"), format: FormatJSONL, err: true},
+		{name: "unsupported", path: writeFile(t, "h.txt", "x"), format: "txt", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := Analyze(tt.path, tt.format)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("Analyze() = %+v, want error", stats)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts := make([]string, len(stats.Columns))
+			for i, column := range stats.Columns {
+				parts[i] = fmt.Sprintf("%s:%s:%d", column.Name, column.Type, column.Nulls)
+			}
+			if got := strings.Join(parts, " "); got != tt.columns || stats.Rows != tt.rows || stats.Format != tt.format {
+				t.Errorf("Analyze() = %d rows %q, want %d rows %q", stats.Rows, got, tt.rows, tt.columns)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// statsBatch 统计 Parquet 文件时每批读取的行数
+const statsBatch = 1000
+
+// MaxStatsBytes 单次请求中同步统计的文件总大小上限 (字节)，超出时由调用方转入后台统计
+const MaxStatsBytes = 256 << 20
+
+// ColumnStats 列统计信息
+type ColumnStats struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Nulls int64  `json:"nulls"` // 空值数量
+}
+
+// Stats 文件统计信息
+type Stats struct {
+	Format  string        `json:"format"`
+	Rows    int64         `json:"rows"` // 数据行数，不含表头
+	Columns []ColumnStats `json:"columns"`
+}
+
+// columnSet 按首次出现的顺序收集列
+type columnSet struct {
+	index   map[string]int
+	columns []ColumnStats
+}
+
+func (s *columnSet) get(name string) *ColumnStats {
+	if s.index == nil {
+		s.index = map[string]int{}
+	}
+	i, ok := s.index[name]
+	if !ok {
+		i = len(s.columns)
+		s.index[name] = i
+		s.columns = append(s.columns, ColumnStats{Name: name})
+	}
+	return &s.columns[i]
+}
+
+func (s *columnSet) result() []ColumnStats {
+	columns := make([]ColumnStats, len(s.columns))
+	for i, column := range s.columns {
+		column.Type = orString(column.Type)
+		columns[i] = column
+	}
+	return columns
+}
+
+// Analyze 完整读取文件，统计行数与各列的类型和空值数量
+func Analyze(path string, format string) (*Stats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var stats *Stats
+	switch format {
+	case FormatCSV:
+		stats, err = analyzeDelimited(f, ',')
+	case FormatTSV:
+		stats, err = analyzeDelimited(f, '\t')
+	case FormatJSONL:
+		stats, err = analyzeJSONLines(f)
+	case FormatParquet:
+		stats, err = analyzeParquet(f)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats.Format = format
+	return stats, nil
+}
+
+func analyzeDelimited(r io.Reader, comma rune) (*Stats, error) {
+	limiter := &recordLimiter{r: r}
+	reader := csv.NewReader(limiter)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	stats := &Stats{}
+	var set columnSet
+	header, err := reader.Read()
+	if err == io.EOF {
+		stats.Columns = []ColumnStats{}
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取表头出错: %s", err)
+	}
+	// 表头可能有重复列名，按位置对应
+	for _, name := range header {
+		set.columns = append(set.columns, ColumnStats{Name: name})
+	}
+
+	for {
+		limiter.reset()
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取第 %d 行出错: %s", stats.Rows+1, err)
+		}
+		stats.Rows++
+
+		for len(set.columns) < len(record) {
+			set.columns = append(set.columns, ColumnStats{
+				Name:  fmt.Sprintf("column_%d", len(set.columns)+1),
+				Nulls: stats.Rows - 1, // 之前的行缺少该列
+			})
+		}
+		for i := range set.columns {
+			column := &set.columns[i]
+			if i >= len(record) || strings.TrimSpace(record[i]) == "" {
+				column.Nulls++
+				continue
+			}
+			column.Type = mergeType(column.Type, inferString(record[i]), TypeString)
+		}
+	}
+
+	stats.Columns = set.result()
+	return stats, nil
+}
+
+func analyzeJSONLines(r io.Reader) (*Stats, error) {
+	reader := bufio.NewReader(r)
+	stats := &Stats{}
+	var set columnSet
+	var seen []int64 // 每列出现的行数，缺失视为空值
+
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) > 0 {
+			var value interface{}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("解析第 %d 行出错: %s", line, err)
+			}
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				object = map[string]interface{}{"value": value}
+			}
+			stats.Rows++
+
+			for _, key := range objectKeys(data, object) {
+				column := set.get(key)
+				for len(seen) < len(set.columns) {
+					seen = append(seen, 0)
+				}
+				seen[set.index[key]]++
+				t := inferJSON(object[key])
+				if t == TypeNull {
+					column.Nulls++
+				}
+				column.Type = mergeType(column.Type, t, TypeMixed)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	for i := range set.columns {
+		set.columns[i].Nulls += stats.Rows - seen[i]
+	}
+	stats.Columns = set.result()
+	return stats, nil
+}
+
+func analyzeParquet(f *os.File) (stats *Stats, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stats, err = nil, fmt.Errorf("解析 Parquet 文件出错: %v", r)
+		}
+	}()
+
+	pr, err := reader.NewParquetReader(parquetFile{f}, nil, 1)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Parquet 文件出错: %s", err)
+	}
+	defer pr.ReadStop()
+
+	stats = &Stats{Rows: pr.GetNumRows()}
+	for _, column := range parquetColumns(pr) {
+		stats.Columns = append(stats.Columns, ColumnStats{Name: column.Name, Type: column.Type})
+	}
+
+	for read := int64(0); read < stats.Rows; {
+		n := statsBatch
+		if remain := stats.Rows - read; remain < int64(n) {
+			n = int(remain)
+		}
+		rows, err := pr.ReadByNumber(n)
+		if err != nil {
+			return nil, fmt.Errorf("读取行出错: %s", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			for i, value := range parquetRow(row) {
+				if value == nil && i < len(stats.Columns) {
+					stats.Columns[i].Nulls++
+				}
+			}
+		}
+		read += int64(len(rows))
+	}
+	if stats.Columns == nil {
+		stats.Columns = []ColumnStats{}
+	}
+	return stats, nil
+}
@@ -0,0 +1,274 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// recordSlack csv.Reader 内部缓冲预读的余量，单条记录最多读取 ByteBudget+recordSlack 字节
+const recordSlack = 64 << 10
+
+// errRecordTooLong 单条记录超过字节上限
+var errRecordTooLong = errors.New("记录过长")
+
+// recordLimiter 限制 csv.Reader 读取单条记录时消耗的字节数，避免超长的行整体读入内存
+// 每读完一条记录调用 reset
+type recordLimiter struct {
+	r io.Reader
+	n int // 当前记录已读取的字节数
+}
+
+func (l *recordLimiter) Read(p []byte) (int, error) {
+	remain := ByteBudget + recordSlack - l.n
+	if remain <= 0 {
+		return 0, errRecordTooLong
+	}
+	if len(p) > remain {
+		p = p[:remain]
+	}
+	n, err := l.r.Read(p)
+	l.n += n
+	return n, err
+}
+
+func (l *recordLimiter) reset() { l.n = 0 }
+
+// readDelimited 读取 CSV/TSV，第一行为表头
+// 表头或跳过的行超过字节上限时返回错误，返回的行超过上限时提前结束并标记 Truncated
+func readDelimited(r io.Reader, comma rune, offset int, limit int) (*Preview, error) {
+	limiter := &recordLimiter{r: r}
+	reader := csv.NewReader(limiter)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &Preview{Columns: []Column{}}, nil
+	}
+	if errors.Is(err, errRecordTooLong) {
+		return nil, fmt.Errorf("表头超过 %d 字节", ByteBudget)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取表头出错: %s", err)
+	}
+
+	p := &Preview{}
+	types := make([]string, len(header))
+	size := 0
+	for i := 0; ; i++ {
+		limiter.reset()
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errRecordTooLong) && i >= offset {
+			p.HasMore = true
+			p.Truncated = true
+			break
+		}
+		if errors.Is(err, errRecordTooLong) {
+			return nil, fmt.Errorf("第 %d 行超过 %d 字节", i+1, ByteBudget)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取第 %d 行出错: %s", i+1, err)
+		}
+		if i < offset {
+			continue
+		}
+		if len(p.Rows) == limit {
+			p.HasMore = true
+			break
+		}
+		for _, field := range record {
+			size += len(field) + 1
+		}
+		if size > ByteBudget && len(p.Rows) > 0 {
+			p.HasMore = true
+			p.Truncated = true
+			break
+		}
+
+		// 多出的字段补充为无名列
+		for len(header) < len(record) {
+			header = append(header, fmt.Sprintf("column_%d", len(header)+1))
+			types = append(types, "")
+		}
+		row := make([]interface{}, len(header))
+		for j, field := range record {
+			row[j] = field
+			types[j] = mergeType(types[j], inferString(field), TypeString)
+		}
+		p.Rows = append(p.Rows, row)
+	}
+
+	for i, name := range header {
+		p.Columns = append(p.Columns, Column{Name: name, Type: orString(types[i])})
+	}
+	for i := range p.Rows {
+		for len(p.Rows[i]) < len(header) {
+			p.Rows[i] = append(p.Rows[i], nil)
+		}
+	}
+	return p, nil
+}
+
+// inferString 推断文本字段的类型，空字段视为 null
+func inferString(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return TypeNull
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return TypeInteger
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return TypeNumber
+	}
+	if _, err := strconv.ParseBool(strings.ToLower(s)); err == nil && len(s) > 1 {
+		return TypeBoolean
+	}
+	return TypeString
+}
+
+func orString(t string) string {
+	if t == "" {
+		return TypeNull
+	}
+	return t
+}
+
+// readJSONLines 读取 JSON Lines，每行一个 JSON 对象，非对象的行放入 value 列
+func readJSONLines(r io.Reader, offset int, limit int) (*Preview, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), ByteBudget)
+
+	p := &Preview{}
+	var (
+		names   []string
+		types   = map[string]string{}
+		objects []map[string]interface{}
+		size    int
+		line    int
+	)
+	for scanner.Scan() {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		line++
+		if line <= offset {
+			continue
+		}
+		if len(objects) == limit {
+			p.HasMore = true
+			break
+		}
+		size += len(data)
+		if size > ByteBudget && len(objects) > 0 {
+			p.HasMore = true
+			p.Truncated = true
+			break
+		}
+
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("解析第 %d 行出错: %s", line, err)
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			object = map[string]interface{}{"value": value}
+		}
+
+		// 按首次出现的顺序记录列名
+		for _, key := range objectKeys(data, object) {
+			if _, ok := types[key]; !ok {
+				names = append(names, key)
+				types[key] = ""
+			}
+		}
+		for key, v := range object {
+			types[key] = mergeType(types[key], inferJSON(v), TypeMixed)
+		}
+		objects = append(objects, object)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("第 %d 行超过 %d 字节", line+1, ByteBudget)
+		}
+		return nil, err
+	}
+
+	for _, name := range names {
+		p.Columns = append(p.Columns, Column{Name: name, Type: orString(types[name])})
+	}
+	for _, object := range objects {
+		row := make([]interface{}, len(names))
+		for i, name := range names {
+			row[i] = object[name]
+		}
+		p.Rows = append(p.Rows, row)
+	}
+	return p, nil
+}
+
+// objectKeys 按原始顺序返回 JSON 对象的键，map 本身不保留顺序
+func objectKeys(data []byte, object map[string]interface{}) []string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return []string{"value"}
+	}
+	var keys []string
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		key, _ := tok.(string)
+		keys = append(keys, key)
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			break
+		}
+	}
+	if len(keys) != len(object) {
+		// 有重复键时退回 map 的键
+		keys = keys[:0]
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+func inferJSON(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return TypeInteger
+		}
+		return TypeNumber
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	}
+	return TypeMixed
+}
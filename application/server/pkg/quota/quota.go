@@ -0,0 +1,223 @@
+package quota
+
+import (
+	bc "application/blockchain"
+	"application/conf"
+	"application/model"
+	"application/pkg/utils"
+	"application/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// 所有者类型
+const (
+	KindUser         = "user"
+	KindOrganization = "organization"
+)
+
+// Unlimited 不限制存储空间
+const Unlimited = -1
+
+// DatasetUsage 单个数据集的用量，同一数据集内按哈希去重
+type DatasetUsage struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+	Files int    `json:"files"`
+}
+
+// Usage 所有者的存储用量，所有未删除数据集引用的文件按哈希去重后累计
+type Usage struct {
+	Owner    string         `json:"owner"`
+	Kind     string         `json:"kind"`
+	Bytes    int64          `json:"bytes"`
+	Files    int            `json:"files"`
+	Limit    int64          `json:"limit"` // 配额 (字节)，-1 表示不限制
+	Datasets []DatasetUsage `json:"datasets"`
+
+	hashes map[string]bool
+}
+
+// ExceededError 超出存储配额
+type ExceededError struct {
+	Owner string
+	Used  int64
+	Add   int64
+	Limit int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("超出存储配额: %s 已使用 %d 字节，新增 %d 字节，配额 %d 字节", e.Owner, e.Used, e.Add, e.Limit)
+}
+
+// ownerKind 判断所有者是用户还是组织
+func ownerKind(owner string) (string, error) {
+	if _, err := bc.ChannelQuery("queryOrganization", [][]byte{[]byte(owner)}); err == nil {
+		return KindOrganization, nil
+	}
+	if _, err := bc.ChannelQuery("queryUser", [][]byte{[]byte(owner)}); err == nil {
+		return KindUser, nil
+	}
+	return "", fmt.Errorf("所有者不存在: %s", owner)
+}
+
+// Limit 返回所有者的配额，优先使用数据库中单独设置的值
+func Limit(owner, kind string) (int64, error) {
+	override, err := sql.GetQuota(owner)
+	if err != nil {
+		return 0, err
+	}
+	limit := conf.Conf.QuotaConfig.UserBytes
+	if kind == KindOrganization {
+		limit = conf.Conf.QuotaConfig.OrganizationBytes
+	}
+	if override != nil {
+		limit = override.Bytes
+	}
+	if limit < 0 {
+		return Unlimited, nil
+	}
+	return limit, nil
+}
+
+// Files 查询链上文件记录
+func Files(hashes []string) ([]model.File, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	res, err := bc.ChannelQuery("queryFiles", [][]byte{[]byte(utils.ToJson(hashes))})
+	if err != nil {
+		return nil, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	var files []model.File
+	if err := json.Unmarshal(res.Payload, &files); err != nil {
+		return nil, fmt.Errorf("反序列化出错: %s", err)
+	}
+	return files, nil
+}
+
+// Compute 统计所有者的存储用量
+func Compute(owner string) (*Usage, error) {
+	kind, err := ownerKind(owner)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := Limit(owner, kind)
+	if err != nil {
+		return nil, fmt.Errorf("数据库出错: %s", err)
+	}
+
+	var res []byte
+	if kind == KindOrganization {
+		r, err := bc.ChannelQuery("queryDatasetsByOrganization", [][]byte{[]byte(owner)})
+		if err != nil {
+			return nil, fmt.Errorf("调用智能合约出错: %s", err)
+		}
+		res = r.Payload
+	} else {
+		r, err := bc.ChannelQuery("queryDatasetsByUser", [][]byte{[]byte(owner), []byte("false")})
+		if err != nil {
+			return nil, fmt.Errorf("调用智能合约出错: %s", err)
+		}
+		res = r.Payload
+	}
+	var datasets []model.Dataset
+	if err := json.Unmarshal(res, &datasets); err != nil {
+		return nil, fmt.Errorf("反序列化出错: %s", err)
+	}
+
+	// 每个数据集引用的哈希
+	refs := map[string]map[string]bool{}
+	var hashes []string
+	seen := map[string]bool{}
+	for _, dataset := range datasets {
+		if dataset.Deleted {
+			continue
+		}
+		set := map[string]bool{}
+		for _, version := range dataset.Versions {
+			for _, file := range version.Files {
+				set[file.Hash] = true
+				if !seen[file.Hash] {
+					seen[file.Hash] = true
+					hashes = append(hashes, file.Hash)
+				}
+			}
+		}
+		refs[dataset.Name] = set
+	}
+
+	files, err := Files(hashes)
+	if err != nil {
+		return nil, err
+	}
+	sizes := map[string]int64{}
+	for _, file := range files {
+		sizes[file.Hash] = file.Size
+	}
+
+	usage := &Usage{Owner: owner, Kind: kind, Limit: limit, Datasets: []DatasetUsage{}, hashes: seen}
+	for _, hash := range hashes {
+		usage.Bytes += sizes[hash]
+		usage.Files++
+	}
+	for name, set := range refs {
+		item := DatasetUsage{Name: name, Files: len(set)}
+		for hash := range set {
+			item.Bytes += sizes[hash]
+		}
+		usage.Datasets = append(usage.Datasets, item)
+	}
+	sort.Slice(usage.Datasets, func(i, j int) bool {
+		return usage.Datasets[i].Bytes > usage.Datasets[j].Bytes
+	})
+	return usage, nil
+}
+
+// Unreferenced 查询上传者已上传但尚未被任何数据集引用的文件
+// 这些文件不属于任何所有者的用量，上传时计入，避免只上传不引用而绕过配额
+func Unreferenced(uploader string) ([]model.File, error) {
+	res, err := bc.ChannelQuery("queryFilesByUploader", [][]byte{[]byte(uploader)})
+	if err != nil {
+		return nil, fmt.Errorf("调用智能合约出错: %s", err)
+	}
+	var files []model.File
+	if err := json.Unmarshal(res.Payload, &files); err != nil {
+		return nil, fmt.Errorf("反序列化出错: %s", err)
+	}
+	unreferenced := files[:0]
+	for _, file := range files {
+		if file.ReferenceCount == 0 {
+			unreferenced = append(unreferenced, file)
+		}
+	}
+	return unreferenced, nil
+}
+
+// Counted 文件是否已计入用量
+func (u *Usage) Counted(hash string) bool {
+	return u.hashes[hash]
+}
+
+// Require 检查新增 add 字节后是否超出配额
+func (u *Usage) Require(add int64) error {
+	if u.Limit == Unlimited || u.Bytes+add <= u.Limit {
+		return nil
+	}
+	return &ExceededError{Owner: u.Owner, Used: u.Bytes, Add: add, Limit: u.Limit}
+}
+
+// RequireFiles 检查引用这些文件后是否超出配额，已计入用量的文件不重复计算
+func (u *Usage) RequireFiles(files []model.File) error {
+	var add int64
+	seen := map[string]bool{}
+	for _, file := range files {
+		if u.Counted(file.Hash) || seen[file.Hash] {
+			continue
+		}
+		seen[file.Hash] = true
+		add += file.Size
+	}
+	return u.Require(add)
+}
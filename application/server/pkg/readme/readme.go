@@ -0,0 +1,83 @@
+package readme
+
+import (
+	"application/pkg/blob"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// MaxSize 说明文档的大小上限
+const MaxSize = 1 << 20
+
+// cacheSize 缓存的渲染结果数量，文件内容不可变，缓存满时清空
+const cacheSize = 128
+
+// Readme 渲染后的说明文档
+type Readme struct {
+	Hash     string `json:"hash"`
+	Markdown string `json:"markdown"`
+	HTML     string `json:"html"`
+}
+
+// markdown 不开启 WithUnsafe，原始 HTML 会被省略，危险链接 (如 javascript:) 会被过滤
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+var cache = struct {
+	sync.Mutex
+	entries map[string]*Readme
+}{entries: map[string]*Readme{}}
+
+// Load 读取说明文档原文
+func Load(hash string) ([]byte, error) {
+	if !blob.ValidHash(hash) {
+		return nil, fmt.Errorf("文件哈希格式错误: %s", hash)
+	}
+	f, err := os.Open(blob.Path(hash))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("说明文档超过 %d 字节", MaxSize)
+	}
+	return data, nil
+}
+
+// Render 读取并渲染说明文档
+func Render(hash string) (*Readme, error) {
+	cache.Lock()
+	r, ok := cache.entries[hash]
+	cache.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	data, err := Load(hash)
+	if err != nil {
+		return nil, err
+	}
+	var html bytes.Buffer
+	if err := markdown.Convert(data, &html); err != nil {
+		return nil, fmt.Errorf("渲染说明文档出错: %s", err)
+	}
+	r = &Readme{Hash: hash, Markdown: string(data), HTML: html.String()}
+
+	cache.Lock()
+	if len(cache.entries) >= cacheSize {
+		cache.entries = map[string]*Readme{}
+	}
+	cache.entries[hash] = r
+	cache.Unlock()
+	return r, nil
+}
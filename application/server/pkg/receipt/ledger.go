@@ -0,0 +1,156 @@
+package receipt
+
+import (
+	bc "application/blockchain"
+	"application/model"
+	"application/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Check 单项验证结果
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Result 回执验证结果
+type Result struct {
+	Valid  bool    `json:"valid"`
+	Checks []Check `json:"checks"`
+}
+
+func (res *Result) add(name string, err error) bool {
+	check := Check{Name: name, Passed: err == nil}
+	if err != nil {
+		check.Detail = err.Error()
+	}
+	res.Checks = append(res.Checks, check)
+	return err == nil
+}
+
+// Issue 为已提交的 createRecord(s) 交易签发回执
+func Issue(txID string, record model.Record) (Receipt, error) {
+	block, err := bc.QueryBlockByTxID(txID)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("查询交易所在区块出错: %s", err)
+	}
+
+	r := Receipt{
+		TxID:         txID,
+		RecordID:     record.ID,
+		BlockNumber:  block.GetHeader().GetNumber(),
+		DatasetOwner: record.DatasetOwner,
+		DatasetName:  record.DatasetName,
+		User:         record.User,
+		Files:        record.Files,
+		IssuedAt:     utils.GetTimeString(),
+	}
+	if err := r.Sign(); err != nil {
+		return Receipt{}, err
+	}
+	return r, nil
+}
+
+// Verify 验证回执签名，并从账本取回交易核对下载记录
+// publicKey 为空时使用本服务的签名公钥
+func Verify(r Receipt, publicKey string) Result {
+	var res Result
+	if publicKey == "" {
+		publicKey = PublicKey()
+	}
+	res.add("signature", r.VerifySignature(publicKey))
+
+	tx, err := bc.QueryTransaction(r.TxID)
+	if !res.add("transaction", err) {
+		return res
+	}
+	if tx.GetValidationCode() != int32(pb.TxValidationCode_VALID) {
+		res.add("validation", fmt.Errorf("交易未通过验证: %s", pb.TxValidationCode(tx.GetValidationCode())))
+	} else {
+		res.add("validation", nil)
+	}
+
+	txID, args, err := bc.InvocationArgs(tx.GetTransactionEnvelope())
+	if err == nil && txID != r.TxID {
+		err = fmt.Errorf("交易ID不一致: %s", txID)
+	}
+	if res.add("envelope", err) {
+		res.add("record", matchRecord(r, args))
+	}
+
+	block, err := bc.QueryBlockByTxID(r.TxID)
+	if err == nil && block.GetHeader().GetNumber() != r.BlockNumber {
+		err = fmt.Errorf("区块号不一致: %d", block.GetHeader().GetNumber())
+	}
+	res.add("block", err)
+
+	res.Valid = true
+	for _, check := range res.Checks {
+		res.Valid = res.Valid && check.Passed
+	}
+	return res
+}
+
+// matchRecord 核对 createRecord(s) 调用参数与回执内容
+func matchRecord(r Receipt, args [][]byte) error {
+	if len(args) == 2 && string(args[0]) == "createRecords" {
+		return matchBatchRecord(r, args[1])
+	}
+	if len(args) < 5 || string(args[0]) != "createRecord" {
+		return fmt.Errorf("交易不是 createRecord 调用")
+	}
+
+	var files []model.DatasetFile
+	if err := json.Unmarshal(args[4], &files); err != nil {
+		return fmt.Errorf("解析文件列表出错: %s", err)
+	}
+	return matchFields(r, model.Record{
+		DatasetOwner: string(args[1]),
+		DatasetName:  string(args[2]),
+		User:         string(args[3]),
+		Files:        files,
+	})
+}
+
+// matchBatchRecord 按记录ID中的序号在 createRecords 调用中找到对应记录
+func matchBatchRecord(r Receipt, arg []byte) error {
+	index, err := strconv.Atoi(strings.TrimPrefix(r.RecordID, r.TxID+"-"))
+	if err != nil || !strings.HasPrefix(r.RecordID, r.TxID+"-") {
+		return fmt.Errorf("记录ID与交易不符: %s", r.RecordID)
+	}
+	var records []model.Record
+	if err := json.Unmarshal(arg, &records); err != nil {
+		return fmt.Errorf("解析记录列表出错: %s", err)
+	}
+	if index < 0 || index >= len(records) {
+		return fmt.Errorf("记录序号超出范围: %d", index)
+	}
+	return matchFields(r, records[index])
+}
+
+// matchFields 比较回执与记录的数据集、下载者和文件列表
+func matchFields(r Receipt, record model.Record) error {
+	if record.DatasetOwner != r.DatasetOwner || record.DatasetName != r.DatasetName {
+		return fmt.Errorf("数据集不一致: %s/%s", record.DatasetOwner, record.DatasetName)
+	}
+	if record.User != r.User {
+		return fmt.Errorf("下载者不一致: %s", record.User)
+	}
+
+	files := record.Files
+	if len(files) != len(r.Files) {
+		return fmt.Errorf("文件数量不一致: %d", len(files))
+	}
+	for i := range files {
+		if files[i].Hash != r.Files[i].Hash || files[i].FileName != r.Files[i].FileName {
+			return fmt.Errorf("文件不一致: %s %s", files[i].Hash, files[i].FileName)
+		}
+	}
+	return nil
+}
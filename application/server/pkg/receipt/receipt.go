@@ -0,0 +1,146 @@
+package receipt
+
+import (
+	"application/model"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// IDHeader 回执ID所在的响应头，下载记录上链后可凭此ID获取回执
+const IDHeader = "X-Download-Receipt-ID"
+
+// Receipt 下载回执，记录 createRecord(s) 交易与下载的文件
+type Receipt struct {
+	TxID         string              `json:"tx_id"`               // createRecord(s) 交易ID
+	RecordID     string              `json:"record_id,omitempty"` // 链上记录ID，批量写入时为 "交易ID-序号"
+	BlockNumber  uint64              `json:"block_number"`        // 交易所在区块
+	DatasetOwner string              `json:"dataset_owner"`       // 数据集所有者
+	DatasetName  string              `json:"dataset_name"`        // 数据集名
+	User         string              `json:"user"`                // 下载者ID
+	Files        []model.DatasetFile `json:"files"`               // 文件列表 (含哈希)
+	IssuedAt     string              `json:"issued_at"`           // 签发时间
+	Signature    string              `json:"signature"`           // 服务端 Ed25519 签名 (base64)
+}
+
+var privateKey ed25519.PrivateKey
+
+// Init 加载签名私钥，文件不存在时生成新的密钥
+func Init(keyFile string) error {
+	data, err := os.ReadFile(keyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return generateKey(keyFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("私钥文件格式错误: %s", keyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return fmt.Errorf("私钥不是 Ed25519 密钥: %s", keyFile)
+	}
+	privateKey = edKey
+	return nil
+}
+
+func generateKey(keyFile string) error {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), os.ModePerm); err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(keyFile, data, 0600); err != nil {
+		return err
+	}
+	privateKey = key
+	return nil
+}
+
+// PublicKey 返回签名公钥 (base64)，用于离线验证回执签名
+func PublicKey() string {
+	if privateKey == nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+}
+
+// signedBytes 返回参与签名的内容 (不含签名字段)
+func (r Receipt) signedBytes() ([]byte, error) {
+	r.Signature = ""
+	return json.Marshal(r)
+}
+
+// Sign 使用服务端私钥签名回执
+func (r *Receipt) Sign() error {
+	if privateKey == nil {
+		return errors.New("回执签名私钥未初始化")
+	}
+	data, err := r.signedBytes()
+	if err != nil {
+		return err
+	}
+	r.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data))
+	return nil
+}
+
+// VerifySignature 使用公钥 (base64) 验证回执签名
+func (r Receipt) VerifySignature(publicKey string) error {
+	pub, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("公钥格式错误")
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return errors.New("签名格式错误")
+	}
+	data, err := r.signedBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, data, sig) {
+		return errors.New("签名无效")
+	}
+	return nil
+}
+
+// Encode 将回执编码为 base64 字符串
+func (r Receipt) Encode() (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decode 解析 base64 编码的回执
+func Decode(value string) (Receipt, error) {
+	var r Receipt
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
@@ -0,0 +1,152 @@
+package receipt
+
+import (
+	"application/model"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// initKey 在临时目录中生成签名私钥
+func initKey(t *testing.T) string {
+	keyFile := filepath.Join(t.TempDir(), "keys", "receipt.pem")
+	if err := Init(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	return keyFile
+}
+
+func testReceipt() Receipt {
+	return Receipt{
+		TxID:         "tx1",
+		RecordID:     "tx1-0",
+		BlockNumber:  7,
+		DatasetOwner: "alice",
+		DatasetName:  "iris",
+		User:         "bob",
+		Files: []model.DatasetFile{
+			{Hash: "5feceb66ffc86f38d952786c6d696c79c2dbc239dd4e91b46729d73a27fb57e9", FileName: "iris.csv"},
+		},
+		IssuedAt: "2021-01-01T00:00:00Z",
+	}
+}
+
+func TestInit(t *testing.T) {
+	keyFile := initKey(t)
+	generated := PublicKey()
+	if generated == "" {
+		t.Fatal("PublicKey() is empty after Init")
+	}
+	if info, err := os.Stat(keyFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("key file not written with 0600: %v %v", info, err)
+	}
+
+	// 再次加载得到同一密钥
+	if err := Init(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if PublicKey() != generated {
+		t.Fatal("Init did not load the existing key")
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	os.WriteFile(invalid, []byte("not a key"), 0600)
+	if err := Init(invalid); err == nil {
+		t.Fatal("Init accepted an invalid key file")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	initKey(t)
+	r := testReceipt()
+	if err := r.Sign(); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := r.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.TxID != r.TxID || decoded.Signature != r.Signature || len(decoded.Files) != 1 ||
+		decoded.Files[0].Hash != r.Files[0].Hash {
+		t.Fatalf("Decode(Encode()) = %+v, want %+v", decoded, r)
+	}
+	if err := decoded.VerifySignature(PublicKey()); err != nil {
+		t.Fatalf("VerifySignature() = %v", err)
+	}
+
+	if _, err := Decode("not base64!"); err == nil {
+		t.Fatal("Decode accepted invalid base64")
+	}
+	if _, err := Decode(base64.StdEncoding.EncodeToString([]byte("{"))); err == nil {
+		t.Fatal("Decode accepted invalid JSON")
+	}
+}
+
+func TestVerifySignatureTampered(t *testing.T) {
+	initKey(t)
+	signed := testReceipt()
+	if err := signed.Sign(); err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name   string
+		tamper func(*Receipt)
+		key    string
+	}{
+		{name: "tx id", tamper: func(r *Receipt) { r.TxID = "tx2" }},
+		{name: "record id", tamper: func(r *Receipt) { r.RecordID = "tx1-1" }},
+		{name: "block number", tamper: func(r *Receipt) { r.BlockNumber++ }},
+		{name: "dataset owner", tamper: func(r *Receipt) { r.DatasetOwner = "mallory" }},
+		{name: "dataset name", tamper: func(r *Receipt) { r.DatasetName = "iris2" }},
+		{name: "user", tamper: func(r *Receipt) { r.User = "mallory" }},
+		{name: "file hash", tamper: func(r *Receipt) {
+			r.Files = []model.DatasetFile{{Hash: "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b", FileName: "iris.csv"}}
+		}},
+		{name: "file name", tamper: func(r *Receipt) {
+			r.Files = []model.DatasetFile{{Hash: r.Files[0].Hash, FileName: "other.csv"}}
+		}},
+		{name: "extra file", tamper: func(r *Receipt) { r.Files = append(r.Files, r.Files[0]) }},
+		{name: "issued at", tamper: func(r *Receipt) { r.IssuedAt = "2021-01-02T00:00:00Z" }},
+		{name: "signature", tamper: func(r *Receipt) { r.Signature = base64.StdEncoding.EncodeToString(make([]byte, 64)) }},
+		{name: "malformed signature", tamper: func(r *Receipt) { r.Signature = "!" }},
+		{name: "other key", tamper: func(r *Receipt) {}, key: base64.StdEncoding.EncodeToString(otherKey)},
+		{name: "malformed key", tamper: func(r *Receipt) {}, key: "AAAA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signed
+			r.Files = append([]model.DatasetFile(nil), signed.Files...)
+			tt.tamper(&r)
+			key := tt.key
+			if key == "" {
+				key = PublicKey()
+			}
+			if err := r.VerifySignature(key); err == nil {
+				t.Fatalf("VerifySignature(%+v) = nil, want error", r)
+			}
+		})
+	}
+}
+
+func TestSignWithoutKey(t *testing.T) {
+	saved := privateKey
+	defer func() { privateKey = saved }()
+	privateKey = nil
+
+	r := testReceipt()
+	if err := r.Sign(); err == nil {
+		t.Fatal("Sign() without a key = nil, want error")
+	}
+	if PublicKey() != "" {
+		t.Fatal("PublicKey() without a key is not empty")
+	}
+}
@@ -0,0 +1,128 @@
+package recommend
+
+import (
+	"application/model"
+	"application/sql"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// HalfLife 下载与新版本的热度每过半衰期减半
+	HalfLife = 7 * 24 * time.Hour
+	// Window 参与计算热度的时间范围
+	Window = 30 * 24 * time.Hour
+	// VersionWeight 发布一个新版本相当于的下载次数
+	VersionWeight = 10
+)
+
+// Scored 带分数的数据集
+type Scored struct {
+	Owner string  `json:"owner"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+func key(owner, name string) string {
+	return owner + "/" + name
+}
+
+// decay 按距今时间衰减的权重
+func decay(at time.Time, now time.Time) float64 {
+	age := now.Sub(at)
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp2(-float64(age) / float64(HalfLife))
+}
+
+// sortScored 按分数降序排序，分数相同时按名称排序，保留前 limit 个
+func sortScored(items []Scored, limit int) []Scored {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return key(items[i].Owner, items[i].Name) < key(items[j].Owner, items[j].Name)
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// Trending 计算数据集热度: 时间窗口内的下载与新版本按发生时间衰减后累加
+// events 为窗口内的下载事件，已删除的数据集不参与排序
+func Trending(datasets []model.Dataset, events []sql.DownloadEvent, now time.Time, limit int) []Scored {
+	since := now.Add(-Window)
+	scores := map[string]*Scored{}
+	for _, dataset := range datasets {
+		if dataset.Deleted {
+			continue
+		}
+		s := &Scored{Owner: dataset.Owner, Name: dataset.Name}
+		for _, version := range dataset.Versions {
+			created, err := time.Parse(time.RFC3339, version.CreationTime)
+			if err != nil || created.Before(since) {
+				continue
+			}
+			s.Score += VersionWeight * decay(created, now)
+		}
+		scores[key(dataset.Owner, dataset.Name)] = s
+	}
+	for _, event := range events {
+		if s := scores[key(event.DatasetOwner, event.DatasetName)]; s != nil && !event.CreatedAt.Before(since) {
+			s.Score += decay(event.CreatedAt, now)
+		}
+	}
+
+	items := make([]Scored, 0, len(scores))
+	for _, s := range scores {
+		if s.Score > 0 {
+			items = append(items, *s)
+		}
+	}
+	return sortScored(items, limit)
+}
+
+// features 元数据中的任务与标签
+func features(table sql.MetadataTable) map[string]bool {
+	set := map[string]bool{}
+	for prefix, value := range map[string]string{"task:": table.Tasks, "tag:": table.Tags} {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+				set[prefix+item] = true
+			}
+		}
+	}
+	return set
+}
+
+// Similar 按任务与标签的 Jaccard 相似度查找相关数据集
+func Similar(target sql.MetadataTable, tables []sql.MetadataTable, limit int) []Scored {
+	want := features(target)
+	if len(want) == 0 {
+		return []Scored{}
+	}
+
+	items := []Scored{}
+	for _, table := range tables {
+		if table.Owner == target.Owner && table.Name == target.Name {
+			continue
+		}
+		have := features(table)
+		shared := 0
+		for f := range have {
+			if want[f] {
+				shared++
+			}
+		}
+		if shared == 0 {
+			continue
+		}
+		union := len(want) + len(have) - shared
+		items = append(items, Scored{Owner: table.Owner, Name: table.Name, Score: float64(shared) / float64(union)})
+	}
+	return sortScored(items, limit)
+}
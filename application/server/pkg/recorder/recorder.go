@@ -0,0 +1,238 @@
+package recorder
+
+import (
+	bc "application/blockchain"
+	"application/conf"
+	"application/model"
+	"application/pkg/utils"
+	"application/sql"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// MaxAttempts 单条记录被链码拒绝的次数上限，超过后标记为失败
+const MaxAttempts = 5
+
+// MaxBackoff 链不可用时两次写入之间的最长间隔
+const MaxBackoff = 5 * time.Minute
+
+// errRejected 记录被链码拒绝或无法解析，计入失败次数
+var errRejected = errors.New("记录被拒绝")
+
+// 链不可用或数据库出错时暂停写入，每次失败间隔加倍，写入成功后恢复
+var (
+	backoff time.Duration
+	retryAt time.Time
+)
+
+// pending 自上次写入后新增的事件数，达到批量大小时提前写入
+var pending int32
+
+var wake = make(chan struct{}, 1)
+
+// Check 模拟执行 createRecords，确认链码会接受该记录 (访问权限、许可证、文件)
+// 只需背书，不等待出块
+func Check(record model.Record) error {
+	_, err := bc.ChannelQuery("createRecords", [][]byte{[]byte(utils.ToJson([]model.Record{record}))})
+	return err
+}
+
+// NewID 生成下载事件ID，即回执ID
+func NewID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// Submit 保存下载事件，由后台批量写入链上，返回回执ID
+func Submit(record model.Record) (string, error) {
+	id, err := NewID()
+	if err != nil {
+		return "", err
+	}
+	return id, SubmitID(id, record)
+}
+
+// SubmitID 以预先生成的回执ID保存下载事件
+// 用于先在响应头中返回回执ID、传输完成后再记录的下载
+func SubmitID(id string, record model.Record) error {
+	files, err := json.Marshal(record.Files)
+	if err != nil {
+		return err
+	}
+	event := sql.DownloadEvent{
+		ID:           id,
+		DatasetOwner: record.DatasetOwner,
+		DatasetName:  record.DatasetName,
+		User:         record.User,
+		Files:        string(files),
+		Status:       sql.EventPending,
+	}
+	if err := sql.CreateDownloadEvent(&event); err != nil {
+		return err
+	}
+
+	if int(atomic.AddInt32(&pending, 1)) >= conf.Conf.RecordConfig.BatchSize {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run 定时将下载事件批量写入链上，服务重启后继续写入未完成的事件
+func Run() {
+	ticker := time.NewTicker(time.Duration(conf.Conf.RecordConfig.FlushInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+		Flush()
+	}
+}
+
+// Flush 写入全部等待中的事件
+func Flush() {
+	if time.Now().Before(retryAt) {
+		return
+	}
+	atomic.StoreInt32(&pending, 0)
+	for {
+		events, err := sql.PendingDownloadEvents(conf.Conf.RecordConfig.BatchSize)
+		if err != nil {
+			log.Printf("查询下载事件出错 %s", err)
+			return
+		}
+		if len(events) == 0 {
+			backoff = 0
+			return
+		}
+		err = commit(events)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, errRejected) {
+			delay(err)
+			return
+		}
+		// 整批被拒绝时逐条写入，避免单条无效记录阻塞其他记录
+		progressed := false
+		for i := range events {
+			err := commit(events[i : i+1])
+			if err == nil {
+				progressed = true
+			} else if !errors.Is(err, errRejected) {
+				delay(err)
+				return
+			}
+		}
+		if !progressed {
+			// 全部被拒绝，失败次数已记录，等待下次重试
+			return
+		}
+	}
+}
+
+// delay 链不可用或数据库出错时推迟下次写入，事件保持等待状态且不计入失败次数
+func delay(err error) {
+	interval := time.Duration(conf.Conf.RecordConfig.FlushInterval) * time.Second
+	backoff *= 2
+	if backoff < interval {
+		backoff = interval
+	}
+	if backoff > MaxBackoff {
+		backoff = MaxBackoff
+	}
+	retryAt = time.Now().Add(backoff)
+	log.Printf("写入下载记录失败，%s 后重试: %s", backoff, err)
+}
+
+// Record 将下载事件转换为链上记录
+func Record(event sql.DownloadEvent) (model.Record, error) {
+	record := model.Record{
+		ID:           event.RecordID,
+		DatasetOwner: event.DatasetOwner,
+		DatasetName:  event.DatasetName,
+		User:         event.User,
+		EventID:      event.ID,
+	}
+	if err := json.Unmarshal([]byte(event.Files), &record.Files); err != nil {
+		return record, fmt.Errorf("解析文件列表出错: %s", err)
+	}
+	return record, nil
+}
+
+// commit 以一笔 createRecords 交易写入事件
+// 记录携带事件ID，链码不会重复写入已上链的事件，因此提交后保存失败时可以安全地重新提交
+func commit(events []sql.DownloadEvent) error {
+	records := make([]model.Record, 0, len(events))
+	for _, event := range events {
+		record, err := Record(event)
+		if err != nil {
+			return reject(events, err)
+		}
+		records = append(records, record)
+	}
+
+	resp, err := bc.ChannelExecute("createRecords", [][]byte{[]byte(utils.ToJson(records))})
+	if err != nil {
+		if bc.Rejected(err) {
+			return reject(events, err)
+		}
+		return err
+	}
+	var ids []string
+	if err := json.Unmarshal(resp.Payload, &ids); err != nil || len(ids) != len(events) {
+		// 交易已提交，按链码的编号规则推算记录ID
+		ids = make([]string, len(events))
+		for i := range ids {
+			ids[i] = fmt.Sprintf("%s-%d", resp.TransactionID, i)
+		}
+	}
+
+	for i := range events {
+		events[i].Status = sql.EventCommitted
+		events[i].Error = ""
+		// 已上链的事件返回此前交易中的记录ID ("交易ID-序号")
+		events[i].TxID = string(resp.TransactionID)
+		if n := strings.LastIndex(ids[i], "-"); n > 0 {
+			events[i].TxID = ids[i][:n]
+		}
+		events[i].RecordID = ids[i]
+	}
+	if err := sql.CommitDownloadEvents(events); err != nil {
+		return fmt.Errorf("保存下载事件出错 %s: %s", resp.TransactionID, err)
+	}
+	return nil
+}
+
+// reject 记录被拒绝的事件，单条事件被拒绝次数过多时不再重试
+func reject(events []sql.DownloadEvent, err error) error {
+	err = fmt.Errorf("%w: %s", errRejected, err)
+	if len(events) > 1 {
+		return err
+	}
+	event := events[0]
+	event.Attempts++
+	event.Error = err.Error()
+	if event.Attempts >= MaxAttempts {
+		event.Status = sql.EventFailed
+		log.Printf("下载事件写入失败 %s: %s", event.ID, err)
+	}
+	if err := sql.SaveDownloadEvent(&event); err != nil {
+		log.Printf("保存下载事件出错 %s: %s", event.ID, err)
+	}
+	return err
+}
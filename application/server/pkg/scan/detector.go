@@ -0,0 +1,233 @@
+package scan
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 严重级别
+const (
+	SeverityLow    = "low"    // 可能是个人信息，如邮箱
+	SeverityMedium = "medium" // 个人联系方式，如电话号码
+	SeverityHigh   = "high"   // 身份证件、银行卡号与密钥
+)
+
+// severityRank 严重级别的顺序，未知级别为 0
+var severityRank = map[string]int{
+	SeverityLow:    1,
+	SeverityMedium: 2,
+	SeverityHigh:   3,
+}
+
+// AtLeast 检查 severity 是否不低于 min
+func AtLeast(severity, min string) bool {
+	return severityRank[severity] > 0 && severityRank[severity] >= severityRank[min]
+}
+
+// ValidSeverity 检查是否为已知的严重级别
+func ValidSeverity(severity string) bool {
+	return severityRank[severity] > 0
+}
+
+// Detector 检测文本中的敏感信息
+type Detector interface {
+	Name() string     // 检测器名称，保存在检测结果中
+	Severity() string // 严重级别
+	// Find 返回一行文本中检测到的敏感信息
+	Find(line string) []string
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []Detector
+)
+
+// Register 注册检测器，之后的扫描都会使用该检测器
+// 新增或修改检测器后应递增 Version，使已扫描的文件重新扫描
+func Register(d Detector) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors = append(detectors, d)
+}
+
+// Detectors 返回已注册的检测器
+func Detectors() []Detector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	return append([]Detector(nil), detectors...)
+}
+
+// regexDetector 按正则表达式匹配，可选地对匹配结果做进一步校验
+type regexDetector struct {
+	name     string
+	severity string
+	pattern  *regexp.Regexp
+	hint     string            // 行中必须包含的子串，用于跳过不可能匹配的行，为空时不检查
+	valid    func(string) bool // 为 nil 时不校验
+}
+
+func (d *regexDetector) Name() string     { return d.name }
+func (d *regexDetector) Severity() string { return d.severity }
+
+func (d *regexDetector) Find(line string) []string {
+	if d.hint != "" && !strings.Contains(line, d.hint) {
+		return nil
+	}
+	var found []string
+	for _, match := range d.pattern.FindAllString(line, -1) {
+		if d.valid == nil || d.valid(match) {
+			found = append(found, match)
+		}
+	}
+	return found
+}
+
+// digits 去掉分隔符，只保留数字
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// luhn 银行卡号校验
+func luhn(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// cardIssuer 发卡机构的卡号前缀范围 (含两端) 与允许的卡号长度
+type cardIssuer struct {
+	low, high string
+	lengths   []int
+}
+
+// cardIssuers 常见发卡机构，前缀按位数比较
+var cardIssuers = []cardIssuer{
+	{"4", "4", []int{13, 16, 19}},             // Visa
+	{"51", "55", []int{16}},                   // Mastercard
+	{"2221", "2720", []int{16}},               // Mastercard
+	{"34", "34", []int{15}},                   // American Express
+	{"37", "37", []int{15}},                   // American Express
+	{"300", "305", []int{14, 16, 17, 18, 19}}, // Diners Club
+	{"36", "36", []int{14, 16, 17, 18, 19}},   // Diners Club
+	{"38", "39", []int{16, 17, 18, 19}},       // Diners Club
+	{"3528", "3589", []int{16, 17, 18, 19}},   // JCB
+	{"6011", "6011", []int{16, 17, 18, 19}},   // Discover
+	{"644", "649", []int{16, 17, 18, 19}},     // Discover
+	{"65", "65", []int{16, 17, 18, 19}},       // Discover
+	{"62", "62", []int{16, 17, 18, 19}},       // 银联
+}
+
+// cardGroups 检查分隔符: 不含分隔符，或使用同一种分隔符按 4 位分组 (最后一组 1-4 位)
+// 或按 American Express / Diners Club 的 4-6-5 与 4-6-4 分组
+func cardGroups(match string) bool {
+	sep := strings.IndexAny(match, " -")
+	if sep < 0 {
+		return true
+	}
+	groups := strings.Split(match, match[sep:sep+1])
+	lengths := make([]int, len(groups))
+	for i, group := range groups {
+		if group == "" || strings.ContainsAny(group, " -") {
+			return false
+		}
+		lengths[i] = len(group)
+	}
+	if len(lengths) == 3 && lengths[0] == 4 && lengths[1] == 6 && (lengths[2] == 5 || lengths[2] == 4) {
+		return true
+	}
+	for i, n := range lengths {
+		if n != 4 && (i < len(lengths)-1 || n > 4) {
+			return false
+		}
+	}
+	return true
+}
+
+// validCard 校验银行卡号的分组、发卡机构前缀与长度以及 Luhn 校验位
+// 仅凭长度与校验位时任意 13-19 位数字串约有十分之一会被误判为卡号
+func validCard(match string) bool {
+	if !cardGroups(match) {
+		return false
+	}
+	number := digits(match)
+	issued := false
+	for _, issuer := range cardIssuers {
+		prefix := number[:len(issuer.low)]
+		if prefix < issuer.low || prefix > issuer.high {
+			continue
+		}
+		for _, n := range issuer.lengths {
+			issued = issued || len(number) == n
+		}
+	}
+	return issued && luhn(number)
+}
+
+// validResidentID 校验中国居民身份证号的出生日期与校验位 (GB 11643)
+func validResidentID(id string) bool {
+	month := id[10:12]
+	day := id[12:14]
+	if month < "01" || month > "12" || day < "01" || day > "31" {
+		return false
+	}
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(id[i]-'0') * w
+	}
+	return strings.ToUpper(id[17:]) == string("10X98765432"[sum%11])
+}
+
+// validSSN 排除不会分配的美国社会安全号码
+func validSSN(ssn string) bool {
+	area, group, serial := ssn[0:3], ssn[4:6], ssn[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func init() {
+	for _, d := range []*regexDetector{
+		{name: "email", severity: SeverityLow, hint: "@",
+			pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+		{name: "phone-cn", severity: SeverityMedium,
+			pattern: regexp.MustCompile(`(?:\+86[- ]?1[3-9]\d{9}|\b1[3-9]\d{9})\b`)},
+		{name: "phone-us", severity: SeverityMedium,
+			pattern: regexp.MustCompile(`(?:\+1[- ]?)?(?:\(\d{3}\) ?|\b\d{3}[-.])\d{3}[-.]\d{4}\b`)},
+		{name: "resident-id-cn", severity: SeverityHigh,
+			pattern: regexp.MustCompile(`\b[1-9]\d{5}(?:19|20)\d{9}[\dXx]\b`), valid: validResidentID},
+		{name: "ssn-us", severity: SeverityHigh,
+			pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), valid: validSSN},
+		{name: "credit-card", severity: SeverityHigh,
+			pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: validCard},
+		{name: "aws-access-key", severity: SeverityHigh,
+			pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{name: "github-token", severity: SeverityHigh, hint: "gh",
+			pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`)},
+		{name: "slack-token", severity: SeverityHigh, hint: "xox",
+			pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+		{name: "google-api-key", severity: SeverityHigh, hint: "AIza",
+			pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+		{name: "stripe-key", severity: SeverityHigh, hint: "_live_",
+			pattern: regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}`)},
+		{name: "private-key", severity: SeverityHigh, hint: "PRIVATE KEY",
+			pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+	} {
+		Register(d)
+	}
+}
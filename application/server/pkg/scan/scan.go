@@ -0,0 +1,278 @@
+package scan
+
+import (
+	"application/pkg/blob"
+	"application/sql"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// Version 扫描器版本，检测器变化后递增，已扫描的文件会在下次后台扫描时重新扫描
+	Version = 2
+	// MaxBytes 单个文件最多扫描的字节数，超出部分不扫描
+	MaxBytes = 256 << 20
+	// MaxSamples 每个检测器在单个文件中最多保存的样例数，超出的发现只计数
+	MaxSamples = 20
+
+	sniffBytes = 8 << 10  // 判断是否为文本文件时读取的字节数
+	lineBytes  = 64 << 10 // 单行最多读取的字节数，更长的行分段检测
+)
+
+// Finding 一条检测结果，样例已脱敏
+type Finding struct {
+	Detector string `json:"detector"`
+	Severity string `json:"severity"`
+	Line     int64  `json:"line"`
+	Sample   string `json:"sample"`
+}
+
+// Result 一个文件的扫描结果
+type Result struct {
+	Text      bool           `json:"text"`
+	Truncated bool           `json:"truncated"`
+	Lines     int64          `json:"lines"`
+	Counts    map[string]int `json:"counts"` // 各检测器的发现数，包括未保存样例的发现
+	Findings  []Finding      `json:"findings"`
+}
+
+// ScanReader 逐行扫描文本内容，二进制内容 (开头包含 NUL 字节) 不扫描
+// 超过 lineBytes 的行分段检测，跨段的匹配可能被遗漏
+func ScanReader(r io.Reader) (Result, error) {
+	result := Result{Counts: map[string]int{}}
+
+	reader := bufio.NewReaderSize(io.LimitReader(r, MaxBytes), lineBytes)
+	head, err := reader.Peek(sniffBytes)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return result, err
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return result, nil
+	}
+	result.Text = true
+
+	detectors := Detectors()
+	var line int64 = 1
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		if len(chunk) > 0 {
+			text := string(chunk)
+			for _, d := range detectors {
+				for _, match := range d.Find(text) {
+					result.Counts[d.Name()]++
+					if result.Counts[d.Name()] > MaxSamples {
+						continue
+					}
+					result.Findings = append(result.Findings, Finding{
+						Detector: d.Name(),
+						Severity: d.Severity(),
+						Line:     line,
+						Sample:   Redact(match),
+					})
+				}
+			}
+		}
+		if !isPrefix {
+			result.Lines = line
+			line++
+		}
+	}
+
+	// LimitReader 读完后检查原始内容是否还有剩余
+	var rest [1]byte
+	if n, _ := r.Read(rest[:]); n > 0 {
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// Redact 保留匹配内容首尾少量字符，其余替换为 *
+func Redact(match string) string {
+	runes := []rune(match)
+	keep := len(runes) / 4
+	if keep > 4 {
+		keep = 4
+	}
+	masked := make([]rune, len(runes))
+	for i, r := range runes {
+		if i < keep || i >= len(runes)-keep {
+			masked[i] = r
+		} else {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
+
+// ScanFile 扫描本地存储的文件，哈希格式错误时返回错误，避免读取存储目录以外的文件
+func ScanFile(hash string) (Result, error) {
+	if !blob.ValidHash(hash) {
+		return Result{}, fmt.Errorf("文件哈希格式错误: %s", hash)
+	}
+	f, err := os.Open(blob.Path(hash))
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+	return ScanReader(f)
+}
+
+var locks sync.Map // hash -> *sync.Mutex，避免同一文件被并发扫描
+
+// Ensure 返回文件的扫描结果，尚未用当前版本扫描时先扫描并保存
+func Ensure(hash string) (*sql.FileScan, error) {
+	if !blob.ValidHash(hash) {
+		return nil, fmt.Errorf("文件哈希格式错误: %s", hash)
+	}
+	lock, _ := locks.LoadOrStore(hash, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	scan, err := sql.GetFileScan(hash)
+	if err != nil {
+		return nil, err
+	}
+	if scan != nil && scan.Version >= Version {
+		return scan, nil
+	}
+
+	result, err := ScanFile(hash)
+	if err != nil {
+		return nil, fmt.Errorf("扫描文件出错: %s", err)
+	}
+	return save(hash, result)
+}
+
+func save(hash string, result Result) (*sql.FileScan, error) {
+	counts, err := json.Marshal(result.Counts)
+	if err != nil {
+		return nil, err
+	}
+	scan := &sql.FileScan{
+		Hash:      hash,
+		Version:   Version,
+		Text:      result.Text,
+		Truncated: result.Truncated,
+		Lines:     result.Lines,
+		Counts:    string(counts),
+		ScannedAt: time.Now(),
+	}
+
+	// 按检测器的严重级别统计全部发现，而不只是保存了样例的发现
+	severities := make(map[string]string)
+	for _, d := range Detectors() {
+		severities[d.Name()] = d.Severity()
+	}
+	for name, n := range result.Counts {
+		switch severities[name] {
+		case SeverityHigh:
+			scan.High += n
+		case SeverityMedium:
+			scan.Medium += n
+		case SeverityLow:
+			scan.Low += n
+		}
+	}
+
+	findings := make([]sql.ScanFinding, 0, len(result.Findings))
+	for _, finding := range result.Findings {
+		findings = append(findings, sql.ScanFinding{
+			Hash:     hash,
+			Detector: finding.Detector,
+			Severity: finding.Severity,
+			Line:     finding.Line,
+			Sample:   finding.Sample,
+		})
+	}
+	if err := sql.SaveFileScan(scan, findings); err != nil {
+		return nil, fmt.Errorf("保存扫描结果出错: %s", err)
+	}
+	return scan, nil
+}
+
+// Submit 在后台扫描刚上传的文件，失败时只记录日志，后台扫描会重试
+func Submit(hash string) {
+	go func() {
+		if _, err := Ensure(hash); err != nil {
+			log.Printf("扫描文件失败 %s: %s", hash, err)
+		}
+	}()
+}
+
+// Blocked 检查扫描结果中是否存在不低于 min 级别的发现
+func Blocked(scan *sql.FileScan, min string) bool {
+	if scan == nil || !ValidSeverity(min) {
+		return false
+	}
+	return (scan.High > 0 && AtLeast(SeverityHigh, min)) ||
+		(scan.Medium > 0 && AtLeast(SeverityMedium, min)) ||
+		(scan.Low > 0 && AtLeast(SeverityLow, min))
+}
+
+// ErrRunning 已有后台扫描正在进行
+var ErrRunning = errors.New("文件扫描正在进行")
+
+var running sync.Mutex
+
+// Run 扫描存储目录中尚未用当前版本扫描的文件，返回扫描的文件数
+func Run() (int, error) {
+	if !running.TryLock() {
+		return 0, ErrRunning
+	}
+	defer running.Unlock()
+	return run()
+}
+
+// Start 在后台开始扫描，结束后调用 done
+func Start(done func(int, error)) error {
+	if !running.TryLock() {
+		return ErrRunning
+	}
+	go func() {
+		defer running.Unlock()
+		done(run())
+	}()
+	return nil
+}
+
+func run() (int, error) {
+	scanned, err := sql.QueryScannedHashes(Version)
+	if err != nil {
+		return 0, fmt.Errorf("查询扫描记录出错: %s", err)
+	}
+
+	entries, err := os.ReadDir(blob.Dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("读取文件目录出错: %s", err)
+	}
+
+	count := 0
+	for _, entry := range entries {
+		hash := entry.Name()
+		if entry.IsDir() || !blob.ValidHash(hash) || scanned[hash] {
+			continue
+		}
+		// 单个文件失败不影响其他文件，下次扫描会重试
+		if _, err := Ensure(hash); err != nil {
+			log.Printf("扫描文件失败 %s: %s", hash, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
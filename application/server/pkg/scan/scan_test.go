@@ -0,0 +1,198 @@
+package scan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"378282246310005", true},
+		{"5555555555554444", true},
+		{"0", true},
+		{"18", true},
+		{"19", false},
+	}
+	for _, tt := range tests {
+		if got := luhn(tt.number); got != tt.want {
+			t.Errorf("luhn(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestValidCard(t *testing.T) {
+	tests := []struct {
+		name  string
+		match string
+		want  bool
+	}{
+		{"visa", "4111111111111111", true},
+		{"visa spaced", "4111 1111 1111 1111", true},
+		{"visa dashed", "4111-1111-1111-1111", true},
+		{"mastercard", "5555555555554444", true},
+		{"amex grouped", "3782 822463 10005", true},
+		{"diners grouped", "3622 720627 1667", true},
+		{"unionpay 19 digits", "6212345678901230005", true},
+		{"unionpay 19 digits grouped", "6212 3456 7890 1230 005", true},
+		{"luhn failure", "4111111111111112", false},
+		{"mixed separators", "4111-1111 1111 1111", false},
+		{"irregular groups", "41 1111111 1111111", false},
+		{"unknown issuer", "7000000000000005", false},
+		{"prefix 2 outside mastercard range", "2000000000000006", false},
+		{"visa wrong length", "40000000000000006", false},
+		{"amex wrong length", "3400000000000000", false},
+	}
+	for _, tt := range tests {
+		if got := validCard(tt.match); got != tt.want {
+			t.Errorf("%s: validCard(%q) = %v, want %v", tt.name, tt.match, got, tt.want)
+		}
+	}
+}
+
+func TestValidResidentID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"11010519491231002X", true},
+		{"11010519491231002x", true},
+		{"110105194912310021", false}, // 校验位错误
+		{"11010519491331002X", false}, // 月份错误
+		{"11010519491200002X", false}, // 日期错误
+	}
+	for _, tt := range tests {
+		if got := validResidentID(tt.id); got != tt.want {
+			t.Errorf("validResidentID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestValidSSN(t *testing.T) {
+	tests := []struct {
+		ssn  string
+		want bool
+	}{
+		{"123-45-6789", true},
+		{"000-45-6789", false},
+		{"666-45-6789", false},
+		{"912-45-6789", false},
+		{"123-00-6789", false},
+		{"123-45-0000", false},
+	}
+	for _, tt := range tests {
+		if got := validSSN(tt.ssn); got != tt.want {
+			t.Errorf("validSSN(%q) = %v, want %v", tt.ssn, got, tt.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		match string
+		want  string
+	}{
+		{"", ""},
+		{"abc", "***"},
+		{"abcdefgh", "ab****gh"},
+		{"4111111111111111", "4111********1111"},
+		{"user.name@example.com", "user*************.com"},
+		{"张三李四", "张**四"},
+	}
+	for _, tt := range tests {
+		if got := Redact(tt.match); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.match, got, tt.want)
+		}
+	}
+}
+
+func TestScanReader(t *testing.T) {
+	var manyEmails strings.Builder
+	for i := 0; i < MaxSamples+5; i++ {
+		manyEmails.WriteString("user@example.com\n")
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		text     bool
+		lines    int64
+		counts   map[string]int
+		findings int
+		line     int64 // 第一条发现所在的行
+	}{
+		{name: "empty", input: "", text: true, counts: map[string]int{}},
+		{name: "clean", input: "id,name\n1,alice\n", text: true, lines: 2, counts: map[string]int{}},
+		{name: "findings", input: "id,card\n1,4111 1111 1111 1111\n2,123-45-6789\n", text: true, lines: 3,
+			counts: map[string]int{"credit-card": 1, "ssn-us": 1}, findings: 2, line: 2},
+		{name: "order number", input: "order\n2000000000000006\n", text: true, lines: 2, counts: map[string]int{}},
+		{name: "no trailing newline", input: "a\nuser@example.com", text: true, lines: 2,
+			counts: map[string]int{"email": 1}, findings: 1, line: 2},
+		{name: "binary", input: "PK\x03\x04\x00\x00user@example.com\n", text: false, counts: map[string]int{}},
+		{name: "samples capped", input: manyEmails.String(), text: true, lines: MaxSamples + 5,
+			counts: map[string]int{"email": MaxSamples + 5}, findings: MaxSamples, line: 1},
+		// 超过 lineBytes 的行分段检测，分段内的发现仍计入同一行
+		{name: "long line", input: "x\n" + strings.Repeat("a", lineBytes+100) + " user@example.com\ny\n", text: true, lines: 3,
+			counts: map[string]int{"email": 1}, findings: 1, line: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ScanReader(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Text != tt.text || result.Lines != tt.lines || result.Truncated {
+				t.Fatalf("ScanReader() = text %v lines %d truncated %v, want text %v lines %d",
+					result.Text, result.Lines, result.Truncated, tt.text, tt.lines)
+			}
+			if len(result.Counts) != len(tt.counts) {
+				t.Fatalf("ScanReader() counts = %v, want %v", result.Counts, tt.counts)
+			}
+			for name, n := range tt.counts {
+				if result.Counts[name] != n {
+					t.Fatalf("ScanReader() counts = %v, want %v", result.Counts, tt.counts)
+				}
+			}
+			if len(result.Findings) != tt.findings {
+				t.Fatalf("ScanReader() findings = %+v, want %d", result.Findings, tt.findings)
+			}
+			if tt.findings > 0 && result.Findings[0].Line != tt.line {
+				t.Fatalf("ScanReader() first finding on line %d, want %d", result.Findings[0].Line, tt.line)
+			}
+			for _, finding := range result.Findings {
+				if strings.Contains(tt.input, finding.Sample) {
+					t.Fatalf("ScanReader() sample %q is not redacted", finding.Sample)
+				}
+			}
+		})
+	}
+}
+
+func TestScanReaderBinaryAfterSniff(t *testing.T) {
+	// 只检查开头是否包含 NUL 字节，之后的 NUL 字节不影响扫描
+	input := append([]byte("user@example.com\n"), bytes.Repeat([]byte{'a'}, sniffBytes)...)
+	input = append(input, 0, '\n')
+	result, err := ScanReader(bytes.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Text || result.Counts["email"] != 1 || result.Lines != 2 {
+		t.Fatalf("ScanReader() = %+v, want text with one email on 2 lines", result)
+	}
+}
+
+func TestInvalidHash(t *testing.T) {
+	for _, hash := range []string{"", "../../conf/app.ini", strings.Repeat("g", 64)} {
+		if _, err := ScanFile(hash); err == nil {
+			t.Errorf("ScanFile(%q) = nil error, want error", hash)
+		}
+		if _, err := Ensure(hash); err == nil {
+			t.Errorf("Ensure(%q) = nil error, want error", hash)
+		}
+	}
+}
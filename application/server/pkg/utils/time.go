@@ -2,7 +2,8 @@ package utils
 
 import "time"
 
-// Return the current time in ISO8601 format
+// Return the current time in RFC 3339 format (UTC)
+// 链码记录的时间以交易时间为准，此处的值仅为兼容旧版链码的参数
 func GetTimeString() string {
-	return time.Now(). /*.UTC()*/ Format("2006-01-02T15:04:05Z")
+	return time.Now().UTC().Format(time.RFC3339)
 }
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress 目标地址为本机、内网或链路本地地址
+var ErrForbiddenAddress = errors.New("不允许投递到本机、内网或链路本地地址")
+
+// sharedAddressSpace 运营商级 NAT 地址 (RFC 6598)，net.IP 没有对应的判断
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// AllowedIP 检查地址是否为可投递的公网地址
+func AllowedIP(ip net.IP) bool {
+	return ip != nil &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// CheckURL 检查 webhook 地址：只允许 http/https，且主机解析到的地址均为公网地址
+// 注册时检查以便及时提示，投递时由 NewClient 在连接前再次检查，防止 DNS 重绑定
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("无效的 URL: %s", raw)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("解析主机出错: %s", err)
+	}
+	for _, addr := range addrs {
+		if !AllowedIP(addr.IP) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr.IP)
+		}
+	}
+	return nil
+}
+
+// NewClient 返回投递用的 HTTP 客户端，每次建立连接前检查实际连接的地址
+// 不使用环境变量中的代理，否则检查的是代理地址而不是目标地址
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); !AllowedIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,351 @@
+package webhook
+
+import (
+	"application/conf"
+	"application/model"
+	"application/pkg/events"
+	"application/sql"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// 投递请求头
+const (
+	EventHeader     = "X-Genshin-Event"         // 链码事件名
+	DeliveryHeader  = "X-Genshin-Delivery"      // 投递记录ID，重新投递时不变
+	SignatureHeader = "X-Genshin-Signature-256" // 请求体的 HMAC-SHA256 签名
+)
+
+// Events 可订阅的链码事件
+var Events = []string{
+	model.EventDatasetCreated,
+	model.EventDatasetVersionAdded,
+	model.EventDatasetDeleted,
+	model.EventDatasetLicenseChanged,
+	model.EventRecordsCreated,
+}
+
+const (
+	// cursorName 事件处理进度在数据库中的名称
+	cursorName = "webhook"
+	// eventFilter 订阅的链码事件名
+	eventFilter = "^(dataset|records)-"
+	// pollInterval 检查到期重试的间隔
+	pollInterval = 5 * time.Second
+	// maxBackoff 重试间隔上限
+	maxBackoff = 6 * time.Hour
+	// batchSize 每次取出的到期投递数
+	batchSize = 100
+	// workers 同时投递的 webhook 数，同一 webhook 的投递按顺序进行
+	workers = 8
+)
+
+var wake = make(chan struct{}, 1)
+
+// Payload 投递的请求体
+type Payload struct {
+	Event string          `json:"event"` // 链码事件名
+	TxID  string          `json:"tx_id"` // 交易ID
+	Owner string          `json:"owner"` // 数据集所有者
+	Name  string          `json:"name"`  // 数据集名
+	Data  json.RawMessage `json:"data"`  // 数据集事件为 DatasetEvent，下载记录事件为 Record
+}
+
+// item 一个链码事件中需要分别投递的部分
+type item struct {
+	key     string // 去重键
+	payload Payload
+}
+
+// Sign 返回请求体的签名，格式为 "sha256=<hex>"
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名，供接收方使用
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send 投递一次，返回响应状态码，响应不是 2xx 时返回错误
+// 错误中不包含响应内容，投递记录对 webhook 所有者可见
+func Send(client *http.Client, url string, secret string, event string, deliveryID uint, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Genshin-Webhook")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(deliveryID), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 丢弃有限长度的响应以复用连接
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("响应状态 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff 第 attempts 次投递失败后的重试间隔，从 base 开始每次加倍
+func Backoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// Subscribed 检查 webhook 是否订阅了该事件
+func Subscribed(webhook sql.Webhook, event string) bool {
+	if webhook.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(webhook.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// split 将链码事件拆分为按数据集投递的部分，批量下载记录逐条投递
+func split(event *fab.CCEvent) ([]item, error) {
+	switch event.EventName {
+	case model.EventDatasetCreated, model.EventDatasetVersionAdded,
+		model.EventDatasetDeleted, model.EventDatasetLicenseChanged:
+		var data model.DatasetEvent
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return nil, fmt.Errorf("反序列化出错: %s", err)
+		}
+		return []item{{
+			key:     event.TxID,
+			payload: Payload{Event: event.EventName, TxID: event.TxID, Owner: data.Owner, Name: data.Name, Data: event.Payload},
+		}}, nil
+
+	case model.EventRecordsCreated:
+		var records []model.Record
+		if err := json.Unmarshal(event.Payload, &records); err != nil {
+			return nil, fmt.Errorf("反序列化出错: %s", err)
+		}
+		items := make([]item, 0, len(records))
+		for i, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return nil, fmt.Errorf("序列化出错: %s", err)
+			}
+			key := record.ID
+			if key == "" {
+				key = fmt.Sprintf("%s-%d", event.TxID, i)
+			}
+			items = append(items, item{
+				key:     key,
+				payload: Payload{Event: event.EventName, TxID: event.TxID, Owner: record.DatasetOwner, Name: record.DatasetName, Data: data},
+			})
+		}
+		return items, nil
+	}
+	return nil, nil
+}
+
+// Enqueue 为订阅了该链码事件的 webhook 创建投递记录
+func Enqueue(event *fab.CCEvent) error {
+	items, err := split(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []sql.WebhookDelivery
+	for _, it := range items {
+		webhooks, err := sql.QueryDatasetWebhooks(it.payload.Owner, it.payload.Name)
+		if err != nil {
+			return fmt.Errorf("查询 webhook 出错: %s", err)
+		}
+		if len(webhooks) == 0 {
+			continue
+		}
+		body, err := json.Marshal(it.payload)
+		if err != nil {
+			return fmt.Errorf("序列化出错: %s", err)
+		}
+		for _, webhook := range webhooks {
+			if !Subscribed(webhook, event.EventName) {
+				continue
+			}
+			deliveries = append(deliveries, sql.WebhookDelivery{
+				WebhookID:   webhook.ID,
+				EventKey:    it.key,
+				Event:       event.EventName,
+				Payload:     string(body),
+				Status:      sql.DeliveryPending,
+				NextAttempt: time.Now(),
+			})
+		}
+	}
+	if err := sql.CreateWebhookDeliveries(deliveries); err != nil {
+		return fmt.Errorf("创建投递记录出错: %s", err)
+	}
+	if len(deliveries) > 0 {
+		notify()
+	}
+	return nil
+}
+
+func notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run 订阅链码事件并投递 webhook，失败的投递按指数退避重试
+func Run() {
+	go events.Run(cursorName, eventFilter, Enqueue)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+		Flush()
+	}
+}
+
+// Flush 投递全部到期的记录
+// 按 webhook 分组并发投递，一个 webhook 投递失败时推迟其余投递，不影响其他 webhook
+func Flush() {
+	client := NewClient(time.Duration(conf.Conf.WebhookConfig.Timeout) * time.Second)
+	for {
+		deliveries, err := sql.DueWebhookDeliveries(time.Now(), batchSize)
+		if err != nil {
+			log.Printf("查询 webhook 投递记录出错: %s", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		var groups [][]sql.WebhookDelivery
+		index := make(map[uint]int)
+		for _, delivery := range deliveries {
+			i, ok := index[delivery.WebhookID]
+			if !ok {
+				i = len(groups)
+				index[delivery.WebhookID] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], delivery)
+		}
+
+		var (
+			wg     sync.WaitGroup
+			failed int32
+			sem    = make(chan struct{}, workers)
+		)
+		for _, group := range groups {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(group []sql.WebhookDelivery) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if err := deliverGroup(client, group); err != nil {
+					log.Printf("保存 webhook 投递记录出错: %s", err)
+					atomic.StoreInt32(&failed, 1)
+				}
+			}(group)
+		}
+		wg.Wait()
+		if failed != 0 {
+			return
+		}
+	}
+}
+
+// deliverGroup 按顺序投递同一 webhook 的记录，投递失败时其余记录推迟到同一重试时间
+func deliverGroup(client *http.Client, group []sql.WebhookDelivery) error {
+	for i := range group {
+		if err := deliver(client, &group[i]); err != nil {
+			return err
+		}
+		if group[i].Status != sql.DeliveryPending {
+			continue
+		}
+		for j := i + 1; j < len(group); j++ {
+			group[j].NextAttempt = group[i].NextAttempt
+			if err := sql.SaveWebhookDelivery(&group[j]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// deliver 投递一次并保存结果
+func deliver(client *http.Client, delivery *sql.WebhookDelivery) error {
+	webhook, err := sql.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+	if webhook == nil {
+		delivery.Status = sql.DeliveryFailed
+		delivery.Error = "webhook 已删除"
+		return sql.SaveWebhookDelivery(delivery)
+	}
+
+	code, err := Send(client, webhook.URL, webhook.Secret, delivery.Event, delivery.ID, []byte(delivery.Payload))
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	if err == nil {
+		delivery.Status = sql.DeliverySucceeded
+		delivery.Error = ""
+	} else if delivery.Attempts >= conf.Conf.WebhookConfig.MaxAttempts {
+		delivery.Status = sql.DeliveryFailed
+		delivery.Error = err.Error()
+	} else {
+		base := time.Duration(conf.Conf.WebhookConfig.RetryInterval) * time.Second
+		delivery.NextAttempt = time.Now().Add(Backoff(base, delivery.Attempts))
+		delivery.Error = err.Error()
+	}
+	return sql.SaveWebhookDelivery(delivery)
+}
+
+// Redeliver 重新投递，重置投递次数并立即投递
+func Redeliver(delivery *sql.WebhookDelivery) error {
+	delivery.Status = sql.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttempt = time.Now()
+	if err := sql.SaveWebhookDelivery(delivery); err != nil {
+		return err
+	}
+	notify()
+	return nil
+}
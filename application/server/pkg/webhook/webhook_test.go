@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"application/model"
+	"application/sql"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+func TestSend(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"event":"dataset-created"}`)
+
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	code, err := Send(receiver.Client(), receiver.URL+"/ok", secret, model.EventDatasetCreated, 42, body)
+	if err != nil || code != http.StatusOK {
+		t.Fatalf("expected success, got %d: %v", code, err)
+	}
+	if received.Method != http.MethodPost || string(receivedBody) != string(body) {
+		t.Fatalf("unexpected request: %s %s", received.Method, receivedBody)
+	}
+	if received.Header.Get(EventHeader) != model.EventDatasetCreated || received.Header.Get(DeliveryHeader) != "42" {
+		t.Fatalf("unexpected headers: %v", received.Header)
+	}
+	if !Verify(secret, receivedBody, received.Header.Get(SignatureHeader)) {
+		t.Fatalf("signature does not verify: %s", received.Header.Get(SignatureHeader))
+	}
+	if Verify("other", receivedBody, received.Header.Get(SignatureHeader)) {
+		t.Fatal("signature verifies with the wrong secret")
+	}
+
+	code, err = Send(receiver.Client(), receiver.URL+"/fail", secret, model.EventDatasetCreated, 43, body)
+	if err == nil || code != http.StatusServiceUnavailable {
+		t.Fatalf("expected failure with 503, got %d: %v", code, err)
+	}
+	if strings.Contains(err.Error(), "busy") {
+		t.Fatalf("error must not include the response body: %v", err)
+	}
+
+	receiver.Close()
+	if code, err = Send(receiver.Client(), receiver.URL, secret, model.EventDatasetCreated, 44, body); err == nil || code != 0 {
+		t.Fatalf("expected connection error, got %d: %v", code, err)
+	}
+}
+
+func TestAllowedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := AllowedIP(net.ParseIP(tt.ip)); got != tt.allowed {
+			t.Errorf("AllowedIP(%s) = %v, want %v", tt.ip, got, tt.allowed)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, raw := range []string{
+		"ftp://93.184.216.34/hook",
+		"http:///hook",
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://localhost/hook",
+	} {
+		if err := CheckURL(context.Background(), raw); err == nil {
+			t.Errorf("expected %s to be rejected", raw)
+		}
+	}
+	if err := CheckURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("expected public address to be accepted: %v", err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	// 注册后主机解析到内网地址 (DNS 重绑定) 时在连接前拒绝
+	_, err := Send(NewClient(time.Second), receiver.URL, "s3cret", model.EventDatasetCreated, 1, []byte("{}"))
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected loopback receiver to be refused, got %v", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n 'hello' | openssl dgst -sha256 -hmac key
+	const want = "sha256=9307b3b915efb5171ff14d8cb55fbcc798c6c0ef1456d66ded1a6aa723a58b7b"
+	if got := Sign("key", []byte("hello")); got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base := 30 * time.Second
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		20: maxBackoff,
+	} {
+		if got := Backoff(base, attempts); got != want {
+			t.Fatalf("Backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	all := sql.Webhook{}
+	some := sql.Webhook{Events: model.EventDatasetVersionAdded + "," + model.EventDatasetDeleted}
+	if !Subscribed(all, model.EventRecordsCreated) {
+		t.Fatal("webhook without events should receive all events")
+	}
+	if !Subscribed(some, model.EventDatasetDeleted) || Subscribed(some, model.EventRecordsCreated) {
+		t.Fatalf("unexpected subscription for %q", some.Events)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	datasetEvent, _ := json.Marshal(model.DatasetEvent{Owner: "user1", Name: "mnist", Version: 2})
+	items, err := split(&fab.CCEvent{TxID: "tx1", EventName: model.EventDatasetVersionAdded, Payload: datasetEvent})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one item, got %d: %v", len(items), err)
+	}
+	if items[0].key != "tx1" || items[0].payload.Owner != "user1" || items[0].payload.Name != "mnist" ||
+		string(items[0].payload.Data) != string(datasetEvent) {
+		t.Fatalf("unexpected item: %+v", items[0])
+	}
+
+	records, _ := json.Marshal([]model.Record{
+		{ID: "tx2-0", DatasetOwner: "user1", DatasetName: "mnist", User: "user2"},
+		{DatasetOwner: "org1", DatasetName: "cifar", User: "user2"},
+	})
+	items, err = split(&fab.CCEvent{TxID: "tx2", EventName: model.EventRecordsCreated, Payload: records})
+	if err != nil || len(items) != 2 {
+		t.Fatalf("expected two items, got %d: %v", len(items), err)
+	}
+	if items[0].key != "tx2-0" || items[1].key != "tx2-1" || items[1].payload.Owner != "org1" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	var record model.Record
+	if err := json.Unmarshal(items[1].payload.Data, &record); err != nil || record.DatasetName != "cifar" {
+		t.Fatalf("unexpected record data: %s", items[1].payload.Data)
+	}
+
+	if items, err := split(&fab.CCEvent{TxID: "tx3", EventName: "unknown", Payload: []byte("{}")}); err != nil || len(items) != 0 {
+		t.Fatalf("unknown events should be ignored, got %d: %v", len(items), err)
+	}
+	if _, err := split(&fab.CCEvent{TxID: "tx4", EventName: model.EventDatasetDeleted, Payload: []byte("x")}); err == nil {
+		t.Fatal("expected error for malformed payload")
+	}
+}
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"application/conf"
+	"application/pkg/app"
+	"crypto/subtle"
+
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTokenHeader 管理接口令牌所在的请求头
+const AdminTokenHeader = "X-Admin-Token"
+
+// adminAuth 校验管理接口令牌，未配置令牌时禁用全部管理接口
+func adminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := conf.Conf.AdminConfig.Token
+		got := c.GetHeader(AdminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			appG := app.Gin{C: c}
+			appG.Response(http.StatusForbidden, "失败", "管理接口令牌错误")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
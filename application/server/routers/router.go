@@ -2,6 +2,7 @@ package routers
 
 import (
 	v1 "application/api/v1"
+	"application/pkg/receipt"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,8 +16,8 @@ func InitRouter() *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "OPTIONS", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Content-Type", AdminTokenHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Digest", "Repr-Digest", receipt.IDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -31,23 +32,113 @@ func InitRouter() *gin.Engine {
 		apiV1.POST("/user/all", v1.QueryAllUsers)
 		apiV1.POST("/user/create", v1.CreateUser)
 		apiV1.POST("/user/login", v1.CheckUserLogin)
+		apiV1.POST("/user/usage", v1.QueryUsage)
+
+		// organization
+		apiV1.POST("/organization", v1.QueryOrganization)
+		apiV1.POST("/organization/all", v1.QueryAllOrganizations)
+		apiV1.POST("/organization/create", v1.CreateOrganization)
+		apiV1.POST("/organization/by/user", v1.QueryOrganizationsByUser)
+		apiV1.POST("/organization/member/add", v1.AddOrganizationMember)
+		apiV1.POST("/organization/member/remove", v1.RemoveOrganizationMember)
+		apiV1.POST("/organization/dataset/all", v1.QueryDatasetsByOrganization)
 
 		// dataset
 		apiV1.POST("/dataset/create", v1.CreateDataset)
 		apiV1.POST("/dataset/delete", v1.DeleteDataset)
 		apiV1.POST("/dataset/all", v1.QueryAllDatasets)
+		apiV1.POST("/dataset/by/user", v1.QueryDatasetsByUser)
 		apiV1.POST("/dataset/metadata", v1.QueryDatasetMetadata)
 		apiV1.POST("/dataset/version/create", v1.AddDatasetVersion)
 		apiV1.POST("/dataset/version/all", v1.QueryAllVersions)
+		apiV1.POST("/dataset/version/proof", v1.QueryFileProof)
+		apiV1.POST("/dataset/version/stats", v1.QueryVersionStats)
+		apiV1.POST("/dataset/version/browse", v1.BrowseVersion)
+		apiV1.GET("/dataset/card/:owner/:name", v1.QueryDatasetCard)
+		apiV1.POST("/dataset/readme", v1.QueryDatasetReadme)
+		apiV1.POST("/dataset/search", v1.SearchDatasets)
+		apiV1.POST("/dataset/trending", v1.QueryTrendingDatasets)
+		apiV1.POST("/dataset/related", v1.QueryRelatedDatasets)
+		apiV1.POST("/dataset/analytics", v1.QueryDatasetAnalytics)
+		apiV1.POST("/dataset/analytics/export", v1.ExportDatasetAnalytics)
+
+		// star & watch
+		apiV1.POST("/dataset/star", v1.StarDataset)
+		apiV1.POST("/dataset/unstar", v1.UnstarDataset)
+		apiV1.POST("/dataset/watch", v1.WatchDataset)
+		apiV1.POST("/dataset/unwatch", v1.UnwatchDataset)
+		apiV1.POST("/dataset/stars", v1.QueryDatasetStars)
+		apiV1.POST("/user/stars", v1.QueryUserStars)
+		apiV1.POST("/user/notifications", v1.QueryNotifications)
+		apiV1.POST("/user/notifications/read", v1.MarkNotificationsRead)
+
+		// comment
+		apiV1.POST("/dataset/comment/all", v1.QueryComments)
+		apiV1.POST("/dataset/comment/create", v1.CreateComment)
+		apiV1.POST("/dataset/comment/edit", v1.EditComment)
+		apiV1.POST("/dataset/comment/delete", v1.DeleteComment)
+		apiV1.POST("/dataset/comment/history", v1.QueryCommentHistory)
+		apiV1.POST("/dataset/comment/moderate", v1.ModerateComment)
+
+		// webhook
+		apiV1.POST("/webhook/create", v1.CreateWebhook)
+		apiV1.POST("/webhook/all", v1.QueryWebhooks)
+		apiV1.POST("/webhook/delete", v1.DeleteWebhook)
+		apiV1.POST("/webhook/deliveries", v1.QueryWebhookDeliveries)
+		apiV1.POST("/webhook/redeliver", v1.RedeliverWebhook)
+
+		// access
+		apiV1.POST("/dataset/gated", v1.SetDatasetGated)
+		apiV1.POST("/dataset/access/request", v1.CreateAccessRequest)
+		apiV1.POST("/dataset/access/approve", v1.ApproveAccessRequest)
+		apiV1.POST("/dataset/access/deny", v1.DenyAccessRequest)
+		apiV1.POST("/dataset/access/by/dataset", v1.QueryAccessRequestsByDataset)
+		apiV1.POST("/dataset/access/by/user", v1.QueryAccessRequestsByUser)
+
+		// license
+		apiV1.POST("/license/all", v1.QueryLicenses)
+		apiV1.POST("/dataset/license/set", v1.SetDatasetLicense)
+		apiV1.POST("/dataset/license/accept", v1.AcceptLicense)
+		apiV1.POST("/dataset/license/by/dataset", v1.QueryLicenseAcceptancesByDataset)
+		apiV1.POST("/dataset/license/by/user", v1.QueryLicenseAcceptancesByUser)
+
+		// attestation
+		apiV1.POST("/attester/all", v1.QueryAttesters)
+		apiV1.POST("/dataset/version/attest", v1.CreateAttestation)
+		apiV1.POST("/dataset/version/attestations", v1.QueryAttestations)
+		apiV1.POST("/dataset/version/badges", v1.QueryAttestationBadges)
 
 		// file
 		apiV1.POST("/file/upload", v1.UploadFile)
+		apiV1.POST("/file/by/uploader", v1.QueryFilesByUploader)
 		apiV1.POST("/file/download", v1.DownloadFile)
 		apiV1.POST("/file/download/zip", v1.DownloadFilesCompressed)
+		apiV1.POST("/file/download/split", v1.DownloadSplit)
+		apiV1.POST("/file/preview", v1.PreviewFile)
+		apiV1.POST("/file/scan", v1.QueryFileScan)
 
 		// record
 		apiV1.POST("/record/by/user", v1.QueryRecordsByUser)
 		apiV1.POST("/record/by/dataset", v1.QueryRecordsByDataset)
+
+		// receipt
+		apiV1.POST("/receipt/key", v1.QueryReceiptKey)
+		apiV1.POST("/receipt/verify", v1.VerifyReceipt)
+		apiV1.POST("/receipt", v1.QueryReceipt)
+	}
+
+	admin := apiV1.Group("/admin", adminAuth())
+	{
+		// fsck
+		admin.POST("/fsck/run", v1.RunFsck)
+		admin.POST("/fsck/runs", v1.QueryFsckRuns)
+		admin.POST("/fsck/issues", v1.QueryFsckIssues)
+		admin.POST("/scan/run", v1.RunScan)
+		admin.POST("/attester/grant", v1.GrantAttester)
+		admin.POST("/attester/revoke", v1.RevokeAttester)
+		admin.POST("/search/reindex", v1.ReindexReadmes)
+		admin.POST("/quota/set", v1.SetQuota)
+		admin.POST("/analytics/backfill", v1.BackfillAnalytics)
 	}
 	return r
 }
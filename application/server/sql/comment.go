@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment 数据集评论
+// 主题 (ParentID 为 0) 属于数据集或数据集的某个版本，回复继承主题的版本
+type Comment struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	DatasetOwner string    `gorm:"index:idx_comment_dataset" json:"dataset_owner"`
+	DatasetName  string    `gorm:"index:idx_comment_dataset" json:"dataset_name"`
+	Version      *int      `json:"version"`                // 版本序号，为 null 表示针对整个数据集
+	ThreadID     uint      `gorm:"index" json:"thread_id"` // 所属主题，主题的 ThreadID 为自身
+	ParentID     uint      `json:"parent_id"`              // 回复的评论，主题为 0
+	Author       string    `gorm:"index" json:"author"`
+	Content      string    `gorm:"type:text" json:"content"`
+	Edits        int       `json:"edits"`  // 编辑次数，编辑前的内容保存在 CommentRevision
+	Hidden       bool      `json:"hidden"` // 被数据集管理者隐藏
+	HiddenBy     string    `json:"hidden_by,omitempty"`
+	Locked       bool      `json:"locked"`  // 主题被锁定，不能再回复
+	Deleted      bool      `json:"deleted"` // 已删除，保留记录以维持主题结构
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CommentRevision 评论编辑前的内容
+type CommentRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CommentID uint      `gorm:"index" json:"comment_id"`
+	Content   string    `gorm:"type:text" json:"content"`
+	Editor    string    `json:"editor"`
+	CreatedAt time.Time `json:"created_at"` // 被替换的时间
+}
+
+func MigrateComment(db *gorm.DB) error {
+	return db.AutoMigrate(&Comment{}, &CommentRevision{})
+}
+
+// CreateComment 创建评论，主题的 ThreadID 设为自身ID
+func CreateComment(comment *Comment) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if comment.ThreadID != 0 {
+			return nil
+		}
+		comment.ThreadID = comment.ID
+		return tx.Model(comment).Update("thread_id", comment.ID).Error
+	})
+}
+
+// GetComment 查询评论，不存在时返回 nil
+func GetComment(id uint) (*Comment, error) {
+	var comment Comment
+	result := DB.Where("id = ?", id).First(&comment)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &comment, nil
+}
+
+func SaveComment(comment *Comment) error {
+	return DB.Save(comment).Error
+}
+
+// EditComment 保存编辑前的内容并更新评论
+func EditComment(comment *Comment, content string, editor string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		revision := CommentRevision{CommentID: comment.ID, Content: comment.Content, Editor: editor}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		comment.Content = content
+		comment.Edits++
+		return tx.Save(comment).Error
+	})
+}
+
+// QueryComments 按创建顺序查询数据集的评论，version 为 nil 时查询全部
+func QueryComments(owner, name string, version *int) ([]Comment, error) {
+	var comments []Comment
+	query := DB.Where("dataset_owner = ? AND dataset_name = ?", owner, name)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+	result := query.Order("id").Find(&comments)
+	return comments, result.Error
+}
+
+// QueryCommentRevisions 按时间顺序查询评论的编辑历史
+func QueryCommentRevisions(commentID uint) ([]CommentRevision, error) {
+	var revisions []CommentRevision
+	result := DB.Where("comment_id = ?", commentID).Order("id").Find(&revisions)
+	return revisions, result.Error
+}
@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FsckRun 一次文件完整性检查
+type FsckRun struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	StartTime  time.Time `json:"start_time"`
+	FinishTime time.Time `json:"finish_time"`
+	Checked    int       `json:"checked"`    // 检查的链上文件数
+	Issues     int       `json:"issues"`     // 发现的问题数
+	Quarantine bool      `json:"quarantine"` // 是否隔离损坏文件
+}
+
+// BlobIssue 检查发现的文件问题
+type BlobIssue struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	RunID       uint   `gorm:"index" json:"run_id"` // 为 0 表示下载时校验发现
+	Hash        string `gorm:"index" json:"hash"`
+	Kind        string `json:"kind"` // missing | corrupt | size_mismatch | orphaned
+	Detail      string `json:"detail"`
+	Quarantined bool   `json:"quarantined"`
+}
+
+func MigrateFsck(db *gorm.DB) error {
+	return db.AutoMigrate(&FsckRun{}, &BlobIssue{})
+}
+
+// CreateFsckRun 保存检查结果，返回检查ID
+func CreateFsckRun(run *FsckRun, issues []BlobIssue) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			return nil
+		}
+		for i := range issues {
+			issues[i].RunID = run.ID
+		}
+		return tx.Create(&issues).Error
+	})
+}
+
+// QueryFsckRuns 查询最近的检查记录
+func QueryFsckRuns(limit int) ([]FsckRun, error) {
+	var runs []FsckRun
+	result := DB.Order("id desc").Limit(limit).Find(&runs)
+	return runs, result.Error
+}
+
+// QueryBlobIssues 查询某次检查发现的问题，kind 为空时返回全部
+func QueryBlobIssues(runID uint, kind string) ([]BlobIssue, error) {
+	var issues []BlobIssue
+	query := DB.Where("run_id = ?", runID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	result := query.Order("id").Find(&issues)
+	return issues, result.Error
+}
+
+// CreateBlobIssue 保存检查之外发现的文件问题 (如下载校验失败)
+func CreateBlobIssue(issue *BlobIssue) error {
+	return DB.Create(issue).Error
+}
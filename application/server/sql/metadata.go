@@ -138,3 +138,11 @@ func MarkDeleted(owner string, name string) error {
 	}
 	return nil
 }
+
+func UpdateLicense(owner string, name string, license string) error {
+	result := DB.Model(&MetadataTable{}).Where("owner = ? AND name = ?", owner, name).Update("license", license)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -11,5 +11,50 @@ func Migrate() error {
 		return err
 	}
 
+	err = MigrateFsck(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateStats(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateSearch(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateQuota(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateRecord(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateWatch(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateWebhook(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateComment(DB)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateScan(DB)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"gorm.io/gorm"
+)
+
+// Quota 单独设置的存储配额，覆盖配置文件中的默认值
+type Quota struct {
+	Owner string `gorm:"primaryKey" json:"owner"` // 用户或组织ID
+	Bytes int64  `json:"bytes"`                   // 配额 (字节)，负数表示不限制
+}
+
+func MigrateQuota(db *gorm.DB) error {
+	return db.AutoMigrate(&Quota{})
+}
+
+// GetQuota 查询单独设置的配额，未设置时返回 nil
+func GetQuota(owner string) (*Quota, error) {
+	var quota Quota
+	result := DB.Where("owner = ?", owner).First(&quota)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &quota, nil
+}
+
+func SaveQuota(quota *Quota) error {
+	return DB.Save(quota).Error
+}
+
+func DeleteQuota(owner string) error {
+	return DB.Where("owner = ?", owner).Delete(&Quota{}).Error
+}
@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 下载事件状态
+const (
+	EventPending   = "pending"   // 等待写入链上
+	EventCommitted = "committed" // 已写入链上
+	EventFailed    = "failed"    // 多次写入失败，不再重试
+)
+
+// DownloadEvent 下载事件，先写入数据库，再由后台批量写入链上
+type DownloadEvent struct {
+	ID           string    `gorm:"primaryKey;size:32" json:"id"` // 回执ID
+	DatasetOwner string    `gorm:"index:idx_download_event_dataset" json:"dataset_owner"`
+	DatasetName  string    `gorm:"index:idx_download_event_dataset" json:"dataset_name"`
+	User         string    `gorm:"index" json:"user"`
+	Files        string    `gorm:"type:text" json:"files"` // []DatasetFile (JSON)
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+
+	Status   string `gorm:"index" json:"status"`
+	Attempts int    `json:"attempts"`               // 写入失败次数
+	Error    string `gorm:"type:text" json:"error"` // 最近一次写入失败的原因
+	TxID     string `json:"tx_id"`                  // createRecords 交易ID
+	RecordID string `json:"record_id"`              // 链上记录ID
+	Receipt  string `gorm:"type:text" json:"-"`     // 已签发的回执 (JSON)
+}
+
+func MigrateRecord(db *gorm.DB) error {
+	return db.AutoMigrate(&DownloadEvent{})
+}
+
+func CreateDownloadEvent(event *DownloadEvent) error {
+	return DB.Create(event).Error
+}
+
+// GetDownloadEvent 查询下载事件，不存在时返回 nil
+func GetDownloadEvent(id string) (*DownloadEvent, error) {
+	var event DownloadEvent
+	result := DB.Where("id = ?", id).First(&event)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &event, nil
+}
+
+// PendingDownloadEvents 按创建顺序查询等待写入链上的下载事件
+func PendingDownloadEvents(limit int) ([]DownloadEvent, error) {
+	var events []DownloadEvent
+	result := DB.Where("status = ?", EventPending).Order("created_at, id").Limit(limit).Find(&events)
+	return events, result.Error
+}
+
+func SaveDownloadEvent(event *DownloadEvent) error {
+	return DB.Save(event).Error
+}
+
+// CommitDownloadEvents 记录一批事件写入链上的交易
+func CommitDownloadEvents(events []DownloadEvent) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			if err := tx.Save(&events[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DownloadEventExists 判断链上记录是否已有对应的下载事件
+func DownloadEventExists(id string, recordID string) (bool, error) {
+	var count int64
+	query := DB.Model(&DownloadEvent{}).Where("id = ?", id)
+	if recordID != "" {
+		query = query.Or("record_id = ?", recordID)
+	}
+	result := query.Count(&count)
+	return count > 0, result.Error
+}
+
+// QueryDownloadEvents 查询数据集在 [from, to) 内的下载事件，不含上链失败的事件
+func QueryDownloadEvents(owner string, name string, from time.Time, to time.Time) ([]DownloadEvent, error) {
+	var events []DownloadEvent
+	result := DB.Where("dataset_owner = ? AND dataset_name = ? AND status <> ? AND created_at >= ? AND created_at < ?",
+		owner, name, EventFailed, from, to).Order("created_at").Find(&events)
+	return events, result.Error
+}
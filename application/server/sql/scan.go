@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FileScan 文件的敏感信息扫描结果
+type FileScan struct {
+	Hash      string    `gorm:"primaryKey;size:64" json:"hash"`
+	Version   int       `json:"version"`   // 扫描器版本，低于当前版本时重新扫描
+	Text      bool      `json:"text"`      // 是否为文本文件，二进制文件不扫描
+	Truncated bool      `json:"truncated"` // 文件过大，只扫描了开头部分
+	Lines     int64     `json:"lines"`     // 扫描的行数
+	High      int       `json:"high"`      // 各严重级别的发现数
+	Medium    int       `json:"medium"`
+	Low       int       `json:"low"`
+	Counts    string    `gorm:"type:text" json:"counts"` // 各检测器的发现数 map[string]int (JSON)
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// ScanFinding 扫描发现的敏感信息，只保存脱敏后的样例
+type ScanFinding struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Hash     string `gorm:"index;size:64" json:"hash"`
+	Detector string `json:"detector"`
+	Severity string `json:"severity"`
+	Line     int64  `json:"line"`   // 行号，从 1 开始
+	Sample   string `json:"sample"` // 脱敏后的匹配内容
+}
+
+func MigrateScan(db *gorm.DB) error {
+	return db.AutoMigrate(&FileScan{}, &ScanFinding{})
+}
+
+// SaveFileScan 保存扫描结果，替换此前的发现
+func SaveFileScan(scan *FileScan, findings []ScanFinding) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hash = ?", scan.Hash).Delete(&ScanFinding{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(scan).Error; err != nil {
+			return err
+		}
+		if len(findings) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(findings, 100).Error
+	})
+}
+
+// GetFileScan 查询扫描结果，尚未扫描时返回 nil
+func GetFileScan(hash string) (*FileScan, error) {
+	var scan FileScan
+	result := DB.Where("hash = ?", hash).First(&scan)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &scan, nil
+}
+
+// QueryScanFindings 按行号查询文件的发现
+func QueryScanFindings(hash string) ([]ScanFinding, error) {
+	var findings []ScanFinding
+	result := DB.Where("hash = ?", hash).Order("line, id").Find(&findings)
+	return findings, result.Error
+}
+
+// QueryScannedHashes 查询已用不低于 version 的扫描器扫描过的文件
+func QueryScannedHashes(version int) (map[string]bool, error) {
+	var hashes []string
+	result := DB.Model(&FileScan{}).Where("version >= ?", version).Pluck("hash", &hashes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	scanned := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		scanned[hash] = true
+	}
+	return scanned, nil
+}
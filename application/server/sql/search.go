@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DatasetReadme 数据集最新版本说明文档的全文，用于搜索
+type DatasetReadme struct {
+	Owner   string `gorm:"primaryKey" json:"owner"`
+	Name    string `gorm:"primaryKey" json:"name"`
+	Version int    `json:"version"` // 版本序号
+	Hash    string `json:"hash"`    // 说明文档哈希
+	Content string `gorm:"type:longtext" json:"content"`
+}
+
+// SearchHit 搜索结果
+type SearchHit struct {
+	Owner   string
+	Name    string
+	Content string
+}
+
+func MigrateSearch(db *gorm.DB) error {
+	return db.AutoMigrate(&DatasetReadme{})
+}
+
+func SaveDatasetReadme(readme *DatasetReadme) error {
+	return DB.Save(readme).Error
+}
+
+func DeleteDatasetReadme(owner string, name string) error {
+	return DB.Where("owner = ? AND name = ?", owner, name).Delete(&DatasetReadme{}).Error
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SearchDatasets 按关键词搜索未删除的数据集，每个关键词须出现在
+// 所有者、名称、任务、标签或说明文档中，按下载次数排序
+func SearchDatasets(terms []string, limit int) ([]SearchHit, error) {
+	query := DB.Model(&MetadataTable{}).
+		Select("metadata_tables.owner, metadata_tables.name, dataset_readmes.content").
+		Joins("LEFT JOIN dataset_readmes ON dataset_readmes.owner = metadata_tables.owner AND dataset_readmes.name = metadata_tables.name").
+		Where("metadata_tables.deleted = ?", false)
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		query = query.Where(
+			"metadata_tables.owner LIKE ? OR metadata_tables.name LIKE ? OR metadata_tables.tasks LIKE ? OR metadata_tables.tags LIKE ? OR dataset_readmes.content LIKE ?",
+			pattern, pattern, pattern, pattern, pattern,
+		)
+	}
+
+	var hits []SearchHit
+	result := query.Order("metadata_tables.downloads DESC").Limit(limit).Scan(&hits)
+	return hits, result.Error
+}
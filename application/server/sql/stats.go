@@ -0,0 +1,37 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FileStats 表格文件的统计信息，文件内容不可变，按哈希与格式缓存
+type FileStats struct {
+	Hash       string    `gorm:"primaryKey;size:64" json:"hash"`
+	Format     string    `gorm:"primaryKey;size:16" json:"format"`
+	Rows       int64     `json:"rows"`
+	Columns    string    `gorm:"type:text" json:"columns"` // []ColumnStats (JSON)
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+func MigrateStats(db *gorm.DB) error {
+	return db.AutoMigrate(&FileStats{})
+}
+
+// GetFileStats 查询文件统计信息，不存在时返回 nil
+func GetFileStats(hash string, format string) (*FileStats, error) {
+	var stats FileStats
+	result := DB.Where("hash = ? AND format = ?", hash, format).First(&stats)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &stats, nil
+}
+
+func SaveFileStats(stats *FileStats) error {
+	return DB.Save(stats).Error
+}
@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"time"
+)
+
+// DatasetCount 数据集及其计数
+type DatasetCount struct {
+	Owner string
+	Name  string
+	Count int
+}
+
+// QueryDownloadTimes 查询 since 之后全部数据集的下载时间，不含上链失败的事件
+func QueryDownloadTimes(since time.Time) ([]DownloadEvent, error) {
+	var events []DownloadEvent
+	result := DB.Select("dataset_owner", "dataset_name", "created_at").
+		Where("status <> ? AND created_at >= ?", EventFailed, since).
+		Find(&events)
+	return events, result.Error
+}
+
+// QueryCoDownloads 查询下载过该数据集的用户还下载过的其他数据集，按共同下载者数排序
+func QueryCoDownloads(owner string, name string, limit int) ([]DatasetCount, error) {
+	var counts []DatasetCount
+	result := DB.Table("download_events AS other").
+		Select("other.dataset_owner AS owner, other.dataset_name AS name, COUNT(DISTINCT other.user) AS count").
+		Joins("JOIN download_events AS this ON this.user = other.user").
+		Where("this.dataset_owner = ? AND this.dataset_name = ? AND this.status <> ?", owner, name, EventFailed).
+		Where("NOT (other.dataset_owner = ? AND other.dataset_name = ?) AND other.status <> ?", owner, name, EventFailed).
+		Group("other.dataset_owner, other.dataset_name").
+		Order("count DESC").
+		Limit(limit).
+		Scan(&counts)
+	return counts, result.Error
+}
+
+// QueryActiveMetadata 查询全部未删除数据集的元数据
+func QueryActiveMetadata() ([]MetadataTable, error) {
+	var tables []MetadataTable
+	result := DB.Where("deleted = ?", false).Find(&tables)
+	return tables, result.Error
+}
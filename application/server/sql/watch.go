@@ -0,0 +1,177 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Star 用户收藏的数据集
+type Star struct {
+	DatasetOwner string    `gorm:"primaryKey" json:"dataset_owner"`
+	DatasetName  string    `gorm:"primaryKey" json:"dataset_name"`
+	User         string    `gorm:"primaryKey;index" json:"user"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Watch 用户关注的数据集，数据集变更时收到通知
+type Watch struct {
+	DatasetOwner string    `gorm:"primaryKey" json:"dataset_owner"`
+	DatasetName  string    `gorm:"primaryKey" json:"dataset_name"`
+	User         string    `gorm:"primaryKey;index" json:"user"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// 评论产生的通知类型，其余通知类型为链码事件名
+const (
+	NotificationCommentMention = "comment-mention" // 评论中提到了用户
+	NotificationCommentReply   = "comment-reply"   // 用户的评论收到回复
+)
+
+// Notification 站内通知，由链码事件或评论生成
+// 同一交易 (或评论) 对同一用户只生成一条同类通知，重放事件或编辑评论时不会重复
+type Notification struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	User         string    `gorm:"uniqueIndex:idx_notification_source;index:idx_notification_user" json:"user"`
+	TxID         string    `gorm:"uniqueIndex:idx_notification_source;size:64" json:"tx_id"`
+	CommentID    uint      `gorm:"uniqueIndex:idx_notification_source" json:"comment_id,omitempty"`
+	Type         string    `gorm:"uniqueIndex:idx_notification_source;size:64" json:"type"` // 链码事件名或评论通知类型
+	DatasetOwner string    `json:"dataset_owner"`
+	DatasetName  string    `json:"dataset_name"`
+	Version      int       `json:"version"` // 事件发生后的版本数量，评论通知为版本序号 (-1 表示整个数据集)
+	License      string    `json:"license"` // 事件发生后的许可证
+	Time         string    `json:"time"`    // 交易时间或评论时间
+	Read         bool      `gorm:"index:idx_notification_user" json:"read"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// EventCursor 记录已处理的链码事件所在区块，服务重启后从此处继续
+type EventCursor struct {
+	Name        string `gorm:"primaryKey;size:64"`
+	BlockNumber uint64
+}
+
+func MigrateWatch(db *gorm.DB) error {
+	// 旧的唯一索引不含评论ID
+	if db.Migrator().HasIndex(&Notification{}, "idx_notification_event") {
+		if err := db.Migrator().DropIndex(&Notification{}, "idx_notification_event"); err != nil {
+			return err
+		}
+	}
+	return db.AutoMigrate(&Star{}, &Watch{}, &Notification{}, &EventCursor{})
+}
+
+// StarDataset 收藏数据集，已收藏时不做任何操作
+func StarDataset(owner, name, user string) error {
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Star{DatasetOwner: owner, DatasetName: name, User: user}).Error
+}
+
+func UnstarDataset(owner, name, user string) error {
+	return DB.Where("dataset_owner = ? AND dataset_name = ? AND user = ?", owner, name, user).Delete(&Star{}).Error
+}
+
+// WatchDataset 关注数据集，已关注时不做任何操作
+func WatchDataset(owner, name, user string) error {
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Watch{DatasetOwner: owner, DatasetName: name, User: user}).Error
+}
+
+func UnwatchDataset(owner, name, user string) error {
+	return DB.Where("dataset_owner = ? AND dataset_name = ? AND user = ?", owner, name, user).Delete(&Watch{}).Error
+}
+
+func QueryStarsByUser(user string) ([]Star, error) {
+	var stars []Star
+	result := DB.Where("user = ?", user).Order("created_at DESC").Find(&stars)
+	return stars, result.Error
+}
+
+func QueryWatchesByUser(user string) ([]Watch, error) {
+	var watches []Watch
+	result := DB.Where("user = ?", user).Order("created_at DESC").Find(&watches)
+	return watches, result.Error
+}
+
+// CountStars 查询数据集的收藏数与关注数
+func CountStars(owner, name string) (stars int64, watchers int64, err error) {
+	if err = DB.Model(&Star{}).Where("dataset_owner = ? AND dataset_name = ?", owner, name).Count(&stars).Error; err != nil {
+		return
+	}
+	err = DB.Model(&Watch{}).Where("dataset_owner = ? AND dataset_name = ?", owner, name).Count(&watchers).Error
+	return
+}
+
+// QueryStarred 查询用户是否已收藏、关注数据集
+func QueryStarred(owner, name, user string) (starred bool, watching bool, err error) {
+	var count int64
+	if err = DB.Model(&Star{}).Where("dataset_owner = ? AND dataset_name = ? AND user = ?", owner, name, user).Count(&count).Error; err != nil {
+		return
+	}
+	starred = count > 0
+	if err = DB.Model(&Watch{}).Where("dataset_owner = ? AND dataset_name = ? AND user = ?", owner, name, user).Count(&count).Error; err != nil {
+		return
+	}
+	watching = count > 0
+	return
+}
+
+// QueryWatchers 查询关注数据集的用户
+func QueryWatchers(owner, name string) ([]string, error) {
+	var users []string
+	result := DB.Model(&Watch{}).Where("dataset_owner = ? AND dataset_name = ?", owner, name).Pluck("user", &users)
+	return users, result.Error
+}
+
+// CreateNotifications 批量创建通知，忽略已存在的通知
+func CreateNotifications(notifications []Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications).Error
+}
+
+// QueryNotifications 按时间倒序查询用户的通知
+func QueryNotifications(user string, unreadOnly bool, limit int) ([]Notification, error) {
+	var notifications []Notification
+	query := DB.Where("user = ?", user)
+	if unreadOnly {
+		query = query.Where("`read` = ?", false)
+	}
+	result := query.Order("id DESC").Limit(limit).Find(&notifications)
+	return notifications, result.Error
+}
+
+func CountUnreadNotifications(user string) (int64, error) {
+	var count int64
+	result := DB.Model(&Notification{}).Where("user = ? AND `read` = ?", user, false).Count(&count)
+	return count, result.Error
+}
+
+// MarkNotificationsRead 将用户的通知标记为已读，ids 为空时标记全部，返回更新的数量
+func MarkNotificationsRead(user string, ids []uint) (int64, error) {
+	query := DB.Model(&Notification{}).Where("user = ? AND `read` = ?", user, false)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Update("read", true)
+	return result.RowsAffected, result.Error
+}
+
+// GetEventCursor 查询已处理到的区块号，尚未处理过时返回 0
+func GetEventCursor(name string) (uint64, error) {
+	var cursor EventCursor
+	result := DB.Where("name = ?", name).First(&cursor)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, result.Error
+	}
+	return cursor.BlockNumber, nil
+}
+
+func SaveEventCursor(name string, blockNumber uint64) error {
+	return DB.Save(&EventCursor{Name: name, BlockNumber: blockNumber}).Error
+}
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhook 投递状态
+const (
+	DeliveryPending   = "pending"   // 等待投递或重试
+	DeliverySucceeded = "succeeded" // 接收方返回 2xx
+	DeliveryFailed    = "failed"    // 达到投递次数上限，不再重试
+)
+
+// Webhook 数据集所有者注册的 webhook
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Owner     string    `gorm:"index" json:"owner"` // 数据集所有者 (用户或组织)
+	Name      string    `json:"name"`               // 数据集名，为空表示所有者的全部数据集
+	URL       string    `gorm:"type:text" json:"url"`
+	Secret    string    `json:"-"`      // HMAC-SHA256 签名密钥
+	Events    string    `json:"events"` // 订阅的链码事件名，逗号分隔，为空表示全部
+	Creator   string    `json:"creator"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery webhook 投递记录
+// 同一 webhook 对同一事件只投递一次，重放链码事件时不会重复投递
+type WebhookDelivery struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	WebhookID    uint      `gorm:"uniqueIndex:idx_webhook_delivery_event" json:"webhook_id"`
+	EventKey     string    `gorm:"uniqueIndex:idx_webhook_delivery_event;size:128" json:"event_key"` // 交易ID，下载记录为记录ID
+	Event        string    `json:"event"`                                                            // 链码事件名
+	Payload      string    `gorm:"type:mediumtext" json:"payload"`                                   // 投递的 JSON
+	Status       string    `gorm:"index:idx_webhook_delivery_due;size:16" json:"status"`
+	NextAttempt  time.Time `gorm:"index:idx_webhook_delivery_due" json:"next_attempt"`
+	Attempts     int       `json:"attempts"`               // 已投递次数
+	ResponseCode int       `json:"response_code"`          // 最近一次投递的响应状态码，请求未完成时为 0
+	Error        string    `gorm:"type:text" json:"error"` // 最近一次投递失败的原因
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func MigrateWebhook(db *gorm.DB) error {
+	return db.AutoMigrate(&Webhook{}, &WebhookDelivery{})
+}
+
+func CreateWebhook(webhook *Webhook) error {
+	return DB.Create(webhook).Error
+}
+
+// GetWebhook 查询 webhook，不存在时返回 nil
+func GetWebhook(id uint) (*Webhook, error) {
+	var webhook Webhook
+	result := DB.Where("id = ?", id).First(&webhook)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &webhook, nil
+}
+
+func QueryWebhooks(owner string) ([]Webhook, error) {
+	var webhooks []Webhook
+	result := DB.Where("owner = ?", owner).Order("id").Find(&webhooks)
+	return webhooks, result.Error
+}
+
+// QueryDatasetWebhooks 查询订阅该数据集的 webhook
+func QueryDatasetWebhooks(owner, name string) ([]Webhook, error) {
+	var webhooks []Webhook
+	result := DB.Where("owner = ? AND (name = '' OR name = ?)", owner, name).Order("id").Find(&webhooks)
+	return webhooks, result.Error
+}
+
+// DeleteWebhook 删除 webhook 及其投递记录
+func DeleteWebhook(id uint) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&Webhook{}).Error
+	})
+}
+
+// CreateWebhookDeliveries 批量创建投递记录，忽略已存在的记录
+func CreateWebhookDeliveries(deliveries []WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// GetWebhookDelivery 查询投递记录，不存在时返回 nil
+func GetWebhookDelivery(id uint) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	result := DB.Where("id = ?", id).First(&delivery)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &delivery, nil
+}
+
+func SaveWebhookDelivery(delivery *WebhookDelivery) error {
+	return DB.Save(delivery).Error
+}
+
+// DueWebhookDeliveries 查询已到重试时间的投递记录
+func DueWebhookDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	result := DB.Where("status = ? AND next_attempt <= ?", DeliveryPending, now).
+		Order("next_attempt, id").Limit(limit).Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// QueryWebhookDeliveries 按时间倒序查询 webhook 的投递记录
+func QueryWebhookDeliveries(webhookID uint, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	result := DB.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&deliveries)
+	return deliveries, result.Error
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"chaincode/model"
+	"chaincode/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func getAccessRequest(stub shim.ChaincodeStubInterface, owner, name, user string) (*model.AccessRequest, error) {
+	requestByte, err := utils.GetStateByKey(stub, model.AccessRequestDatasetKey, []string{owner, name, user})
+	if err != nil {
+		return nil, fmt.Errorf("getAccessRequest-查询访问申请出错: %s", err)
+	}
+	if requestByte == nil {
+		return nil, nil
+	}
+	var request model.AccessRequest
+	if err := json.Unmarshal(requestByte, &request); err != nil {
+		return nil, fmt.Errorf("getAccessRequest-反序列化出错: %s", err)
+	}
+	return &request, nil
+}
+func writeAccessRequest(stub shim.ChaincodeStubInterface, request model.AccessRequest) error {
+	keyDataset := []string{request.DatasetOwner, request.DatasetName, request.User}
+	keyUser := []string{request.User, request.DatasetOwner, request.DatasetName}
+	if err := utils.WriteLedger(request, stub, model.AccessRequestDatasetKey, keyDataset); err != nil {
+		return err
+	}
+	return utils.WriteLedger(request, stub, model.AccessRequestUserKey, keyUser)
+}
+
+// inOwnerNamespace 检查用户是否为所有者本人或所有者组织的成员
+func inOwnerNamespace(stub shim.ChaincodeStubInterface, owner, user string) (bool, error) {
+	if owner == user {
+		return true, nil
+	}
+	if exist, err := checkOrganizationExist(stub, owner); err != nil || !exist {
+		return false, err
+	}
+	org, err := getOrganization(stub, owner)
+	if err != nil {
+		return false, err
+	}
+	return getMemberRole(org, user) != "", nil
+}
+
+// hasDatasetAccess 检查用户能否下载数据集
+// 非受限数据集、数据集所有者 (及所属组织成员) 与申请已批准的用户可以下载
+func hasDatasetAccess(stub shim.ChaincodeStubInterface, dataset model.Dataset, user string) (bool, error) {
+	if !dataset.Gated {
+		return true, nil
+	}
+	if member, err := inOwnerNamespace(stub, dataset.Owner, user); err != nil || member {
+		return member, err
+	}
+	request, err := getAccessRequest(stub, dataset.Owner, dataset.Name, user)
+	if err != nil {
+		return false, err
+	}
+	return request != nil && request.Status == model.AccessApproved, nil
+}
+
+// [SetDatasetGated] 设置数据集是否受限访问
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 操作者ID | string
+// args[3]: 是否受限 | string (bool)
+// return: nil
+func SetDatasetGated(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("SetDatasetGated-参数数量错误")
+	}
+
+	gated, err := strconv.ParseBool(args[3])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetGated-参数错误: %s", err))
+	}
+
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetGated-查询数据集出错: %s", err))
+	}
+	if dataset.Deleted {
+		return shim.Error("SetDatasetGated-参数错误: 数据集已删除")
+	}
+	if err := checkManagePermission(stub, dataset.Owner, args[2]); err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetGated-权限不足: %s", err))
+	}
+
+	dataset.Gated = gated
+	err = utils.WriteLedger(dataset, stub, model.DatasetKey, []string{dataset.Owner, dataset.Name})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetGated-写入账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// [CreateAccessRequest] 提交访问申请
+// 被拒绝的申请可以重新提交
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 申请者ID | string
+// args[3]: 申请理由 | string
+// args[4]: 申请时间 | string (已弃用，使用交易时间)
+// return: nil
+func CreateAccessRequest(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 5 {
+		return shim.Error("CreateAccessRequest-参数数量错误")
+	}
+
+	requestTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-%s", err))
+	}
+
+	request := model.AccessRequest{
+		DatasetOwner:  args[0],
+		DatasetName:   args[1],
+		User:          args[2],
+		Justification: args[3],
+		Status:        model.AccessPending,
+		RequestTime:   requestTime,
+	}
+
+	if err := model.ValidateAccessRequest(request); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-参数错误: %s", err))
+	}
+
+	if exist, err := checkUserExist(stub, request.User); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-查询用户出错: %s", err))
+	} else if !exist {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-参数错误: 用户不存在: %s", request.User))
+	}
+
+	dataset, err := getDataset(stub, request.DatasetOwner, request.DatasetName)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-查询数据集出错: %s", err))
+	}
+	if dataset.Deleted {
+		return shim.Error("CreateAccessRequest-参数错误: 数据集已删除")
+	}
+	if !dataset.Gated {
+		return shim.Error("CreateAccessRequest-参数错误: 数据集无需申请访问")
+	}
+
+	existing, err := getAccessRequest(stub, request.DatasetOwner, request.DatasetName, request.User)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if existing != nil && existing.Status != model.AccessDenied {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-申请已存在: %s", existing.Status))
+	}
+
+	if err := writeAccessRequest(stub, request); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAccessRequest-写入账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// reviewAccessRequest 审批访问申请
+// pending -> approved, pending -> denied, approved -> denied (撤销)
+func reviewAccessRequest(stub shim.ChaincodeStubInterface, args []string, status string, funcName string) pb.Response {
+	if len(args) != 6 {
+		return shim.Error(fmt.Sprintf("%s-参数数量错误", funcName))
+	}
+	reviewer := args[3]
+
+	decisionTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("%s-%s", funcName, err))
+	}
+
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("%s-查询数据集出错: %s", funcName, err))
+	}
+	if err := checkManagePermission(stub, dataset.Owner, reviewer); err != nil {
+		return shim.Error(fmt.Sprintf("%s-权限不足: %s", funcName, err))
+	}
+
+	request, err := getAccessRequest(stub, dataset.Owner, dataset.Name, args[2])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if request == nil {
+		return shim.Error(fmt.Sprintf("%s-申请不存在", funcName))
+	}
+	if request.Status == status || request.Status == model.AccessDenied {
+		return shim.Error(fmt.Sprintf("%s-申请状态错误: %s", funcName, request.Status))
+	}
+
+	request.Status = status
+	request.Reviewer = reviewer
+	request.Comment = args[4]
+	request.DecisionTime = decisionTime
+
+	if err := model.ValidateAccessRequest(*request); err != nil {
+		return shim.Error(fmt.Sprintf("%s-参数错误: %s", funcName, err))
+	}
+
+	if err := writeAccessRequest(stub, *request); err != nil {
+		return shim.Error(fmt.Sprintf("%s-写入账本出错: %s", funcName, err))
+	}
+	return shim.Success(nil)
+}
+
+// [ApproveAccessRequest] 批准访问申请
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 申请者ID | string
+// args[3]: 审批者ID | string
+// args[4]: 审批意见 | string
+// args[5]: 审批时间 | string (已弃用，使用交易时间)
+// return: nil
+func ApproveAccessRequest(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	return reviewAccessRequest(stub, args, model.AccessApproved, "ApproveAccessRequest")
+}
+
+// [DenyAccessRequest] 拒绝访问申请，或撤销已批准的申请
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 申请者ID | string
+// args[3]: 审批者ID | string
+// args[4]: 审批意见 | string
+// args[5]: 审批时间 | string (已弃用，使用交易时间)
+// return: nil
+func DenyAccessRequest(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	return reviewAccessRequest(stub, args, model.AccessDenied, "DenyAccessRequest")
+}
+
+// [QueryAccessRequestsByDataset] 查询数据集的访问申请列表
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 状态过滤 (可选) | string
+// return: []AccessRequest | string (JSON)
+func QueryAccessRequestsByDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 && len(args) != 3 {
+		return shim.Error("QueryAccessRequestsByDataset-参数数量错误")
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.AccessRequestDatasetKey, []string{args[0], args[1]})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAccessRequestsByDataset-查询访问申请出错: %s", err))
+	}
+
+	var requests []model.AccessRequest
+	for _, requestByte := range res {
+		var request model.AccessRequest
+		err = json.Unmarshal(requestByte, &request)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("QueryAccessRequestsByDataset-反序列化出错: %s", err))
+		}
+		if len(args) == 3 && args[2] != "" && request.Status != args[2] {
+			continue
+		}
+		requests = append(requests, request)
+	}
+
+	requestsByte, err := json.Marshal(requests)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAccessRequestsByDataset-序列化出错: %s", err))
+	}
+
+	return shim.Success(requestsByte)
+}
+
+// [QueryAccessRequestsByUser] 查询用户提交的访问申请列表
+// args[0]: 申请者ID | string
+// return: []AccessRequest | string (JSON)
+func QueryAccessRequestsByUser(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("QueryAccessRequestsByUser-参数数量错误")
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.AccessRequestUserKey, []string{args[0]})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAccessRequestsByUser-查询访问申请出错: %s", err))
+	}
+
+	var requests []model.AccessRequest
+	for _, requestByte := range res {
+		var request model.AccessRequest
+		err = json.Unmarshal(requestByte, &request)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("QueryAccessRequestsByUser-反序列化出错: %s", err))
+		}
+		requests = append(requests, request)
+	}
+
+	requestsByte, err := json.Marshal(requests)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAccessRequestsByUser-序列化出错: %s", err))
+	}
+
+	return shim.Success(requestsByte)
+}
+
+// [CheckDatasetAccess] 检查用户能否下载数据集
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 用户ID | string
+// return: bool | string
+func CheckDatasetAccess(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("CheckDatasetAccess-参数数量错误")
+	}
+
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CheckDatasetAccess-查询数据集出错: %s", err))
+	}
+
+	ok, err := hasDatasetAccess(stub, dataset, args[2])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CheckDatasetAccess-查询访问权限出错: %s", err))
+	}
+
+	return shim.Success([]byte(strconv.FormatBool(ok)))
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"chaincode/model"
+	"chaincode/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// checkAttester 检查用户是否持有认证者角色
+func checkAttester(stub shim.ChaincodeStubInterface, user string) (bool, error) {
+	attesterByte, err := utils.GetStateByKey_Single(stub, model.AttesterKey, user)
+	if err != nil {
+		return false, fmt.Errorf("checkAttester-查询认证者出错: %s", err)
+	}
+	return attesterByte != nil, nil
+}
+
+// [GrantAttester] 授予用户认证者角色，只有平台管理员可以操作
+// args[0]: 操作者ID | string
+// args[1]: 用户ID | string
+// return: nil
+func GrantAttester(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("GrantAttester-参数数量错误")
+	}
+	if args[0] != model.AdminUser {
+		return shim.Error("GrantAttester-权限不足: 只有平台管理员可以授予认证者角色")
+	}
+	if exist, err := checkUserExist(stub, args[1]); err != nil {
+		return shim.Error(fmt.Sprintf("GrantAttester-查询用户出错: %s", err))
+	} else if !exist {
+		return shim.Error(fmt.Sprintf("GrantAttester-参数错误: 用户不存在: %s", args[1]))
+	}
+
+	grantTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("GrantAttester-%s", err))
+	}
+	attester := model.Attester{User: args[1], GrantedBy: args[0], Time: grantTime}
+	if err := utils.WriteLedger_Single(attester, stub, model.AttesterKey, attester.User); err != nil {
+		return shim.Error(fmt.Sprintf("GrantAttester-写入账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// [RevokeAttester] 撤销用户的认证者角色，已作出的认证仍然保留
+// args[0]: 操作者ID | string
+// args[1]: 用户ID | string
+// return: nil
+func RevokeAttester(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("RevokeAttester-参数数量错误")
+	}
+	if args[0] != model.AdminUser {
+		return shim.Error("RevokeAttester-权限不足: 只有平台管理员可以撤销认证者角色")
+	}
+	if ok, err := checkAttester(stub, args[1]); err != nil {
+		return shim.Error(fmt.Sprintf("RevokeAttester-%s", err))
+	} else if !ok {
+		return shim.Error(fmt.Sprintf("RevokeAttester-参数错误: 用户不是认证者: %s", args[1]))
+	}
+
+	if err := utils.DelLedger_Single(stub, model.AttesterKey, args[1]); err != nil {
+		return shim.Error(fmt.Sprintf("RevokeAttester-删除账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// [QueryAttesters] 查询认证者列表
+// args: nil
+// return: []Attester | string (JSON)
+func QueryAttesters(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 0 {
+		return shim.Error("QueryAttesters-参数数量错误")
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.AttesterKey, []string{})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAttesters-查询认证者出错: %s", err))
+	}
+
+	var attesters []model.Attester
+	for _, attesterByte := range res {
+		var attester model.Attester
+		if err := json.Unmarshal(attesterByte, &attester); err != nil {
+			return shim.Error(fmt.Sprintf("QueryAttesters-反序列化出错: %s", err))
+		}
+		attesters = append(attesters, attester)
+	}
+
+	attestersByte, err := json.Marshal(attesters)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAttesters-序列化出错: %s", err))
+	}
+	return shim.Success(attestersByte)
+}
+
+// [CreateAttestation] 认证数据集版本的某项属性，认证者须持有认证者角色
+// 同一认证者对同一版本的同一认证类型再次认证时覆盖此前的结果
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 版本序号 (从 0 开始) | string (int)
+// args[3]: 认证者ID | string
+// args[4]: 认证类型 | string
+// args[5]: 结果 (pass/warn/fail) | string
+// args[6]: 证据的 SHA-256 哈希 | string
+// args[7]: 说明 | string
+// return: Attestation | string (JSON)
+func CreateAttestation(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 8 {
+		return shim.Error("CreateAttestation-参数数量错误")
+	}
+
+	version, err := strconv.Atoi(args[2])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-参数错误: %s", err))
+	}
+	if ok, err := checkAttester(stub, args[3]); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-%s", err))
+	} else if !ok {
+		return shim.Error(fmt.Sprintf("CreateAttestation-权限不足: 用户不是认证者: %s", args[3]))
+	}
+
+	if exist, err := checkDatasetExist(stub, args[0], args[1]); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-查询数据集出错: %s", err))
+	} else if !exist {
+		return shim.Error("CreateAttestation-参数错误: 数据集不存在")
+	}
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-查询数据集出错: %s", err))
+	}
+	if dataset.Deleted {
+		return shim.Error("CreateAttestation-参数错误: 数据集已删除")
+	}
+	if version < 0 || version >= len(dataset.Versions) {
+		return shim.Error(fmt.Sprintf("CreateAttestation-参数错误: 版本不存在: %d", version))
+	}
+
+	attestTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-%s", err))
+	}
+	attestation := model.Attestation{
+		DatasetOwner: dataset.Owner,
+		DatasetName:  dataset.Name,
+		Version:      version,
+		MerkleRoot:   dataset.Versions[version].MerkleRoot,
+		Attester:     args[3],
+		Claim:        args[4],
+		Result:       args[5],
+		Evidence:     args[6],
+		Details:      args[7],
+		Time:         attestTime,
+	}
+	if err := model.ValidateAttestation(attestation); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-参数错误: %s", err))
+	}
+
+	key := []string{
+		attestation.DatasetOwner,
+		attestation.DatasetName,
+		strconv.Itoa(attestation.Version),
+		attestation.Attester,
+		attestation.Claim,
+	}
+	if err := utils.WriteLedger(attestation, stub, model.AttestationKey, key); err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-写入账本出错: %s", err))
+	}
+
+	attestationByte, err := json.Marshal(attestation)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateAttestation-序列化出错: %s", err))
+	}
+	return shim.Success(attestationByte)
+}
+
+// [QueryAttestationsByVersion] 查询数据集版本的认证
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 版本序号 (从 0 开始) | string (int)
+// return: []Attestation | string (JSON)
+func QueryAttestationsByVersion(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("QueryAttestationsByVersion-参数数量错误")
+	}
+	version, err := strconv.Atoi(args[2])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAttestationsByVersion-参数错误: %s", err))
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.AttestationKey, []string{args[0], args[1], strconv.Itoa(version)})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAttestationsByVersion-查询认证出错: %s", err))
+	}
+
+	var attestations []model.Attestation
+	for _, attestationByte := range res {
+		var attestation model.Attestation
+		if err := json.Unmarshal(attestationByte, &attestation); err != nil {
+			return shim.Error(fmt.Sprintf("QueryAttestationsByVersion-反序列化出错: %s", err))
+		}
+		attestations = append(attestations, attestation)
+	}
+
+	attestationsByte, err := json.Marshal(attestations)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAttestationsByVersion-序列化出错: %s", err))
+	}
+	return shim.Success(attestationsByte)
+}
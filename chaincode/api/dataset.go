@@ -2,9 +2,11 @@ package api
 
 import (
 	"chaincode/model"
+	"chaincode/pkg/merkle"
 	"chaincode/pkg/utils"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -32,20 +34,125 @@ func checkDatasetExist(stub shim.ChaincodeStubInterface, owner, name string) (bo
 	}
 	return datasetByte != nil, nil
 }
+func getDatasetsByOwner(stub shim.ChaincodeStubInterface, owner string) ([]model.Dataset, error) {
+	res, err := utils.GetStateByPartialKey(stub, model.DatasetKey, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("getDatasetsByOwner-查询数据集出错: %s", err)
+	}
+	var datasets []model.Dataset
+	for _, datasetByte := range res {
+		var dataset model.Dataset
+		if err := json.Unmarshal(datasetByte, &dataset); err != nil {
+			return nil, fmt.Errorf("getDatasetsByOwner-反序列化出错: %s", err)
+		}
+		datasets = append(datasets, dataset)
+	}
+	return datasets, nil
+}
 
-// [CreateDataset] 创建数据集
-// args[0]: 所有者ID | string
+// checkNamespacePermission 检查用户能否在所有者命名空间下创建数据集
+// 用户命名空间只允许本人，组织命名空间允许任意成员
+func checkNamespacePermission(stub shim.ChaincodeStubInterface, owner, operator string) error {
+	if exist, err := checkUserExist(stub, owner); err != nil {
+		return err
+	} else if exist {
+		if operator != owner {
+			return fmt.Errorf("无权操作其他用户的命名空间")
+		}
+		return nil
+	}
+
+	org, err := getOrganization(stub, owner)
+	if err != nil {
+		return fmt.Errorf("所有者不存在")
+	}
+	if getMemberRole(org, operator) == "" {
+		return fmt.Errorf("用户不是组织成员: %s", operator)
+	}
+	return nil
+}
+
+// checkManagePermission 检查用户能否管理所有者命名空间下的数据集
+// 用户命名空间只允许本人，组织命名空间允许所有者和管理员
+func checkManagePermission(stub shim.ChaincodeStubInterface, owner, operator string) error {
+	if exist, err := checkUserExist(stub, owner); err != nil {
+		return err
+	} else if exist {
+		if operator != owner {
+			return fmt.Errorf("无权管理其他用户的数据集")
+		}
+		return nil
+	}
+
+	org, err := getOrganization(stub, owner)
+	if err != nil {
+		return fmt.Errorf("所有者不存在")
+	}
+	if role := getMemberRole(org, operator); role != model.RoleOwner && role != model.RoleAdmin {
+		return fmt.Errorf("用户不是组织所有者或管理员: %s", operator)
+	}
+	return nil
+}
+
+// versionEntries 返回版本文件列表对应的默克尔树叶子
+func versionEntries(version model.Version) []merkle.Entry {
+	entries := make([]merkle.Entry, 0, len(version.Files))
+	for _, file := range version.Files {
+		entries = append(entries, merkle.Entry{FileName: file.FileName, Hash: file.Hash})
+	}
+	return entries
+}
+
+// datasetHasFile 检查文件是否属于数据集的某个版本 (文件名与哈希均相同)
+func datasetHasFile(dataset model.Dataset, file model.DatasetFile) bool {
+	for _, version := range dataset.Versions {
+		for _, f := range version.Files {
+			if f.Hash == file.Hash && f.FileName == file.FileName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setDatasetEvent 设置数据集变更事件
+func setDatasetEvent(stub shim.ChaincodeStubInterface, name string, dataset model.Dataset) error {
+	txTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return err
+	}
+	return utils.SetEvent(stub, name, model.DatasetEvent{
+		Owner:   dataset.Owner,
+		Name:    dataset.Name,
+		Version: len(dataset.Versions),
+		License: dataset.License,
+		Time:    txTime,
+	})
+}
+
+// [CreateDataset] 创建数据集，设置 dataset-created 事件
+// args[0]: 所有者ID (用户或组织) | string
 // args[1]: 数据集名字 | string
+// args[2]: 操作者ID (可选，所有者为组织时必填) | string
+// args[3]: 许可证 (可选，SPDX 标识符，为空表示无许可证) | string
+// args[4]: 下载前是否需要接受许可证 (与 args[3] 同时提供) | string (bool)
 // return: nil
 func CreateDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	if len(args) != 2 {
+	if len(args) != 2 && len(args) != 3 && len(args) != 5 {
 		return shim.Error("CreateDataset-参数数量错误")
 	}
 
-	if exist, err := checkUserExist(stub, args[0]); err != nil {
+	operator := args[0]
+	if len(args) >= 3 {
+		operator = args[2]
+	}
+	if exist, err := checkOwnerExist(stub, args[0]); err != nil {
 		return shim.Error(fmt.Sprintf("CreateDataset-查询用户出错: %s", err))
 	} else if !exist {
-		return shim.Error("CreateDataset-参数错误: 用户不存在")
+		return shim.Error("CreateDataset-参数错误: 所有者不存在")
+	}
+	if err := checkNamespacePermission(stub, args[0], operator); err != nil {
+		return shim.Error(fmt.Sprintf("CreateDataset-权限不足: %s", err))
 	}
 
 	dataset := model.Dataset{
@@ -54,6 +161,18 @@ func CreateDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response
 		Versions: []model.Version{},
 		Deleted:  false,
 	}
+	// 许可证与数据集在同一交易中写入，条款版本从 1 开始
+	if len(args) == 5 {
+		requireLicense, err := strconv.ParseBool(args[4])
+		if err != nil {
+			return shim.Error(fmt.Sprintf("CreateDataset-参数错误: %s", err))
+		}
+		dataset.License = args[3]
+		dataset.RequireLicense = requireLicense
+		if dataset.License != "" {
+			dataset.LicenseVersion = 1
+		}
+	}
 
 	if err := model.ValidateDataset(dataset); err != nil {
 		return shim.Error(fmt.Sprintf("CreateDataset-参数错误: %s", err))
@@ -69,14 +188,17 @@ func CreateDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response
 	if err != nil {
 		return shim.Error(fmt.Sprintf("CreateDataset-写入账本出错: %s", err))
 	}
+	if err := setDatasetEvent(stub, model.EventDatasetCreated, dataset); err != nil {
+		return shim.Error(fmt.Sprintf("CreateDataset-%s", err))
+	}
 
 	return shim.Success(nil)
 }
 
-// AddDatasetVersion 添加数据集版本
+// AddDatasetVersion 添加数据集版本，设置 dataset-version-added 事件
 // args[0]: 所有者ID | string
 // args[1]: 数据集名字 | string
-// args[2]: 版本 Version | string (JSON)
+// args[2]: 版本 Version | string (JSON)，CreationTime 由链码取交易时间
 // return: nil
 func AddDatasetVersion(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	if len(args) != 3 {
@@ -103,6 +225,40 @@ func AddDatasetVersion(stub shim.ChaincodeStubInterface, args []string) pb.Respo
 		return shim.Error(fmt.Sprintf("AddDatasetVersions-反序列化出错: %s", err))
 	}
 
+	// 创建时间取交易时间，默克尔根由链码计算，均忽略客户端传入的值
+	creationTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("AddDatasetVersions-%s", err))
+	}
+	version.CreationTime = creationTime
+	version.MerkleRoot = ""
+	if err := model.ValidateVersion(version); err != nil {
+		return shim.Error(fmt.Sprintf("AddDatasetVersions-参数错误: %s", err))
+	}
+	if root, err := merkle.Root(versionEntries(version)); err != nil {
+		return shim.Error(fmt.Sprintf("AddDatasetVersions-计算默克尔根出错: %s", err))
+	} else {
+		version.MerkleRoot = root
+	}
+
+	// 未指定说明文档时使用根目录下的 README.md
+	// 指定的说明文档必须是该版本中的文件，避免将其他 (受限) 数据集的文件公开为说明文档
+	if version.Readme == "" {
+		for _, file := range version.Files {
+			if file.FileName == model.ReadmeFileName {
+				version.Readme = file.Hash
+			}
+		}
+	} else {
+		found := false
+		for _, file := range version.Files {
+			found = found || file.Hash == version.Readme
+		}
+		if !found {
+			return shim.Error(fmt.Sprintf("AddDatasetVersions-参数错误: 说明文档不是该版本中的文件: %s", version.Readme))
+		}
+	}
+
 	dataset.Versions = append(dataset.Versions, version)
 	if err := model.ValidateDataset(dataset); err != nil {
 		return shim.Error(fmt.Sprintf("AddDatasetVersions-参数错误: %s", err))
@@ -139,6 +295,9 @@ func AddDatasetVersion(stub shim.ChaincodeStubInterface, args []string) pb.Respo
 	if err != nil {
 		return shim.Error(fmt.Sprintf("AddDatasetVersions-写入账本出错: %s", err))
 	}
+	if err := setDatasetEvent(stub, model.EventDatasetVersionAdded, dataset); err != nil {
+		return shim.Error(fmt.Sprintf("AddDatasetVersions-%s", err))
+	}
 	return shim.Success(nil)
 }
 
@@ -175,9 +334,10 @@ func QueryAllDatasets(stub shim.ChaincodeStubInterface, args []string) pb.Respon
 
 // [QueryDatasetsByUser] 查询某个用户的数据集列表
 // args[0]: 用户ID | string
+// args[1]: 是否包含用户所属组织的数据集 (可选) | string (bool)
 // return: []Dataset | string (JSON)
 func QueryDatasetsByUser(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	if len(args) != 1 {
+	if len(args) != 1 && len(args) != 2 {
 		return shim.Error("QueryDatasetsByUser-参数数量错误")
 	}
 
@@ -187,19 +347,23 @@ func QueryDatasetsByUser(stub shim.ChaincodeStubInterface, args []string) pb.Res
 		return shim.Error("QueryDatasetsByUser-参数错误: 用户不存在")
 	}
 
-	res, err := utils.GetStateByPartialKey(stub, model.DatasetKey, []string{args[0]})
+	datasets, err := getDatasetsByOwner(stub, args[0])
 	if err != nil {
 		return shim.Error(fmt.Sprintf("QueryDatasetsByUser-查询数据集出错: %s", err))
 	}
 
-	var datasets []model.Dataset
-	for _, datasetByte := range res {
-		var dataset model.Dataset
-		err = json.Unmarshal(datasetByte, &dataset)
+	if len(args) == 2 && utils.Str2Bool(args[1]) {
+		orgs, err := getOrganizationsByUser(stub, args[0])
 		if err != nil {
-			return shim.Error(fmt.Sprintf("QueryDatasetsByUser-反序列化出错: %s", err))
+			return shim.Error(fmt.Sprintf("QueryDatasetsByUser-查询组织出错: %s", err))
+		}
+		for _, org := range orgs {
+			orgDatasets, err := getDatasetsByOwner(stub, org.ID)
+			if err != nil {
+				return shim.Error(fmt.Sprintf("QueryDatasetsByUser-查询数据集出错: %s", err))
+			}
+			datasets = append(datasets, orgDatasets...)
 		}
-		datasets = append(datasets, dataset)
 	}
 
 	datasetsByte, err := json.Marshal(datasets)
@@ -227,7 +391,7 @@ func QueryDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	return shim.Success(dataset)
 }
 
-// DeleteDataset 删除数据集
+// DeleteDataset 删除数据集，设置 dataset-deleted 事件
 // args[0]: 所有者ID | string
 // args[1]: 数据集名字 | string
 // return: nil
@@ -261,6 +425,51 @@ func DeleteDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response
 	if err != nil {
 		return shim.Error(fmt.Sprintf("DeleteDataset-写入账本出错: %s", err))
 	}
+	if err := setDatasetEvent(stub, model.EventDatasetDeleted, dataset); err != nil {
+		return shim.Error(fmt.Sprintf("DeleteDataset-%s", err))
+	}
 
 	return shim.Success(nil)
 }
+
+// [QueryFileProof] 查询文件属于数据集版本的默克尔包含证明
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 版本序号 (从 0 开始) | string (int32)
+// args[3]: 文件名 | string
+// return: merkle.Proof | string (JSON)
+func QueryFileProof(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("QueryFileProof-参数数量错误")
+	}
+
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryFileProof-查询数据集出错: %s", err))
+	}
+	if dataset.Deleted {
+		return shim.Error("QueryFileProof-参数错误: 数据集已删除")
+	}
+
+	index, err := strconv.Atoi(args[2])
+	if err != nil || index < 0 || index >= len(dataset.Versions) {
+		return shim.Error(fmt.Sprintf("QueryFileProof-参数错误: 版本不存在: %s", args[2]))
+	}
+	version := dataset.Versions[index]
+
+	proof, err := merkle.BuildProof(versionEntries(version), args[3])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryFileProof-生成证明出错: %s", err))
+	}
+	// 早期版本未保存默克尔根，此时以计算结果为准
+	if version.MerkleRoot != "" && version.MerkleRoot != proof.Root {
+		return shim.Error("QueryFileProof-默克尔根与账本记录不一致")
+	}
+
+	proofByte, err := json.Marshal(proof)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryFileProof-序列化出错: %s", err))
+	}
+
+	return shim.Success(proofByte)
+}
@@ -23,8 +23,19 @@ func getFile(stub shim.ChaincodeStubInterface, fileHash string) (model.File, err
 	if err != nil {
 		return model.File{}, fmt.Errorf("getFile-反序列化出错: %s", err)
 	}
+	fillFileDefaults(&file)
 	return file, nil
 }
+
+// fillFileDefaults 为早期的文件记录填充上传者与上传时间，下次写入时随之保存
+func fillFileDefaults(file *model.File) {
+	if file.Uploader == "" {
+		file.Uploader = model.Unknown
+	}
+	if file.UploadTime == "" {
+		file.UploadTime = model.Unknown
+	}
+}
 func checkFileExist(stub shim.ChaincodeStubInterface, fileHash string) (bool, error) {
 	fileByte, err := utils.GetStateByKey_Single(stub, model.FileKey, fileHash)
 	if err != nil {
@@ -52,25 +63,42 @@ func decrementFileReferenceCount(stub shim.ChaincodeStubInterface, fileHash stri
 	return utils.WriteLedger_Single(file, stub, model.FileKey, file.Hash)
 }
 
-// [CreateFile] 创建文件
+// [CreateFile] 创建文件，上传时间取交易时间
+// 上传者由调用方声明，链码无法验证 (服务端以同一身份提交全部交易)；
+// 实际提交交易的证书身份另存于 submitter
 // args[0]: 文件哈希 | string
 // args[1]: 文件大小 | string (int64)
+// args[2]: 上传者ID (调用方声明) | string
 // return: nil
 func CreateFile(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	if len(args) != 2 {
+	if len(args) != 3 {
 		return shim.Error("CreateFile-参数数量错误")
 	}
 
+	uploadTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateFile-%s", err))
+	}
+
 	file := model.File{
 		Hash:           args[0],
 		Size:           utils.Str2Int64(args[1]),
 		ReferenceCount: 0,
+		Uploader:       args[2],
+		UploadTime:     uploadTime,
+		Submitter:      utils.GetSubmitter(stub),
 	}
 
 	if err := model.ValidateFile(file); err != nil {
 		return shim.Error(fmt.Sprintf("CreateFile-参数错误: %s", err))
 	}
 
+	if exist, err := checkUserExist(stub, file.Uploader); err != nil {
+		return shim.Error(fmt.Sprintf("CreateFile-查询用户出错: %s", err))
+	} else if !exist {
+		return shim.Error(fmt.Sprintf("CreateFile-参数错误: 用户不存在: %s", file.Uploader))
+	}
+
 	if exist, err := checkFileExist(stub, file.Hash); err != nil {
 		return shim.Error(fmt.Sprintf("CreateFile-查询文件出错: %s", err))
 	} else if exist {
@@ -80,6 +108,10 @@ func CreateFile(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	if err := utils.WriteLedger_Single(file, stub, model.FileKey, file.Hash); err != nil {
 		return shim.Error(fmt.Sprintf("CreateFile-写入账本出错: %s", err))
 	}
+	// 上传者索引只保存哈希，引用计数以主记录为准
+	if err := utils.WriteLedger(file.Hash, stub, model.FileUploaderKey, []string{file.Uploader, file.Hash}); err != nil {
+		return shim.Error(fmt.Sprintf("CreateFile-写入账本出错: %s", err))
+	}
 
 	return shim.Success(nil)
 }
@@ -100,6 +132,17 @@ func QueryFile(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 		return shim.Error("QueryFile-文件不存在")
 	}
 
+	var file model.File
+	if err := json.Unmarshal(fileByte, &file); err != nil {
+		return shim.Error(fmt.Sprintf("QueryFile-反序列化出错: %s", err))
+	}
+	fillFileDefaults(&file)
+
+	fileByte, err = json.Marshal(file)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryFile-序列化出错: %s", err))
+	}
+
 	return shim.Success(fileByte)
 }
 
@@ -133,3 +176,69 @@ func QueryFiles(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 
 	return shim.Success(filesByte)
 }
+
+// [QueryAllFiles] 查询全部文件信息
+// args: nil
+// return: []File | string (JSON)
+func QueryAllFiles(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 0 {
+		return shim.Error("QueryAllFiles-参数数量错误")
+	}
+
+	res, err := utils.GetStateByObjectType(stub, model.FileKey)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAllFiles-查询文件出错: %s", err))
+	}
+
+	var files []model.File
+	for _, fileByte := range res {
+		var file model.File
+		err = json.Unmarshal(fileByte, &file)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("QueryAllFiles-反序列化出错: %s", err))
+		}
+		fillFileDefaults(&file)
+		files = append(files, file)
+	}
+
+	filesByte, err := json.Marshal(files)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAllFiles-序列化出错: %s", err))
+	}
+
+	return shim.Success(filesByte)
+}
+
+// [QueryFilesByUploader] 查询用户上传的文件，上传者为调用方声明的值
+// args[0]: 上传者ID | string
+// return: []File | string (JSON)
+func QueryFilesByUploader(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("QueryFilesByUploader-参数数量错误")
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.FileUploaderKey, []string{args[0]})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryFilesByUploader-查询文件出错: %s", err))
+	}
+
+	files := []model.File{}
+	for _, hashByte := range res {
+		var hash string
+		if err := json.Unmarshal(hashByte, &hash); err != nil {
+			return shim.Error(fmt.Sprintf("QueryFilesByUploader-反序列化出错: %s", err))
+		}
+		file, err := getFile(stub, hash)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		files = append(files, file)
+	}
+
+	filesByte, err := json.Marshal(files)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryFilesByUploader-序列化出错: %s", err))
+	}
+
+	return shim.Success(filesByte)
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"chaincode/model"
+	"chaincode/pkg/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func getLicenseAcceptance(stub shim.ChaincodeStubInterface, owner, name, user string) (*model.LicenseAcceptance, error) {
+	acceptanceByte, err := utils.GetStateByKey(stub, model.LicenseAcceptanceDatasetKey, []string{owner, name, user})
+	if err != nil {
+		return nil, fmt.Errorf("getLicenseAcceptance-查询许可证接受记录出错: %s", err)
+	}
+	if acceptanceByte == nil {
+		return nil, nil
+	}
+	var acceptance model.LicenseAcceptance
+	if err := json.Unmarshal(acceptanceByte, &acceptance); err != nil {
+		return nil, fmt.Errorf("getLicenseAcceptance-反序列化出错: %s", err)
+	}
+	return &acceptance, nil
+}
+
+// hasAcceptedLicense 检查用户是否已接受数据集当前版本的许可证
+// 不要求接受许可证的数据集、数据集所有者 (及所属组织成员) 总是返回 true
+func hasAcceptedLicense(stub shim.ChaincodeStubInterface, dataset model.Dataset, user string) (bool, error) {
+	if !dataset.RequireLicense {
+		return true, nil
+	}
+	if member, err := inOwnerNamespace(stub, dataset.Owner, user); err != nil || member {
+		return member, err
+	}
+	acceptance, err := getLicenseAcceptance(stub, dataset.Owner, dataset.Name, user)
+	if err != nil {
+		return false, err
+	}
+	return acceptance != nil &&
+		acceptance.License == dataset.License &&
+		acceptance.LicenseVersion == dataset.LicenseVersion, nil
+}
+
+// [SetDatasetLicense] 设置数据集许可证
+// 许可证变更时条款版本递增，此前的接受记录随之失效，并设置 dataset-license-changed 事件
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 操作者ID | string
+// args[3]: 许可证 (SPDX 标识符，为空表示无许可证) | string
+// args[4]: 下载前是否需要接受许可证 | string (bool)
+// return: nil
+func SetDatasetLicense(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 5 {
+		return shim.Error("SetDatasetLicense-参数数量错误")
+	}
+
+	requireLicense, err := strconv.ParseBool(args[4])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetLicense-参数错误: %s", err))
+	}
+
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetLicense-查询数据集出错: %s", err))
+	}
+	if dataset.Deleted {
+		return shim.Error("SetDatasetLicense-参数错误: 数据集已删除")
+	}
+	if err := checkManagePermission(stub, dataset.Owner, args[2]); err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetLicense-权限不足: %s", err))
+	}
+
+	changed := dataset.License != args[3]
+	if changed {
+		dataset.License = args[3]
+		dataset.LicenseVersion++
+	}
+	dataset.RequireLicense = requireLicense
+
+	if err := model.ValidateDataset(dataset); err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetLicense-参数错误: %s", err))
+	}
+
+	err = utils.WriteLedger(dataset, stub, model.DatasetKey, []string{dataset.Owner, dataset.Name})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("SetDatasetLicense-写入账本出错: %s", err))
+	}
+	if changed {
+		if err := setDatasetEvent(stub, model.EventDatasetLicenseChanged, dataset); err != nil {
+			return shim.Error(fmt.Sprintf("SetDatasetLicense-%s", err))
+		}
+	}
+	return shim.Success(nil)
+}
+
+// [AcceptLicense] 接受数据集当前的许可证
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// args[2]: 用户ID | string
+// args[3]: 接受时间 | string (已弃用，使用交易时间)
+// return: LicenseAcceptance | string (JSON)
+func AcceptLicense(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("AcceptLicense-参数数量错误")
+	}
+
+	acceptTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-%s", err))
+	}
+
+	if exist, err := checkUserExist(stub, args[2]); err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-查询用户出错: %s", err))
+	} else if !exist {
+		return shim.Error(fmt.Sprintf("AcceptLicense-参数错误: 用户不存在: %s", args[2]))
+	}
+
+	dataset, err := getDataset(stub, args[0], args[1])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-查询数据集出错: %s", err))
+	}
+	if dataset.Deleted {
+		return shim.Error("AcceptLicense-参数错误: 数据集已删除")
+	}
+	if dataset.License == "" {
+		return shim.Error("AcceptLicense-参数错误: 数据集未设置许可证")
+	}
+
+	acceptance := model.LicenseAcceptance{
+		DatasetOwner:   dataset.Owner,
+		DatasetName:    dataset.Name,
+		User:           args[2],
+		License:        dataset.License,
+		LicenseVersion: dataset.LicenseVersion,
+		Time:           acceptTime,
+	}
+
+	if err := model.ValidateLicenseAcceptance(acceptance); err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-参数错误: %s", err))
+	}
+
+	keyDataset := []string{acceptance.DatasetOwner, acceptance.DatasetName, acceptance.User}
+	keyUser := []string{acceptance.User, acceptance.DatasetOwner, acceptance.DatasetName}
+	if err := utils.WriteLedger(acceptance, stub, model.LicenseAcceptanceDatasetKey, keyDataset); err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-写入账本出错: %s", err))
+	}
+	if err := utils.WriteLedger(acceptance, stub, model.LicenseAcceptanceUserKey, keyUser); err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-写入账本出错: %s", err))
+	}
+
+	acceptanceByte, err := json.Marshal(acceptance)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("AcceptLicense-序列化出错: %s", err))
+	}
+	return shim.Success(acceptanceByte)
+}
+
+// [QueryLicenseAcceptancesByDataset] 查询数据集的许可证接受记录
+// args[0]: 所有者ID | string
+// args[1]: 数据集名字 | string
+// return: []LicenseAcceptance | string (JSON)
+func QueryLicenseAcceptancesByDataset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("QueryLicenseAcceptancesByDataset-参数数量错误")
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.LicenseAcceptanceDatasetKey, []string{args[0], args[1]})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryLicenseAcceptancesByDataset-查询许可证接受记录出错: %s", err))
+	}
+
+	var acceptances []model.LicenseAcceptance
+	for _, acceptanceByte := range res {
+		var acceptance model.LicenseAcceptance
+		err = json.Unmarshal(acceptanceByte, &acceptance)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("QueryLicenseAcceptancesByDataset-反序列化出错: %s", err))
+		}
+		acceptances = append(acceptances, acceptance)
+	}
+
+	acceptancesByte, err := json.Marshal(acceptances)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryLicenseAcceptancesByDataset-序列化出错: %s", err))
+	}
+
+	return shim.Success(acceptancesByte)
+}
+
+// [QueryLicenseAcceptancesByUser] 查询用户的许可证接受记录
+// args[0]: 用户ID | string
+// return: []LicenseAcceptance | string (JSON)
+func QueryLicenseAcceptancesByUser(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("QueryLicenseAcceptancesByUser-参数数量错误")
+	}
+
+	res, err := utils.GetStateByPartialKey(stub, model.LicenseAcceptanceUserKey, []string{args[0]})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryLicenseAcceptancesByUser-查询许可证接受记录出错: %s", err))
+	}
+
+	var acceptances []model.LicenseAcceptance
+	for _, acceptanceByte := range res {
+		var acceptance model.LicenseAcceptance
+		err = json.Unmarshal(acceptanceByte, &acceptance)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("QueryLicenseAcceptancesByUser-反序列化出错: %s", err))
+		}
+		acceptances = append(acceptances, acceptance)
+	}
+
+	acceptancesByte, err := json.Marshal(acceptances)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryLicenseAcceptancesByUser-序列化出错: %s", err))
+	}
+
+	return shim.Success(acceptancesByte)
+}
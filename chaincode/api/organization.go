@@ -0,0 +1,320 @@
+package api
+
+import (
+	"chaincode/model"
+	"chaincode/pkg/utils"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func getOrganization(stub shim.ChaincodeStubInterface, orgID string) (model.Organization, error) {
+	orgByte, err := utils.GetStateByKey_Single(stub, model.OrganizationKey, orgID)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("getOrganization-查询组织出错: %s", err)
+	}
+	if orgByte == nil {
+		return model.Organization{}, fmt.Errorf("getOrganization-组织不存在")
+	}
+	var org model.Organization
+	err = json.Unmarshal(orgByte, &org)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("getOrganization-反序列化出错: %s", err)
+	}
+	return org, nil
+}
+func checkOrganizationExist(stub shim.ChaincodeStubInterface, orgID string) (bool, error) {
+	orgByte, err := utils.GetStateByKey_Single(stub, model.OrganizationKey, orgID)
+	if err != nil {
+		return false, fmt.Errorf("checkOrganizationExist-查询组织出错: %s", err)
+	}
+	return orgByte != nil, nil
+}
+
+// checkOwnerExist 检查数据集所有者 (用户或组织) 是否存在
+func checkOwnerExist(stub shim.ChaincodeStubInterface, ownerID string) (bool, error) {
+	if exist, err := checkUserExist(stub, ownerID); err != nil || exist {
+		return exist, err
+	}
+	return checkOrganizationExist(stub, ownerID)
+}
+
+// getMemberRole 返回用户在组织中的角色，非成员返回空字符串
+func getMemberRole(org model.Organization, userID string) string {
+	for _, member := range org.Members {
+		if member.User == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// getOrganizationsByUser 查询用户所属的全部组织
+func getOrganizationsByUser(stub shim.ChaincodeStubInterface, userID string) ([]model.Organization, error) {
+	res, err := utils.GetStateByObjectType(stub, model.OrganizationKey)
+	if err != nil {
+		return nil, fmt.Errorf("getOrganizationsByUser-查询组织出错: %s", err)
+	}
+
+	var orgs []model.Organization
+	for _, orgByte := range res {
+		var org model.Organization
+		if err := json.Unmarshal(orgByte, &org); err != nil {
+			return nil, fmt.Errorf("getOrganizationsByUser-反序列化出错: %s", err)
+		}
+		if getMemberRole(org, userID) != "" {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs, nil
+}
+
+// [CreateOrganization] 创建组织
+// args[0]: 组织ID | string
+// args[1]: 组织名 | string
+// args[2]: 创建者ID (成为组织所有者) | string
+// return: nil
+func CreateOrganization(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("CreateOrganization-参数数量错误")
+	}
+
+	org := model.Organization{
+		ID:   args[0],
+		Name: args[1],
+		Members: []model.Member{
+			{User: args[2], Role: model.RoleOwner},
+		},
+	}
+
+	if err := model.ValidateOrganization(org); err != nil {
+		return shim.Error(fmt.Sprintf("CreateOrganization-参数错误: %s", err))
+	}
+
+	if exist, err := checkUserExist(stub, args[2]); err != nil {
+		return shim.Error(fmt.Sprintf("CreateOrganization-查询用户出错: %s", err))
+	} else if !exist {
+		return shim.Error("CreateOrganization-参数错误: 用户不存在")
+	}
+
+	// 组织与用户共享命名空间，ID 不能重复
+	if exist, err := checkOwnerExist(stub, org.ID); err != nil {
+		return shim.Error(fmt.Sprintf("CreateOrganization-查询组织出错: %s", err))
+	} else if exist {
+		return shim.Error("CreateOrganization-组织ID已被占用")
+	}
+
+	if err := utils.WriteLedger_Single(org, stub, model.OrganizationKey, org.ID); err != nil {
+		return shim.Error(fmt.Sprintf("CreateOrganization-写入账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// [QueryOrganization] 查询组织
+// args[0]: 组织ID | string
+// return: Organization | string (JSON)
+func QueryOrganization(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("QueryOrganization-参数数量错误")
+	}
+
+	org, err := getOrganization(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	orgByte, err := json.Marshal(org)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryOrganization-序列化出错: %s", err))
+	}
+
+	return shim.Success(orgByte)
+}
+
+// [QueryAllOrganizations] 查询组织列表
+// args: nil
+// return: []Organization | string (JSON)
+func QueryAllOrganizations(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 0 {
+		return shim.Error("QueryAllOrganizations-参数数量错误")
+	}
+
+	res, err := utils.GetStateByObjectType(stub, model.OrganizationKey)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAllOrganizations-查询组织出错: %s", err))
+	}
+
+	var orgs []model.Organization
+	for _, orgByte := range res {
+		var org model.Organization
+		err = json.Unmarshal(orgByte, &org)
+		if err != nil {
+			return shim.Error(fmt.Sprintf("QueryAllOrganizations-反序列化出错: %s", err))
+		}
+		orgs = append(orgs, org)
+	}
+
+	orgsByte, err := json.Marshal(orgs)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryAllOrganizations-序列化出错: %s", err))
+	}
+
+	return shim.Success(orgsByte)
+}
+
+// [QueryOrganizationsByUser] 查询用户所属的组织列表
+// args[0]: 用户ID | string
+// return: []Organization | string (JSON)
+func QueryOrganizationsByUser(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("QueryOrganizationsByUser-参数数量错误")
+	}
+
+	orgs, err := getOrganizationsByUser(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	orgsByte, err := json.Marshal(orgs)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryOrganizationsByUser-序列化出错: %s", err))
+	}
+
+	return shim.Success(orgsByte)
+}
+
+// [AddOrganizationMember] 添加组织成员或修改成员角色
+// 所有者可以授予任意角色；管理员只能管理普通成员
+// args[0]: 组织ID | string
+// args[1]: 操作者ID | string
+// args[2]: 成员ID | string
+// args[3]: 角色 (owner/admin/member) | string
+// return: nil
+func AddOrganizationMember(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("AddOrganizationMember-参数数量错误")
+	}
+	operator := args[1]
+	member := model.Member{User: args[2], Role: args[3]}
+
+	org, err := getOrganization(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if exist, err := checkUserExist(stub, member.User); err != nil {
+		return shim.Error(fmt.Sprintf("AddOrganizationMember-查询用户出错: %s", err))
+	} else if !exist {
+		return shim.Error(fmt.Sprintf("AddOrganizationMember-参数错误: 用户不存在: %s", member.User))
+	}
+
+	operatorRole := getMemberRole(org, operator)
+	currentRole := getMemberRole(org, member.User)
+	switch operatorRole {
+	case model.RoleOwner:
+	case model.RoleAdmin:
+		if member.Role != model.RoleMember || (currentRole != "" && currentRole != model.RoleMember) {
+			return shim.Error("AddOrganizationMember-权限不足: 管理员只能管理普通成员")
+		}
+	default:
+		return shim.Error("AddOrganizationMember-权限不足: 操作者不是组织所有者或管理员")
+	}
+
+	if currentRole == "" {
+		org.Members = append(org.Members, member)
+	} else {
+		for i := range org.Members {
+			if org.Members[i].User == member.User {
+				org.Members[i].Role = member.Role
+			}
+		}
+	}
+
+	if err := model.ValidateOrganization(org); err != nil {
+		return shim.Error(fmt.Sprintf("AddOrganizationMember-参数错误: %s", err))
+	}
+
+	if err := utils.WriteLedger_Single(org, stub, model.OrganizationKey, org.ID); err != nil {
+		return shim.Error(fmt.Sprintf("AddOrganizationMember-写入账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// [RemoveOrganizationMember] 移除组织成员
+// 所有者可以移除任意成员；管理员只能移除普通成员；成员可以退出组织
+// args[0]: 组织ID | string
+// args[1]: 操作者ID | string
+// args[2]: 成员ID | string
+// return: nil
+func RemoveOrganizationMember(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("RemoveOrganizationMember-参数数量错误")
+	}
+	operator := args[1]
+	userID := args[2]
+
+	org, err := getOrganization(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	currentRole := getMemberRole(org, userID)
+	if currentRole == "" {
+		return shim.Error(fmt.Sprintf("RemoveOrganizationMember-参数错误: 用户不是组织成员: %s", userID))
+	}
+
+	operatorRole := getMemberRole(org, operator)
+	switch {
+	case operator == userID:
+	case operatorRole == model.RoleOwner:
+	case operatorRole == model.RoleAdmin && currentRole == model.RoleMember:
+	default:
+		return shim.Error("RemoveOrganizationMember-权限不足")
+	}
+
+	members := make([]model.Member, 0, len(org.Members))
+	for _, member := range org.Members {
+		if member.User != userID {
+			members = append(members, member)
+		}
+	}
+	org.Members = members
+
+	if err := model.ValidateOrganization(org); err != nil {
+		return shim.Error(fmt.Sprintf("RemoveOrganizationMember-参数错误: %s", err))
+	}
+
+	if err := utils.WriteLedger_Single(org, stub, model.OrganizationKey, org.ID); err != nil {
+		return shim.Error(fmt.Sprintf("RemoveOrganizationMember-写入账本出错: %s", err))
+	}
+	return shim.Success(nil)
+}
+
+// [QueryDatasetsByOrganization] 查询组织命名空间下的数据集列表
+// args[0]: 组织ID | string
+// return: []Dataset | string (JSON)
+func QueryDatasetsByOrganization(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("QueryDatasetsByOrganization-参数数量错误")
+	}
+
+	if exist, err := checkOrganizationExist(stub, args[0]); err != nil {
+		return shim.Error(fmt.Sprintf("QueryDatasetsByOrganization-查询组织出错: %s", err))
+	} else if !exist {
+		return shim.Error("QueryDatasetsByOrganization-参数错误: 组织不存在")
+	}
+
+	datasets, err := getDatasetsByOwner(stub, args[0])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryDatasetsByOrganization-查询数据集出错: %s", err))
+	}
+
+	datasetsByte, err := json.Marshal(datasets)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("QueryDatasetsByOrganization-序列化出错: %s", err))
+	}
+
+	return shim.Success(datasetsByte)
+}
@@ -10,81 +10,215 @@ import (
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
-// [CreateRecord] 创建下载记录
-// args[0]: 所有者ID | string
-// args[1]: 数据集名 | string
-// args[2]: 下载者ID | string
-// args[3]: 文件列表 []DatasetFile | string (JSON)
-// args[4]: 下载时间 | string
-// return: nil
-func CreateRecord(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	if len(args) != 5 {
-		return shim.Error("CreateRecord-参数数量错误")
-	}
-
-	var files []model.DatasetFile
-	if err := json.Unmarshal([]byte(args[3]), &files); err != nil {
-		return shim.Error(fmt.Sprintf("CreateRecord-反序列化出错: %s", err))
-	}
-
-	record := model.Record{
-		DatasetOwner: args[0],
-		DatasetName:  args[1],
-		User:         args[2],
-		Files:        files,
-		Time:         args[4],
-	}
-
-	if err := model.ValidateRecord(record); err != nil {
-		return shim.Error(fmt.Sprintf("CreateRecord-参数错误: %s", err))
+// writeRecord 校验并写入一条下载记录，记录只追加不覆盖
+// 写入时补全记录的许可证信息
+func writeRecord(stub shim.ChaincodeStubInterface, record *model.Record) error {
+	if err := model.ValidateRecord(*record); err != nil {
+		return fmt.Errorf("参数错误: %s", err)
 	}
 
 	if exist, err := checkUserExist(stub, record.User); err != nil {
-		return shim.Error(fmt.Sprintf("CreateRecord-查询用户出错: %s", err))
+		return fmt.Errorf("查询用户出错: %s", err)
 	} else if !exist {
-		return shim.Error(fmt.Sprintf("CreateRecord-参数错误: 用户不存在: %s", record.User))
+		return fmt.Errorf("参数错误: 用户不存在: %s", record.User)
 	}
 
 	if exist, err := checkDatasetExist(stub, record.DatasetOwner, record.DatasetName); err != nil {
-		return shim.Error(fmt.Sprintf("CreateRecord-查询数据集出错: %s", err))
+		return fmt.Errorf("查询数据集出错: %s", err)
 	} else if !exist {
-		return shim.Error(fmt.Sprintf("CreateRecord-参数错误: 数据集不存在: %s/%s",
+		return fmt.Errorf("参数错误: 数据集不存在: %s/%s",
 			record.DatasetOwner,
 			record.DatasetName,
-		))
+		)
 	}
 
+	dataset, err := getDataset(stub, record.DatasetOwner, record.DatasetName)
+	if err != nil {
+		return fmt.Errorf("查询数据集出错: %s", err)
+	}
+	if ok, err := hasDatasetAccess(stub, dataset, record.User); err != nil {
+		return fmt.Errorf("查询访问权限出错: %s", err)
+	} else if !ok {
+		return fmt.Errorf("权限不足: 访问申请未获批准: %s", record.User)
+	}
+	if ok, err := hasAcceptedLicense(stub, dataset, record.User); err != nil {
+		return fmt.Errorf("查询许可证接受记录出错: %s", err)
+	} else if !ok {
+		return fmt.Errorf("权限不足: 未接受当前许可证: %s", dataset.License)
+	}
+	// 记录下载时适用的许可证条款
+	record.License = dataset.License
+	record.LicenseVersion = dataset.LicenseVersion
+
 	for _, file := range record.Files {
 		if exist, err := checkFileExist(stub, file.Hash); err != nil {
-			return shim.Error(fmt.Sprintf("CreateRecord-查询文件出错: %s", err))
+			return fmt.Errorf("查询文件出错: %s", err)
 		} else if !exist {
-			return shim.Error(fmt.Sprintf("CreateRecord-参数错误: 文件不存在: %s %s",
+			return fmt.Errorf("参数错误: 文件不存在: %s %s",
 				file.Hash,
 				file.FileName,
-			))
+			)
+		}
+		// 只能记录数据集中的文件，避免借公开数据集的名义下载受限数据集的文件
+		if !datasetHasFile(dataset, file) {
+			return fmt.Errorf("参数错误: 文件不属于数据集: %s %s",
+				file.Hash,
+				file.FileName,
+			)
 		}
 	}
 
+	// 键以记录ID结尾，同一用户多次下载同一数据集时各自保留
 	keyDataset := []string{
 		record.DatasetOwner,
 		record.DatasetName,
 		record.User,
+		record.ID,
 	}
 	keyUser := []string{
 		record.User,
 		record.DatasetOwner,
 		record.DatasetName,
+		record.ID,
 	}
 
 	if err := utils.WriteLedger(record, stub, model.RecordDatasetKey, keyDataset); err != nil {
-		return shim.Error(fmt.Sprintf("CreateRecord-写入账本出错: %s", err))
+		return fmt.Errorf("写入账本出错: %s", err)
 	}
 	if err := utils.WriteLedger(record, stub, model.RecordUserKey, keyUser); err != nil {
-		return shim.Error(fmt.Sprintf("CreateRecord-写入账本出错: %s", err))
+		return fmt.Errorf("写入账本出错: %s", err)
+	}
+	if record.EventID != "" {
+		if err := utils.WriteLedger(record, stub, model.RecordEventKey, []string{record.EventID}); err != nil {
+			return fmt.Errorf("写入账本出错: %s", err)
+		}
+	}
+	return nil
+}
+
+// getRecordIDByEvent 查询下载事件已写入的记录ID，未写入时返回空字符串
+func getRecordIDByEvent(stub shim.ChaincodeStubInterface, eventID string) (string, error) {
+	recordByte, err := utils.GetStateByKey(stub, model.RecordEventKey, []string{eventID})
+	if err != nil || recordByte == nil {
+		return "", err
+	}
+	var record model.Record
+	if err := json.Unmarshal(recordByte, &record); err != nil {
+		return "", fmt.Errorf("反序列化出错: %s", err)
+	}
+	return record.ID, nil
+}
+
+// [CreateRecord] 创建下载记录，记录ID为交易ID，设置 records-created 事件
+// args[0]: 所有者ID | string
+// args[1]: 数据集名 | string
+// args[2]: 下载者ID | string
+// args[3]: 文件列表 []DatasetFile | string (JSON)
+// args[4]: 下载时间 | string (已弃用，使用交易时间)
+// return: nil
+func CreateRecord(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 5 {
+		return shim.Error("CreateRecord-参数数量错误")
+	}
+
+	recordTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecord-%s", err))
+	}
+
+	var files []model.DatasetFile
+	if err := json.Unmarshal([]byte(args[3]), &files); err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecord-反序列化出错: %s", err))
+	}
+
+	record := model.Record{
+		ID:           stub.GetTxID(),
+		DatasetOwner: args[0],
+		DatasetName:  args[1],
+		User:         args[2],
+		Files:        files,
+		Time:         recordTime,
+	}
+
+	if err := writeRecord(stub, &record); err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecord-%s", err))
+	}
+	if err := utils.SetEvent(stub, model.EventRecordsCreated, []model.Record{record}); err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecord-%s", err))
 	}
 	return shim.Success(nil)
 }
 
+// [CreateRecords] 批量创建下载记录，任一记录出错时整批失败
+// 记录ID为 "交易ID-序号"，序号为记录在列表中的位置 (从 0 开始)，设置 records-created 事件
+// event_id 已写入过的记录不再写入，返回已有的记录ID，客户端可安全地重新提交
+// args[0]: 记录列表 []Record (只使用 dataset_owner, dataset_name, user, files, event_id) | string (JSON)
+// return: []string 记录ID列表，与输入一一对应 | string (JSON)
+func CreateRecords(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("CreateRecords-参数数量错误")
+	}
+
+	recordTime, err := utils.GetTxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecords-%s", err))
+	}
+
+	var records []model.Record
+	if err := json.Unmarshal([]byte(args[0]), &records); err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecords-反序列化出错: %s", err))
+	}
+	if len(records) == 0 || len(records) > model.MaxRecordBatch {
+		return shim.Error(fmt.Sprintf("CreateRecords-参数错误: 记录数量须在 1 到 %d 之间", model.MaxRecordBatch))
+	}
+
+	ids := make([]string, 0, len(records))
+	written := make([]model.Record, 0, len(records))
+	seen := make(map[string]string) // 本批已写入的事件ID -> 记录ID
+	for i, r := range records {
+		if r.EventID != "" {
+			id, ok := seen[r.EventID]
+			if !ok {
+				if id, err = getRecordIDByEvent(stub, r.EventID); err != nil {
+					return shim.Error(fmt.Sprintf("CreateRecords-第 %d 条记录: 查询记录出错: %s", i, err))
+				}
+			}
+			if id != "" {
+				ids = append(ids, id)
+				continue
+			}
+		}
+		record := model.Record{
+			ID:           fmt.Sprintf("%s-%d", stub.GetTxID(), i),
+			DatasetOwner: r.DatasetOwner,
+			DatasetName:  r.DatasetName,
+			User:         r.User,
+			Files:        r.Files,
+			Time:         recordTime,
+			EventID:      r.EventID,
+		}
+		if err := writeRecord(stub, &record); err != nil {
+			return shim.Error(fmt.Sprintf("CreateRecords-第 %d 条记录: %s", i, err))
+		}
+		if record.EventID != "" {
+			seen[record.EventID] = record.ID
+		}
+		ids = append(ids, record.ID)
+		written = append(written, record)
+	}
+	if len(written) > 0 {
+		if err := utils.SetEvent(stub, model.EventRecordsCreated, written); err != nil {
+			return shim.Error(fmt.Sprintf("CreateRecords-%s", err))
+		}
+	}
+
+	idsByte, err := json.Marshal(ids)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("CreateRecords-序列化出错: %s", err))
+	}
+	return shim.Success(idsByte)
+}
+
 // [QueryRecordsByUser] 查询下载记录列表
 // args[0]: 下载者ID | string
 // return: []Record | string (JSON)
@@ -110,6 +110,11 @@ func CreateUser(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	} else if existing {
 		return shim.Error("CreateUser-用户已存在")
 	}
+	if existing, err := checkOrganizationExist(stub, userID); err != nil {
+		return shim.Error(err.Error())
+	} else if existing {
+		return shim.Error("CreateUser-用户ID已被组织占用")
+	}
 
 	if err := utils.WriteLedger_Single(user, stub, model.UserKey, userID); err != nil {
 		return shim.Error(fmt.Sprintf("CreateUser-写入账本出错: %s", err))
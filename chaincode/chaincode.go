@@ -49,6 +49,22 @@ func (t *BlockChainGenshin) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 	case "modifyUserName":
 		return api.ModifyUserName(stub, args)
 
+		// organization api
+	case "createOrganization":
+		return api.CreateOrganization(stub, args)
+	case "queryOrganization":
+		return api.QueryOrganization(stub, args)
+	case "queryAllOrganizations":
+		return api.QueryAllOrganizations(stub, args)
+	case "queryOrganizationsByUser":
+		return api.QueryOrganizationsByUser(stub, args)
+	case "addOrganizationMember":
+		return api.AddOrganizationMember(stub, args)
+	case "removeOrganizationMember":
+		return api.RemoveOrganizationMember(stub, args)
+	case "queryDatasetsByOrganization":
+		return api.QueryDatasetsByOrganization(stub, args)
+
 		// file api
 	case "createFile":
 		return api.CreateFile(stub, args)
@@ -56,6 +72,10 @@ func (t *BlockChainGenshin) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		return api.QueryFile(stub, args)
 	case "queryFiles":
 		return api.QueryFiles(stub, args)
+	case "queryAllFiles":
+		return api.QueryAllFiles(stub, args)
+	case "queryFilesByUploader":
+		return api.QueryFilesByUploader(stub, args)
 
 		// dataset api
 	case "createDataset":
@@ -70,10 +90,52 @@ func (t *BlockChainGenshin) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		return api.QueryDataset(stub, args)
 	case "deleteDataset":
 		return api.DeleteDataset(stub, args)
+	case "queryFileProof":
+		return api.QueryFileProof(stub, args)
+
+		// access api
+	case "setDatasetGated":
+		return api.SetDatasetGated(stub, args)
+	case "createAccessRequest":
+		return api.CreateAccessRequest(stub, args)
+	case "approveAccessRequest":
+		return api.ApproveAccessRequest(stub, args)
+	case "denyAccessRequest":
+		return api.DenyAccessRequest(stub, args)
+	case "queryAccessRequestsByDataset":
+		return api.QueryAccessRequestsByDataset(stub, args)
+	case "queryAccessRequestsByUser":
+		return api.QueryAccessRequestsByUser(stub, args)
+	case "checkDatasetAccess":
+		return api.CheckDatasetAccess(stub, args)
+
+		// license api
+	case "setDatasetLicense":
+		return api.SetDatasetLicense(stub, args)
+	case "acceptLicense":
+		return api.AcceptLicense(stub, args)
+	case "queryLicenseAcceptancesByDataset":
+		return api.QueryLicenseAcceptancesByDataset(stub, args)
+	case "queryLicenseAcceptancesByUser":
+		return api.QueryLicenseAcceptancesByUser(stub, args)
+
+		// attestation api
+	case "grantAttester":
+		return api.GrantAttester(stub, args)
+	case "revokeAttester":
+		return api.RevokeAttester(stub, args)
+	case "queryAttesters":
+		return api.QueryAttesters(stub, args)
+	case "createAttestation":
+		return api.CreateAttestation(stub, args)
+	case "queryAttestationsByVersion":
+		return api.QueryAttestationsByVersion(stub, args)
 
 		// record api
 	case "createRecord":
 		return api.CreateRecord(stub, args)
+	case "createRecords":
+		return api.CreateRecords(stub, args)
 	case "queryRecordsByUser":
 		return api.QueryRecordsByUser(stub, args)
 	case "queryRecordsByDataset":
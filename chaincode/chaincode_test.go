@@ -2,10 +2,13 @@ package main
 
 import (
 	"chaincode/model"
+	"chaincode/pkg/merkle"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -22,7 +25,22 @@ func initTest() *shim.MockStub {
 	return stub
 }
 
+// drainEvents 取出此前调用设置的链码事件
+// MockStub 的事件通道容量有限，写满后 SetEvent 会阻塞
+func drainEvents(stub *shim.MockStub) []*pb.ChaincodeEvent {
+	var events []*pb.ChaincodeEvent
+	for {
+		select {
+		case event := <-stub.ChaincodeEventsChannel:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
 func checkInvoke(t *testing.T, stub *shim.MockStub, success bool, args [][]byte) pb.Response {
+	drainEvents(stub)
 	res := stub.MockInvoke("1", args)
 	if success && res.Status != shim.OK || !success && res.Status == shim.OK {
 		fmt.Println("\n\n! Test failed on invoking")
@@ -113,6 +131,13 @@ func testUser(t *testing.T) {
 			[]byte("queryUser"),
 			[]byte("test_user1"),
 		}).Payload))
+
+	fmt.Printf("\n9: CreateUser [failed] (admin is created at init)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createUser"),
+			[]byte(model.AdminUser),
+			[]byte("Admin"),
+		}).Payload))
 }
 
 const sha256_a = "5feceb66ffc86f38d952786c6d696c79c2dbc239dd4e91b46729d73a27fb57e9"
@@ -146,21 +171,25 @@ func testFile(t *testing.T) {
 			[]byte("createFile"),
 			[]byte(sha256_a),
 			[]byte("1024"),
+			[]byte(dataset_owner),
 		}).Payload),
 		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("createFile"),
 			[]byte(sha256_b),
 			[]byte("1025"),
+			[]byte(dataset_owner),
 		}).Payload),
 		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("createFile"),
 			[]byte(sha256_c),
 			[]byte("1034"),
+			[]byte(dataset_owner),
 		}).Payload),
 		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("createFile"),
 			[]byte(sha256_d),
 			[]byte("1424"),
+			[]byte(dataset_owner),
 		}).Payload),
 	)
 
@@ -169,6 +198,7 @@ func testFile(t *testing.T) {
 			[]byte("createFile"),
 			[]byte(sha256_a),
 			[]byte("1024"),
+			[]byte(dataset_owner),
 		}).Payload))
 
 	fmt.Printf("\n3: CreateFile [failed] (invalid file size)\n%s",
@@ -176,6 +206,7 @@ func testFile(t *testing.T) {
 			[]byte("createFile"),
 			[]byte(sha256_e),
 			[]byte("-1"),
+			[]byte(dataset_owner),
 		}).Payload))
 
 	fmt.Printf("\n4: CreateFile [failed] (invalid file hash)\n%s",
@@ -183,19 +214,73 @@ func testFile(t *testing.T) {
 			[]byte("createFile"),
 			[]byte(sha256_invalid),
 			[]byte("1024"),
+			[]byte(dataset_owner),
 		}).Payload))
 
-	fmt.Printf("\n5: QueryFile [success]\n%s",
+	fmt.Printf("\n5: CreateFile [failed] (uploader not exist)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createFile"),
+			[]byte(sha256_e),
+			[]byte("1024"),
+			[]byte("test_user1145"),
+		}).Payload))
+
+	fmt.Printf("\n6: CreateFile [failed] (missing uploader)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createFile"),
+			[]byte(sha256_e),
+			[]byte("1024"),
+		}).Payload))
+
+	fmt.Printf("\n7: QueryFile [success]\n%s",
 		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("queryFile"),
 			[]byte(sha256_a),
 		}).Payload))
 
-	fmt.Printf("\n6: QueryFiles [success]\n%s",
+	fmt.Printf("\n8: QueryFiles [success]\n%s",
 		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("queryFiles"),
 			[]byte(ToJson([]string{sha256_a, sha256_b})),
 		}).Payload))
+
+	fmt.Printf("\n9: QueryAllFiles [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryAllFiles"),
+		}).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryFile"),
+		[]byte(sha256_a),
+	})
+	var file model.File
+	if err := json.Unmarshal(res.Payload, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Uploader != dataset_owner || file.UploadTime == "" || file.UploadTime == model.Unknown {
+		t.Fatalf("unexpected uploader or upload time: %+v", file)
+	}
+
+	res = checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryFilesByUploader"),
+		[]byte(dataset_owner),
+	})
+	fmt.Printf("\n10: QueryFilesByUploader [success]\n%s", string(res.Payload))
+	var files []model.File
+	if err := json.Unmarshal(res.Payload, &files); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("expected 4 files uploaded by %s, got %d", dataset_owner, len(files))
+	}
+
+	res = checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryFilesByUploader"),
+		[]byte(downloader),
+	})
+	if string(res.Payload) != "[]" {
+		t.Fatalf("expected no files uploaded by %s, got %s", downloader, string(res.Payload))
+	}
 }
 
 func testDataset(t *testing.T) {
@@ -267,14 +352,33 @@ func testDataset(t *testing.T) {
 			[]byte(ToJson(dataset_version1)),
 		}).Payload))
 
-	fmt.Printf("\n6: AddDatasetVersion [failed] (invalid dataset version time)\n%s",
-		string(checkInvoke(t, stub, false, [][]byte{
+	fmt.Printf("\n6: AddDatasetVersion [success] (client-supplied time is ignored)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("addDatasetVersion"),
 			[]byte(dataset_owner),
 			[]byte(dataset_name),
 			[]byte(ToJson(dataset_version_time_invalid)),
 		}).Payload))
 
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDataset"),
+		[]byte(dataset_owner),
+		[]byte(dataset_name),
+	})
+	var dataset model.Dataset
+	if err := json.Unmarshal(res.Payload, &dataset); err != nil {
+		t.Fatal(err)
+	}
+	for i, version := range dataset.Versions {
+		creationTime, err := time.Parse(time.RFC3339, version.CreationTime)
+		if err != nil || creationTime.Location() != time.UTC {
+			t.Fatalf("version %d: expected RFC 3339 UTC transaction time, got %q", i, version.CreationTime)
+		}
+		if version.CreationTime == dataset_version_time_invalid.CreationTime || version.CreationTime == dataset_version1.CreationTime {
+			t.Fatalf("version %d: client-supplied time was stored: %q", i, version.CreationTime)
+		}
+	}
+
 	fmt.Printf("\n7: AddDatasetVersion [failed] (invalid dataset version rows)\n%s",
 		string(checkInvoke(t, stub, false, [][]byte{
 			[]byte("addDatasetVersion"),
@@ -365,8 +469,8 @@ func testRecord(t *testing.T) {
 			[]byte("2021-01-01T00:00:00Z"),
 		}).Payload))
 
-	fmt.Printf("\n4: CreateRecord [failed] (invalid download time)\n%s",
-		string(checkInvoke(t, stub, false, [][]byte{
+	fmt.Printf("\n4: CreateRecord [success] (client-supplied time is ignored)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
 			[]byte("createRecord"),
 			[]byte(dataset_owner),
 			[]byte(dataset_name),
@@ -375,11 +479,21 @@ func testRecord(t *testing.T) {
 			[]byte("2021-01-01T00:00:00"),
 		}).Payload))
 
-	fmt.Printf("\n5: QueryRecordsByUser [success]\n%s",
-		string(checkInvoke(t, stub, true, [][]byte{
-			[]byte("queryRecordsByUser"),
-			[]byte(downloader),
-		}).Payload))
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryRecordsByUser"),
+		[]byte(downloader),
+	})
+	fmt.Printf("\n5: QueryRecordsByUser [success]\n%s", string(res.Payload))
+	var records []model.Record
+	if err := json.Unmarshal(res.Payload, &records); err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		recordTime, err := time.Parse(time.RFC3339, record.Time)
+		if err != nil || recordTime.Location() != time.UTC || record.Time == "2021-01-01T00:00:00Z" {
+			t.Fatalf("expected RFC 3339 UTC transaction time, got %q", record.Time)
+		}
+	}
 
 	fmt.Printf("\n6: QueryRecordsByDataset [success]\n%s",
 		string(checkInvoke(t, stub, true, [][]byte{
@@ -387,6 +501,1031 @@ func testRecord(t *testing.T) {
 			[]byte(dataset_owner),
 			[]byte(dataset_name),
 		}).Payload))
+
+	fmt.Printf("\n7: CreateRecord [failed] (file not in dataset)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createRecord"),
+			[]byte(dataset_owner),
+			[]byte(dataset_name),
+			[]byte(downloader),
+			[]byte(ToJson([]model.DatasetFile{{Hash: sha256_a, FileName: "other.txt"}})),
+			[]byte(""),
+		}).Payload))
+}
+
+// addVersion 为数据集添加只包含 files 的版本
+func addVersion(t *testing.T, owner, name string, files []model.DatasetFile) {
+	checkInvoke(t, stub, true, [][]byte{
+		[]byte("addDatasetVersion"),
+		[]byte(owner),
+		[]byte(name),
+		ToJson(model.Version{Files: files}),
+	})
+}
+
+// countRecords 返回数据集的下载记录数量
+func countRecords(t *testing.T, owner, name string) int {
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryRecordsByDataset"),
+		[]byte(owner),
+		[]byte(name),
+	})
+	var records []model.Record
+	if err := json.Unmarshal(res.Payload, &records); err != nil {
+		t.Fatal(err)
+	}
+	return len(records)
+}
+
+// invokeTx 以指定的交易ID调用链码 (checkInvoke 固定使用 "1")
+func invokeTx(t *testing.T, txID string, success bool, args [][]byte) pb.Response {
+	drainEvents(stub)
+	res := stub.MockInvoke(txID, args)
+	if success != (res.Status == shim.OK) {
+		t.Fatalf("%s: expected success=%v, got status %d: %s", args[0], success, res.Status, res.Message)
+	}
+	return res
+}
+
+func testRecordBatch(t *testing.T) {
+	record := model.Record{
+		DatasetOwner: dataset_owner,
+		DatasetName:  dataset_name,
+		User:         downloader,
+		Files:        filelist1,
+	}
+	before := countRecords(t, dataset_owner, dataset_name)
+
+	fmt.Printf("\n1: CreateRecord [success] (repeated download is appended)\n%s",
+		string(invokeTx(t, "tx_record_repeat", true, [][]byte{
+			[]byte("createRecord"),
+			[]byte(dataset_owner),
+			[]byte(dataset_name),
+			[]byte(downloader),
+			[]byte(ToJson(filelist1)),
+			[]byte(""),
+		}).Payload))
+	if n := countRecords(t, dataset_owner, dataset_name); n != before+1 {
+		t.Fatalf("expected %d records, got %d", before+1, n)
+	}
+
+	res := invokeTx(t, "tx_record_batch", true, [][]byte{
+		[]byte("createRecords"),
+		ToJson([]model.Record{record, record}),
+	})
+	fmt.Printf("\n2: CreateRecords [success]\n%s", string(res.Payload))
+	var ids []string
+	if err := json.Unmarshal(res.Payload, &ids); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "tx_record_batch-0" || ids[1] != "tx_record_batch-1" {
+		t.Fatalf("unexpected record IDs: %v", ids)
+	}
+	if n := countRecords(t, dataset_owner, dataset_name); n != before+3 {
+		t.Fatalf("expected %d records, got %d", before+3, n)
+	}
+
+	invalid := record
+	invalid.User = "test_user1145"
+	// MockStub 不会回滚失败调用中已写入的状态，无效记录放在首位
+	fmt.Printf("\n3: CreateRecords [failed] (one invalid record fails the batch)\n%s",
+		string(invokeTx(t, "tx_record_batch_invalid", false, [][]byte{
+			[]byte("createRecords"),
+			ToJson([]model.Record{invalid, record}),
+		}).Payload))
+	if n := countRecords(t, dataset_owner, dataset_name); n != before+3 {
+		t.Fatalf("expected %d records, got %d", before+3, n)
+	}
+
+	fmt.Printf("\n4: CreateRecords [failed] (empty batch)\n%s",
+		string(invokeTx(t, "tx_record_batch_empty", false, [][]byte{
+			[]byte("createRecords"),
+			ToJson([]model.Record{}),
+		}).Payload))
+
+	// 同一下载事件重复提交时返回已有记录ID，不再写入
+	withEvent := record
+	withEvent.EventID = "event_1"
+	for _, txID := range []string{"tx_record_event", "tx_record_event_retry"} {
+		res := invokeTx(t, txID, true, [][]byte{
+			[]byte("createRecords"),
+			ToJson([]model.Record{withEvent, withEvent}),
+		})
+		fmt.Printf("\n5: CreateRecords [success] (event %s)\n%s", txID, string(res.Payload))
+		var ids []string
+		if err := json.Unmarshal(res.Payload, &ids); err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 2 || ids[0] != "tx_record_event-0" || ids[1] != "tx_record_event-0" {
+			t.Fatalf("unexpected record IDs: %v", ids)
+		}
+	}
+	if n := countRecords(t, dataset_owner, dataset_name); n != before+4 {
+		t.Fatalf("expected %d records, got %d", before+4, n)
+	}
+}
+
+const org_id = "test_org"
+const org_dataset_name = "test_org_dataset"
+
+func testOrganization(t *testing.T) {
+	fmt.Printf("\n1: CreateOrganization [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createOrganization"),
+			[]byte(org_id),
+			[]byte("TestOrg"),
+			[]byte(dataset_owner),
+		}).Payload))
+
+	fmt.Printf("\n2: CreateOrganization [failed] (id taken by user)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createOrganization"),
+			[]byte(downloader),
+			[]byte("TestOrg"),
+			[]byte(dataset_owner),
+		}).Payload))
+
+	fmt.Printf("\n3: CreateUser [failed] (id taken by organization)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createUser"),
+			[]byte(org_id),
+			[]byte("TestUser3"),
+		}).Payload))
+
+	fmt.Printf("\n4: CreateDataset [failed] (not an organization member)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createDataset"),
+			[]byte(org_id),
+			[]byte(org_dataset_name),
+			[]byte(downloader),
+		}).Payload))
+
+	fmt.Printf("\n5: AddOrganizationMember [failed] (operator not owner or admin)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addOrganizationMember"),
+			[]byte(org_id),
+			[]byte(downloader),
+			[]byte(downloader),
+			[]byte(model.RoleMember),
+		}).Payload))
+
+	fmt.Printf("\n6: AddOrganizationMember [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("addOrganizationMember"),
+			[]byte(org_id),
+			[]byte(dataset_owner),
+			[]byte(downloader),
+			[]byte(model.RoleMember),
+		}).Payload))
+
+	fmt.Printf("\n7: CreateDataset [success] (organization namespace)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(org_id),
+			[]byte(org_dataset_name),
+			[]byte(downloader),
+		}).Payload))
+
+	fmt.Printf("\n8: QueryDatasetsByOrganization [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryDatasetsByOrganization"),
+			[]byte(org_id),
+		}).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDatasetsByUser"),
+		[]byte(downloader),
+		[]byte("true"),
+	})
+	var datasets []model.Dataset
+	if err := json.Unmarshal(res.Payload, &datasets); err != nil || len(datasets) != 1 {
+		t.Fatalf("QueryDatasetsByUser should include organization datasets: %s", string(res.Payload))
+	}
+	fmt.Printf("\n9: QueryDatasetsByUser [success] (include organizations)\n%s", string(res.Payload))
+
+	fmt.Printf("\n10: RemoveOrganizationMember [failed] (last owner)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("removeOrganizationMember"),
+			[]byte(org_id),
+			[]byte(dataset_owner),
+			[]byte(dataset_owner),
+		}).Payload))
+
+	fmt.Printf("\n11: RemoveOrganizationMember [success] (leave organization)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("removeOrganizationMember"),
+			[]byte(org_id),
+			[]byte(downloader),
+			[]byte(downloader),
+		}).Payload))
+
+	fmt.Printf("\n12: QueryOrganizationsByUser [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryOrganizationsByUser"),
+			[]byte(dataset_owner),
+		}).Payload))
+}
+
+const gated_dataset_name = "test_gated_dataset"
+
+func testAccess(t *testing.T) {
+	createRecord := [][]byte{
+		[]byte("createRecord"),
+		[]byte(dataset_owner),
+		[]byte(gated_dataset_name),
+		[]byte(downloader),
+		[]byte(ToJson(filelist1)),
+		[]byte("2021-01-01T00:00:00Z"),
+	}
+
+	fmt.Printf("\n1: CreateDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+		}).Payload))
+	addVersion(t, dataset_owner, gated_dataset_name, filelist1)
+
+	fmt.Printf("\n2: SetDatasetGated [failed] (not dataset owner)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("setDatasetGated"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+			[]byte("true"),
+		}).Payload))
+
+	fmt.Printf("\n3: SetDatasetGated [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("setDatasetGated"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(dataset_owner),
+			[]byte("true"),
+		}).Payload))
+
+	fmt.Printf("\n4: CreateRecord [failed] (access not approved)\n%s",
+		string(checkInvoke(t, stub, false, createRecord).Payload))
+
+	fmt.Printf("\n5: CreateAccessRequest [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createAccessRequest"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+			[]byte("For research purposes"),
+			[]byte("2021-01-01T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n6: CreateAccessRequest [failed] (request pending)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createAccessRequest"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+			[]byte("For research purposes"),
+			[]byte("2021-01-01T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n7: ApproveAccessRequest [failed] (reviewer not dataset owner)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("approveAccessRequest"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+			[]byte(downloader),
+			[]byte(""),
+			[]byte("2021-01-02T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n8: ApproveAccessRequest [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("approveAccessRequest"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+			[]byte(dataset_owner),
+			[]byte("Approved"),
+			[]byte("2021-01-02T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n9: CreateRecord [success] (access approved)\n%s",
+		string(checkInvoke(t, stub, true, createRecord).Payload))
+
+	fmt.Printf("\n10: DenyAccessRequest [success] (revoke access)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("denyAccessRequest"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+			[]byte(dataset_owner),
+			[]byte("Revoked"),
+			[]byte("2021-01-03T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n11: CheckDatasetAccess [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("checkDatasetAccess"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+			[]byte(downloader),
+		}).Payload))
+
+	fmt.Printf("\n12: CreateRecord [failed] (access revoked)\n%s",
+		string(checkInvoke(t, stub, false, createRecord).Payload))
+
+	fmt.Printf("\n13: QueryAccessRequestsByDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryAccessRequestsByDataset"),
+			[]byte(dataset_owner),
+			[]byte(gated_dataset_name),
+		}).Payload))
+
+	fmt.Printf("\n14: QueryAccessRequestsByUser [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryAccessRequestsByUser"),
+			[]byte(downloader),
+		}).Payload))
+}
+
+const licensed_dataset_name = "test_licensed_dataset"
+
+func testLicense(t *testing.T) {
+	createRecord := [][]byte{
+		[]byte("createRecord"),
+		[]byte(dataset_owner),
+		[]byte(licensed_dataset_name),
+		[]byte(downloader),
+		[]byte(ToJson(filelist1)),
+		[]byte("2021-01-01T00:00:00Z"),
+	}
+	setLicense := func(license string) [][]byte {
+		return [][]byte{
+			[]byte("setDatasetLicense"),
+			[]byte(dataset_owner),
+			[]byte(licensed_dataset_name),
+			[]byte(dataset_owner),
+			[]byte(license),
+			[]byte("true"),
+		}
+	}
+
+	fmt.Printf("\n1: CreateDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(licensed_dataset_name),
+		}).Payload))
+	addVersion(t, dataset_owner, licensed_dataset_name, filelist1)
+
+	fmt.Printf("\n2: SetDatasetLicense [failed] (invalid SPDX identifier)\n%s",
+		string(checkInvoke(t, stub, false, setLicense("MIT License")).Payload))
+
+	fmt.Printf("\n2: SetDatasetLicense [failed] (not on the SPDX list)\n%s",
+		string(checkInvoke(t, stub, false, setLicense("Made-Up-1.0")).Payload))
+
+	fmt.Printf("\n3: SetDatasetLicense [success]\n%s",
+		string(checkInvoke(t, stub, true, setLicense("CC-BY-4.0")).Payload))
+
+	fmt.Printf("\n4: CreateRecord [failed] (license not accepted)\n%s",
+		string(checkInvoke(t, stub, false, createRecord).Payload))
+
+	fmt.Printf("\n5: AcceptLicense [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("acceptLicense"),
+			[]byte(dataset_owner),
+			[]byte(licensed_dataset_name),
+			[]byte(downloader),
+			[]byte("2021-01-01T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n6: CreateRecord [success] (license accepted)\n%s",
+		string(checkInvoke(t, stub, true, createRecord).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryRecordsByDataset"),
+		[]byte(dataset_owner),
+		[]byte(licensed_dataset_name),
+	})
+	var records []model.Record
+	if err := json.Unmarshal(res.Payload, &records); err != nil || len(records) != 1 ||
+		records[0].License != "CC-BY-4.0" || records[0].LicenseVersion != 1 {
+		t.Fatalf("Record should carry the accepted license: %s", string(res.Payload))
+	}
+	fmt.Printf("\n7: QueryRecordsByDataset [success] (record carries license)\n%s", string(res.Payload))
+
+	fmt.Printf("\n8: SetDatasetLicense [success] (change license)\n%s",
+		string(checkInvoke(t, stub, true, setLicense("CC-BY-SA-4.0")).Payload))
+
+	fmt.Printf("\n9: CreateRecord [failed] (accepted license outdated)\n%s",
+		string(checkInvoke(t, stub, false, createRecord).Payload))
+
+	fmt.Printf("\n10: QueryLicenseAcceptancesByDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryLicenseAcceptancesByDataset"),
+			[]byte(dataset_owner),
+			[]byte(licensed_dataset_name),
+		}).Payload))
+
+	createLicensed := func(name, license string) [][]byte {
+		return [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(name),
+			[]byte(dataset_owner),
+			[]byte(license),
+			[]byte("true"),
+		}
+	}
+	fmt.Printf("\n11: CreateDataset [failed] (invalid license, nothing written)\n%s",
+		string(checkInvoke(t, stub, false, createLicensed(licensed_dataset_name+"_2", "Made-Up-1.0")).Payload))
+
+	fmt.Printf("\n12: CreateDataset [success] (license set in the same transaction)\n%s",
+		string(checkInvoke(t, stub, true, createLicensed(licensed_dataset_name+"_2", "MIT")).Payload))
+	res = checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDataset"),
+		[]byte(dataset_owner),
+		[]byte(licensed_dataset_name + "_2"),
+	})
+	var dataset model.Dataset
+	if err := json.Unmarshal(res.Payload, &dataset); err != nil ||
+		dataset.License != "MIT" || dataset.LicenseVersion != 1 || !dataset.RequireLicense {
+		t.Fatalf("CreateDataset should set the license: %s", string(res.Payload))
+	}
+
+	fmt.Printf("\n13: CreateDataset [failed] (license set, operator not the owner)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(licensed_dataset_name + "_3"),
+			[]byte(downloader),
+			[]byte("MIT"),
+			[]byte("true"),
+		}).Payload))
+
+	fmt.Printf("\n14: CreateDataset [success] (license set, organization namespace)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(org_id),
+			[]byte(licensed_dataset_name),
+			[]byte(dataset_owner),
+			[]byte("MIT"),
+			[]byte("true"),
+		}).Payload))
+	addVersion(t, org_id, licensed_dataset_name, filelist1)
+
+	fmt.Printf("\n15: CreateRecord [success] (organization member, license not accepted)\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createRecord"),
+			[]byte(org_id),
+			[]byte(licensed_dataset_name),
+			[]byte(dataset_owner),
+			[]byte(ToJson(filelist1)),
+			[]byte("2021-01-01T00:00:00Z"),
+		}).Payload))
+
+	fmt.Printf("\n16: CreateRecord [failed] (not a member, license not accepted)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("createRecord"),
+			[]byte(org_id),
+			[]byte(licensed_dataset_name),
+			[]byte(downloader),
+			[]byte(ToJson(filelist1)),
+			[]byte("2021-01-01T00:00:00Z"),
+		}).Payload))
+}
+
+const merkle_dataset_name = "test_merkle_dataset"
+
+func testMerkle(t *testing.T) {
+	fmt.Printf("\n1: CreateDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+		}).Payload))
+
+	fmt.Printf("\n2: AddDatasetVersion [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+			[]byte(ToJson(model.Version{
+				Files:        filelist2,
+				Rows:         200,
+				CreationTime: "2021-01-02T00:00:00Z",
+				ChangeLog:    "Merkle root",
+				MerkleRoot:   sha256_e,
+			})),
+		}).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDataset"),
+		[]byte(dataset_owner),
+		[]byte(merkle_dataset_name),
+	})
+	var dataset model.Dataset
+	if err := json.Unmarshal(res.Payload, &dataset); err != nil || len(dataset.Versions) != 1 {
+		t.Fatalf("QueryDataset failed: %s", string(res.Payload))
+	}
+	root := dataset.Versions[0].MerkleRoot
+	if root == "" || root == sha256_e {
+		t.Fatalf("Merkle root should be computed by the chaincode: %s", root)
+	}
+	fmt.Printf("\n3: QueryDataset [success] (merkle root computed)\n%s", root)
+
+	for _, file := range filelist2 {
+		res := checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryFileProof"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+			[]byte("0"),
+			[]byte(file.FileName),
+		})
+		var proof merkle.Proof
+		if err := json.Unmarshal(res.Payload, &proof); err != nil {
+			t.Fatalf("QueryFileProof failed: %s", string(res.Payload))
+		}
+		if proof.Root != root || proof.Entry.Hash != file.Hash {
+			t.Fatalf("Proof does not match version: %s", string(res.Payload))
+		}
+		if err := merkle.Verify(proof); err != nil {
+			t.Fatalf("Proof verification failed: %s", err)
+		}
+		proof.Entry.Hash = sha256_e
+		if err := merkle.Verify(proof); err == nil {
+			t.Fatalf("Tampered proof should not verify")
+		}
+	}
+	fmt.Printf("\n4: QueryFileProof x4 [success] (proofs verified)\n")
+
+	fmt.Printf("\n5: QueryFileProof [failed] (file not in version)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("queryFileProof"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+			[]byte("0"),
+			[]byte("missing.txt"),
+		}).Payload))
+
+	fmt.Printf("\n6: QueryFileProof [failed] (version not exist)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("queryFileProof"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+			[]byte("1"),
+			[]byte("aba.txt"),
+		}).Payload))
+}
+
+const split_dataset_name = "test_split_dataset"
+
+func testSplit(t *testing.T) {
+	splitVersion := func(splits []model.Split) []byte {
+		return []byte(ToJson(model.Version{
+			Files:        filelist2,
+			Rows:         200,
+			CreationTime: "2021-01-02T00:00:00Z",
+			ChangeLog:    "Splits",
+			Splits:       splits,
+		}))
+	}
+
+	fmt.Printf("\n1: CreateDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(split_dataset_name),
+		}).Payload))
+
+	fmt.Printf("\n2: AddDatasetVersion [failed] (split file not in version)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(split_dataset_name),
+			splitVersion([]model.Split{{Name: "train", Files: []string{"missing.txt"}}}),
+		}).Payload))
+
+	fmt.Printf("\n3: AddDatasetVersion [failed] (duplicate split name)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(split_dataset_name),
+			splitVersion([]model.Split{
+				{Name: "train", Files: []string{"aba.txt"}},
+				{Name: "train", Files: []string{"aba2.txt"}},
+			}),
+		}).Payload))
+
+	fmt.Printf("\n4: AddDatasetVersion [failed] (file in two splits)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(split_dataset_name),
+			splitVersion([]model.Split{
+				{Name: "train", Files: []string{"aba.txt", "aba2.txt"}},
+				{Name: "test", Files: []string{"aba2.txt"}},
+			}),
+		}).Payload))
+
+	fmt.Printf("\n5: AddDatasetVersion [failed] (invalid split name)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(split_dataset_name),
+			splitVersion([]model.Split{{Name: "train/1", Files: []string{"aba.txt"}}}),
+		}).Payload))
+
+	fmt.Printf("\n6: AddDatasetVersion [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(split_dataset_name),
+			splitVersion([]model.Split{
+				{Name: "train", Files: []string{"aba.txt", "aba2.txt"}},
+				{Name: "validation", Files: []string{"aba3.txt"}},
+				{Name: "test", Files: []string{"aba4.txt"}},
+			}),
+		}).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDataset"),
+		[]byte(dataset_owner),
+		[]byte(split_dataset_name),
+	})
+	var dataset model.Dataset
+	if err := json.Unmarshal(res.Payload, &dataset); err != nil || len(dataset.Versions) != 1 || len(dataset.Versions[0].Splits) != 3 {
+		t.Fatalf("QueryDataset failed: %s", string(res.Payload))
+	}
+	fmt.Printf("\n7: QueryDataset [success] (splits stored)\n%s", string(res.Payload))
+}
+
+const file_metadata_dataset_name = "test_file_metadata_dataset"
+
+func testFileMetadata(t *testing.T) {
+	metadataVersion := func(file model.DatasetFile) []byte {
+		return []byte(ToJson(model.Version{
+			Files:        []model.DatasetFile{file},
+			Rows:         100,
+			CreationTime: "2021-01-02T00:00:00Z",
+			ChangeLog:    "File metadata",
+		}))
+	}
+	file := model.DatasetFile{
+		Hash:        sha256_a,
+		FileName:    "train.csv",
+		ContentType: "text/csv",
+		Description: "Training data",
+		Attributes:  map[string]string{"split": "train", "language": "en"},
+	}
+
+	fmt.Printf("\n1: CreateDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(file_metadata_dataset_name),
+		}).Payload))
+
+	invalidType := file
+	invalidType.ContentType = "text csv"
+	fmt.Printf("\n2: AddDatasetVersion [failed] (invalid content type)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(file_metadata_dataset_name),
+			metadataVersion(invalidType),
+		}).Payload))
+
+	invalidType.ContentType = strings.Repeat("a", 64) + "/" + strings.Repeat("b", 64)
+	fmt.Printf("\n2: AddDatasetVersion [failed] (content type longer than 128 characters)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(file_metadata_dataset_name),
+			metadataVersion(invalidType),
+		}).Payload))
+
+	invalidKey := file
+	invalidKey.Attributes = map[string]string{"bad key": "value"}
+	fmt.Printf("\n3: AddDatasetVersion [failed] (invalid attribute key)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(file_metadata_dataset_name),
+			metadataVersion(invalidKey),
+		}).Payload))
+
+	longDescription := file
+	longDescription.Description = strings.Repeat("a", 1025)
+	fmt.Printf("\n4: AddDatasetVersion [failed] (description too long)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(file_metadata_dataset_name),
+			metadataVersion(longDescription),
+		}).Payload))
+
+	fmt.Printf("\n5: AddDatasetVersion [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(file_metadata_dataset_name),
+			metadataVersion(file),
+		}).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDataset"),
+		[]byte(dataset_owner),
+		[]byte(file_metadata_dataset_name),
+	})
+	var dataset model.Dataset
+	if err := json.Unmarshal(res.Payload, &dataset); err != nil || len(dataset.Versions) != 1 {
+		t.Fatalf("QueryDataset failed: %s", string(res.Payload))
+	}
+	stored := dataset.Versions[0].Files[0]
+	if stored.ContentType != file.ContentType || stored.Description != file.Description || stored.Attributes["language"] != "en" {
+		t.Fatalf("File metadata not stored: %s", string(res.Payload))
+	}
+	fmt.Printf("\n6: QueryDataset [success] (file metadata stored)\n%s", string(res.Payload))
+}
+
+const directory_dataset_name = "test_directory_dataset"
+
+func testDirectory(t *testing.T) {
+	directoryVersion := func(names ...string) []byte {
+		var files []model.DatasetFile
+		for i, name := range names {
+			files = append(files, model.DatasetFile{Hash: []string{sha256_a, sha256_b, sha256_c, sha256_d}[i%4], FileName: name})
+		}
+		return []byte(ToJson(model.Version{
+			Files:        files,
+			Rows:         100,
+			CreationTime: "2021-01-02T00:00:00Z",
+			ChangeLog:    "Directories",
+		}))
+	}
+
+	fmt.Printf("\n1: CreateDataset [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("createDataset"),
+			[]byte(dataset_owner),
+			[]byte(directory_dataset_name),
+		}).Payload))
+
+	invalid := []struct {
+		reason string
+		names  []string
+	}{
+		{"parent directory", []string{"../a.csv"}},
+		{"absolute path", []string{"/data/a.csv"}},
+		{"empty segment", []string{"data//a.csv"}},
+		{"current directory", []string{"data/./a.csv"}},
+		{"trailing slash", []string{"data/"}},
+		{"segment too long", []string{"data/" + strings.Repeat("a", 65)}},
+		{"duplicate name", []string{"data/a.csv", "data/a.csv"}},
+		{"file used as directory", []string{"data", "data/a.csv"}},
+	}
+	for i, c := range invalid {
+		fmt.Printf("\n%d: AddDatasetVersion [failed] (%s)\n%s", i+2, c.reason,
+			string(checkInvoke(t, stub, false, [][]byte{
+				[]byte("addDatasetVersion"),
+				[]byte(dataset_owner),
+				[]byte(directory_dataset_name),
+				directoryVersion(c.names...),
+			}).Payload))
+	}
+
+	fmt.Printf("\n%d: AddDatasetVersion [success]\n%s", len(invalid)+2,
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(directory_dataset_name),
+			directoryVersion("README.md", "data/train/a.csv", "data/test/b.csv", "data/test/c.csv"),
+		}).Payload))
+
+	res := checkInvoke(t, stub, true, [][]byte{
+		[]byte("queryDataset"),
+		[]byte(dataset_owner),
+		[]byte(directory_dataset_name),
+	})
+	var dataset model.Dataset
+	if err := json.Unmarshal(res.Payload, &dataset); err != nil || len(dataset.Versions) != 1 || dataset.Versions[0].Readme != sha256_a {
+		t.Fatalf("README.md should be used as readme: %s", string(res.Payload))
+	}
+	fmt.Printf("\n%d: QueryDataset [success] (readme detected)\n%s", len(invalid)+3, dataset.Versions[0].Readme)
+
+	var version model.Version
+	json.Unmarshal(directoryVersion("data/a.csv"), &version)
+	version.Readme = sha256_b
+	fmt.Printf("\n%d: AddDatasetVersion [failed] (readme not in version)\n%s", len(invalid)+4,
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(directory_dataset_name),
+			[]byte(ToJson(version)),
+		}).Payload))
+
+	json.Unmarshal(directoryVersion("data/a.csv", "docs/intro.md"), &version)
+	version.Readme = sha256_b
+	fmt.Printf("\n%d: AddDatasetVersion [success] (readme referenced by hash)\n%s", len(invalid)+5,
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("addDatasetVersion"),
+			[]byte(dataset_owner),
+			[]byte(directory_dataset_name),
+			[]byte(ToJson(version)),
+		}).Payload))
+}
+
+const event_dataset_name = "test_event_dataset"
+
+// expectEvent 检查上一次调用设置的链码事件
+func expectEvent(t *testing.T, name string, version int, license string) {
+	events := drainEvents(stub)
+	if name == "" {
+		if len(events) != 0 {
+			t.Fatalf("Unexpected event: %s", events[0].EventName)
+		}
+		return
+	}
+	if len(events) != 1 || events[0].EventName != name {
+		t.Fatalf("Expected event %s, got %d events", name, len(events))
+	}
+	var event model.DatasetEvent
+	if err := json.Unmarshal(events[0].Payload, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Owner != dataset_owner || event.Name != event_dataset_name ||
+		event.Version != version || event.License != license {
+		t.Fatalf("Unexpected event payload: %s", string(events[0].Payload))
+	}
+	if _, err := time.Parse(time.RFC3339, event.Time); err != nil {
+		t.Fatalf("Event time should be RFC 3339: %s", event.Time)
+	}
+	fmt.Printf("\n%s\n%s", events[0].EventName, string(events[0].Payload))
+}
+
+func testEvent(t *testing.T) {
+	setLicense := func(license string) [][]byte {
+		return [][]byte{
+			[]byte("setDatasetLicense"),
+			[]byte(dataset_owner),
+			[]byte(event_dataset_name),
+			[]byte(dataset_owner),
+			[]byte(license),
+			[]byte("false"),
+		}
+	}
+
+	fmt.Printf("\n0: CreateDataset [success] (sets dataset-created)")
+	checkInvoke(t, stub, true, [][]byte{
+		[]byte("createDataset"),
+		[]byte(dataset_owner),
+		[]byte(event_dataset_name),
+	})
+	expectEvent(t, model.EventDatasetCreated, 0, "")
+
+	fmt.Printf("\n1: AddDatasetVersion [success] (sets dataset-version-added)")
+	checkInvoke(t, stub, true, [][]byte{
+		[]byte("addDatasetVersion"),
+		[]byte(dataset_owner),
+		[]byte(event_dataset_name),
+		ToJson(model.Version{Files: filelist1, ChangeLog: "v1"}),
+	})
+	expectEvent(t, model.EventDatasetVersionAdded, 1, "")
+
+	fmt.Printf("\n2: SetDatasetLicense [success] (sets dataset-license-changed)")
+	checkInvoke(t, stub, true, setLicense("MIT"))
+	expectEvent(t, model.EventDatasetLicenseChanged, 1, "MIT")
+
+	fmt.Printf("\n3: SetDatasetLicense [success] (license unchanged, no event)")
+	checkInvoke(t, stub, true, setLicense("MIT"))
+	expectEvent(t, "", 0, "")
+
+	fmt.Printf("\n4: CreateRecord [success] (sets records-created)")
+	invokeTx(t, "tx_event_record", true, [][]byte{
+		[]byte("createRecord"),
+		[]byte(dataset_owner),
+		[]byte(event_dataset_name),
+		[]byte(downloader),
+		ToJson(filelist1),
+		[]byte(""),
+	})
+	events := drainEvents(stub)
+	if len(events) != 1 || events[0].EventName != model.EventRecordsCreated {
+		t.Fatalf("Expected event %s, got %d events", model.EventRecordsCreated, len(events))
+	}
+	var records []model.Record
+	if err := json.Unmarshal(events[0].Payload, &records); err != nil || len(records) != 1 ||
+		records[0].ID != "tx_event_record" || records[0].License != "MIT" {
+		t.Fatalf("Unexpected event payload: %s", string(events[0].Payload))
+	}
+	fmt.Printf("\n%s\n%s", events[0].EventName, string(events[0].Payload))
+
+	fmt.Printf("\n5: DeleteDataset [success] (sets dataset-deleted)")
+	checkInvoke(t, stub, true, [][]byte{
+		[]byte("deleteDataset"),
+		[]byte(dataset_owner),
+		[]byte(event_dataset_name),
+	})
+	expectEvent(t, model.EventDatasetDeleted, 1, "MIT")
+}
+
+func testAttestation(t *testing.T) {
+	attester := downloader
+	attest := func(user, version, claim, result string) [][]byte {
+		return [][]byte{
+			[]byte("createAttestation"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+			[]byte(version),
+			[]byte(user),
+			[]byte(claim),
+			[]byte(result),
+			[]byte(sha256_c),
+			[]byte("automated scan"),
+		}
+	}
+	queryAttestations := func() []model.Attestation {
+		res := checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryAttestationsByVersion"),
+			[]byte(dataset_owner),
+			[]byte(merkle_dataset_name),
+			[]byte("0"),
+		})
+		var attestations []model.Attestation
+		if err := json.Unmarshal(res.Payload, &attestations); err != nil {
+			t.Fatal(err)
+		}
+		return attestations
+	}
+
+	fmt.Printf("\n1: CreateAttestation [failed] (not an attester)\n%s",
+		string(checkInvoke(t, stub, false, attest(attester, "0", "pii-scan", model.AttestationPass)).Payload))
+
+	fmt.Printf("\n2: GrantAttester [failed] (operator is not the admin)\n%s",
+		string(checkInvoke(t, stub, false, [][]byte{
+			[]byte("grantAttester"),
+			[]byte(dataset_owner),
+			[]byte(attester),
+		}).Payload))
+
+	fmt.Printf("\n3: GrantAttester [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("grantAttester"),
+			[]byte(model.AdminUser),
+			[]byte(attester),
+		}).Payload))
+
+	fmt.Printf("\n4: QueryAttesters [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("queryAttesters"),
+		}).Payload))
+
+	fmt.Printf("\n5: CreateAttestation [failed] (version does not exist)\n%s",
+		string(checkInvoke(t, stub, false, attest(attester, "99", "pii-scan", model.AttestationPass)).Payload))
+
+	fmt.Printf("\n6: CreateAttestation [failed] (invalid claim)\n%s",
+		string(checkInvoke(t, stub, false, attest(attester, "0", "PII Scan", model.AttestationPass)).Payload))
+
+	fmt.Printf("\n7: CreateAttestation [failed] (invalid result)\n%s",
+		string(checkInvoke(t, stub, false, attest(attester, "0", "pii-scan", "ok")).Payload))
+
+	fmt.Printf("\n8: CreateAttestation [success]\n%s",
+		string(checkInvoke(t, stub, true, attest(attester, "0", "pii-scan", model.AttestationFail)).Payload))
+
+	fmt.Printf("\n9: CreateAttestation [success] (re-attesting replaces the result)\n%s",
+		string(checkInvoke(t, stub, true, attest(attester, "0", "pii-scan", model.AttestationPass)).Payload))
+
+	fmt.Printf("\n10: CreateAttestation [success] (another claim)\n%s",
+		string(checkInvoke(t, stub, true, attest(attester, "0", "schema-v3", model.AttestationWarn)).Payload))
+
+	attestations := queryAttestations()
+	if len(attestations) != 2 {
+		t.Fatalf("Expected 2 attestations, got %d", len(attestations))
+	}
+	for _, attestation := range attestations {
+		if attestation.Attester != attester || attestation.MerkleRoot == "" || attestation.Evidence != sha256_c {
+			t.Fatalf("Unexpected attestation: %+v", attestation)
+		}
+		if attestation.Claim == "pii-scan" && attestation.Result != model.AttestationPass {
+			t.Fatalf("Re-attesting should replace the result: %+v", attestation)
+		}
+	}
+	fmt.Printf("\n11: QueryAttestationsByVersion [success]\n%s", string(ToJson(attestations)))
+
+	fmt.Printf("\n12: RevokeAttester [success]\n%s",
+		string(checkInvoke(t, stub, true, [][]byte{
+			[]byte("revokeAttester"),
+			[]byte(model.AdminUser),
+			[]byte(attester),
+		}).Payload))
+
+	fmt.Printf("\n13: CreateAttestation [failed] (attester role revoked)\n%s",
+		string(checkInvoke(t, stub, false, attest(attester, "0", "pii-scan", model.AttestationPass)).Payload))
+
+	if len(queryAttestations()) != 2 {
+		t.Fatal("Revoking the attester role should keep existing attestations")
+	}
 }
 
 func TestGenshin(t *testing.T) {
@@ -395,6 +1534,16 @@ func TestGenshin(t *testing.T) {
 	t.Run("File", testFile)
 	t.Run("Dataset", testDataset)
 	t.Run("Record", testRecord)
+	t.Run("RecordBatch", testRecordBatch)
+	t.Run("Organization", testOrganization)
+	t.Run("Access", testAccess)
+	t.Run("License", testLicense)
+	t.Run("Merkle", testMerkle)
+	t.Run("Split", testSplit)
+	t.Run("FileMetadata", testFileMetadata)
+	t.Run("Directory", testDirectory)
+	t.Run("Event", testEvent)
+	t.Run("Attestation", testAttestation)
 }
 
 func TestMain(m *testing.M) {
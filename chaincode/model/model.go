@@ -6,48 +6,195 @@ type User struct {
 	Name string `json:"name"` // 用户名
 }
 
+// Member 组织成员
+type Member struct {
+	User string `json:"user"` // 用户ID
+	Role string `json:"role"` // 角色 (owner/admin/member)
+}
+
+// Organization 组织，与用户共享数据集所有者命名空间
+type Organization struct {
+	ID      string   `json:"id"`      // 组织ID
+	Name    string   `json:"name"`    // 组织名
+	Members []Member `json:"members"` // 成员列表
+}
+
 // File 文件
 type File struct {
 	Hash           string `json:"hash"`            // 文件哈希 (key)
 	Size           int64  `json:"size"`            // 文件大小
 	ReferenceCount int32  `json:"reference_count"` // 引用计数
+	Uploader       string `json:"uploader"`        // 上传者ID，由调用方声明，链码只校验用户存在
+	UploadTime     string `json:"upload_time"`     // 上传时间 (交易时间)
+
+	Submitter string `json:"submitter,omitempty"` // 提交交易的客户端证书身份 (MSP ID/证书 ID)，由链码从交易取得
 }
 
+// Unknown 早期文件记录没有上传者与上传时间，读取时以此填充
+const Unknown = "unknown"
+
+// MaxRecordBatch 单次批量写入下载记录的数量上限
+const MaxRecordBatch = 256
+
 // DatasetFile 数据集文件
 type DatasetFile struct {
 	Hash     string `json:"hash"`     // 文件哈希
 	FileName string `json:"filename"` // 文件名
+
+	ContentType string            `json:"content_type,omitempty"` // MIME 类型
+	Description string            `json:"description,omitempty"`  // 文件说明
+	Attributes  map[string]string `json:"attributes,omitempty"`   // 自定义属性 (如 language)
 }
 
 // Version 数据集的一个版本
 type Version struct {
-	Files        []DatasetFile `json:"files"`         // 文件列表
-	Rows         int32         `json:"rows"`          // 行数
-	CreationTime string        `json:"creation_time"` // 创建时间
-	ChangeLog    string        `json:"change_log"`    // 版本说明
+	Files        []DatasetFile `json:"files"`            // 文件列表
+	Rows         int32         `json:"rows"`             // 行数
+	CreationTime string        `json:"creation_time"`    // 创建时间
+	ChangeLog    string        `json:"change_log"`       // 版本说明
+	MerkleRoot   string        `json:"merkle_root"`      // 按文件名排序的 (文件名, 哈希) 默克尔根
+	Splits       []Split       `json:"splits,omitempty"` // 数据划分 (如 train/validation/test)
+	Readme       string        `json:"readme,omitempty"` // 说明文档 (Markdown) 的文件哈希
+}
+
+// ReadmeFileName 版本根目录下作为说明文档的文件名
+const ReadmeFileName = "README.md"
+
+// Split 数据划分，将版本中的部分文件命名为一个子集
+type Split struct {
+	Name  string   `json:"name"`  // 划分名
+	Files []string `json:"files"` // 文件名列表，须属于所在版本
 }
 
 // Dataset 数据集
 type Dataset struct {
-	Owner    string    `json:"owner"`    // 所有者ID
+	Owner    string    `json:"owner"`    // 所有者ID (用户或组织)
 	Name     string    `json:"name"`     // 数据集名
 	Versions []Version `json:"versions"` // 版本列表
 	Deleted  bool      `json:"deleted"`  // 已删除
+	Gated    bool      `json:"gated"`    // 受限访问，下载前需申请
+
+	License        string `json:"license"`         // 许可证 (SPDX 标识符)
+	LicenseVersion int32  `json:"license_version"` // 许可证条款版本，每次变更许可证时递增
+	RequireLicense bool   `json:"require_license"` // 下载前需明确接受许可证
 }
 
 // Record 下载记录
 type Record struct {
+	ID           string        `json:"id,omitempty"`  // 记录ID (交易ID，批量写入时附加序号)，早期记录为空
 	DatasetOwner string        `json:"dataset_owner"` // 数据集所有者
 	DatasetName  string        `json:"dataset_name"`  // 数据集名
 	User         string        `json:"user"`          // 下载者ID
 	Files        []DatasetFile `json:"files"`         // 文件列表
 	Time         string        `json:"time"`          // 下载时间
+
+	License        string `json:"license,omitempty"`         // 下载时数据集的许可证
+	LicenseVersion int32  `json:"license_version,omitempty"` // 下载时的许可证条款版本
+
+	EventID string `json:"event_id,omitempty"` // 客户端的下载事件ID，同一事件重复提交时不再写入
+}
+
+// DatasetEvent 数据集变更的链码事件负载
+type DatasetEvent struct {
+	Owner   string `json:"owner"`   // 数据集所有者
+	Name    string `json:"name"`    // 数据集名
+	Version int    `json:"version"` // 变更后的版本数量
+	License string `json:"license"` // 变更后的许可证
+	Time    string `json:"time"`    // 交易时间
 }
 
+// 链码事件名，Fabric 每笔交易只保留最后设置的一个事件
+const (
+	EventDatasetCreated        = "dataset-created"
+	EventDatasetVersionAdded   = "dataset-version-added"
+	EventDatasetDeleted        = "dataset-deleted"
+	EventDatasetLicenseChanged = "dataset-license-changed"
+	EventRecordsCreated        = "records-created" // 负载为 []Record
+)
+
+// LicenseAcceptance 用户对数据集许可证的接受记录
+type LicenseAcceptance struct {
+	DatasetOwner   string `json:"dataset_owner"`   // 数据集所有者
+	DatasetName    string `json:"dataset_name"`    // 数据集名
+	User           string `json:"user"`            // 用户ID
+	License        string `json:"license"`         // 接受的许可证 (SPDX 标识符)
+	LicenseVersion int32  `json:"license_version"` // 接受的许可证条款版本
+	Time           string `json:"time"`            // 接受时间
+}
+
+// Attester 持有认证者角色的用户，可对数据集版本作出认证
+type Attester struct {
+	User      string `json:"user"`       // 用户ID
+	GrantedBy string `json:"granted_by"` // 授予者ID
+	Time      string `json:"time"`       // 授予时间
+}
+
+// Attestation 第三方对数据集版本某项属性的认证
+// 同一认证者对同一版本的同一认证类型只保留最新结果
+type Attestation struct {
+	DatasetOwner string `json:"dataset_owner"`     // 数据集所有者
+	DatasetName  string `json:"dataset_name"`      // 数据集名
+	Version      int    `json:"version"`           // 版本序号，从 0 开始
+	MerkleRoot   string `json:"merkle_root"`       // 认证时版本的默克尔根
+	Attester     string `json:"attester"`          // 认证者ID
+	Claim        string `json:"claim"`             // 认证类型 (如 pii-scan、schema-v3)
+	Result       string `json:"result"`            // 结果 (pass/warn/fail)
+	Evidence     string `json:"evidence"`          // 证据 (如检查报告) 的 SHA-256 哈希
+	Details      string `json:"details,omitempty"` // 说明
+	Time         string `json:"time"`              // 认证时间 (交易时间)
+}
+
+const (
+	AttestationPass = "pass" // 通过
+	AttestationWarn = "warn" // 通过但有警告
+	AttestationFail = "fail" // 未通过
+)
+
+// AdminUser 平台管理员，负责授予和撤销认证者角色
+// 该用户在链码初始化时创建，不能再被注册；服务端只在令牌保护的管理接口中以该身份调用
+const AdminUser = "admin"
+
+// AccessRequest 受限数据集的访问申请
+type AccessRequest struct {
+	DatasetOwner  string `json:"dataset_owner"` // 数据集所有者
+	DatasetName   string `json:"dataset_name"`  // 数据集名
+	User          string `json:"user"`          // 申请者ID
+	Justification string `json:"justification"` // 申请理由
+	Status        string `json:"status"`        // 状态 (pending/approved/denied)
+	RequestTime   string `json:"request_time"`  // 申请时间
+	Reviewer      string `json:"reviewer"`      // 审批者ID
+	Comment       string `json:"comment"`       // 审批意见
+	DecisionTime  string `json:"decision_time"` // 审批时间
+}
+
+const (
+	AccessPending  = "pending"  // 待审批
+	AccessApproved = "approved" // 已批准
+	AccessDenied   = "denied"   // 已拒绝 (或撤销)
+)
+
+const (
+	RoleOwner  = "owner"  // 组织所有者: 管理全部成员
+	RoleAdmin  = "admin"  // 组织管理员: 管理普通成员
+	RoleMember = "member" // 组织成员: 可在组织命名空间下创建数据集
+)
+
 const (
 	UserKey          = "user"
+	OrganizationKey  = "organization"
 	FileKey          = "file"
+	FileUploaderKey  = "file-uploader"
 	DatasetKey       = "dataset"
 	RecordUserKey    = "record-user"
 	RecordDatasetKey = "record-dataset"
+	RecordEventKey   = "record-event"
+
+	AccessRequestUserKey    = "access-request-user"
+	AccessRequestDatasetKey = "access-request-dataset"
+
+	LicenseAcceptanceUserKey    = "license-acceptance-user"
+	LicenseAcceptanceDatasetKey = "license-acceptance-dataset"
+
+	AttesterKey    = "attester"
+	AttestationKey = "attestation"
 )
@@ -3,6 +3,8 @@ package model
 import (
 	"chaincode/pkg/utils"
 	"errors"
+	"fmt"
+	"strings"
 )
 
 func ValidateUser(user User) error {
@@ -26,6 +28,62 @@ func ValidateUser(user User) error {
 	return nil
 }
 
+func ValidateMember(member Member) error {
+	// User ID: 3-16 characters, only letters, numbers, and underscores
+	// Role: owner, admin or member
+
+	if !utils.ValidateLength(member.User, 3, 16) {
+		return errors.New("Member ID must be between 3 and 16 characters")
+	}
+	if !utils.ValidateName(member.User) {
+		return errors.New("Member ID must contain only letters, numbers, and underscores")
+	}
+	if member.Role != RoleOwner && member.Role != RoleAdmin && member.Role != RoleMember {
+		return errors.New("Member Role must be one of owner, admin and member")
+	}
+
+	return nil
+}
+
+func ValidateOrganization(organization Organization) error {
+	// Organization ID: 3-16 characters, only letters, numbers, and underscores
+	// Organization Name: 3-16 characters, only letters, numbers, and underscores
+	// Members: list of Member [unique users, at least one owner]
+
+	if !utils.ValidateLength(organization.ID, 3, 16) {
+		return errors.New("Organization ID must be between 3 and 16 characters")
+	}
+	if !utils.ValidateLength(organization.Name, 3, 16) {
+		return errors.New("Organization Name must be between 3 and 16 characters")
+	}
+	if !utils.ValidateName(organization.ID) {
+		return errors.New("Organization ID must contain only letters, numbers, and underscores")
+	}
+	if !utils.ValidateName(organization.Name) {
+		return errors.New("Organization Name must contain only letters, numbers, and underscores")
+	}
+
+	owners := 0
+	userMp := make(map[string]bool)
+	for _, member := range organization.Members {
+		if err := ValidateMember(member); err != nil {
+			return err
+		}
+		if userMp[member.User] {
+			return errors.New("Organization Members must be unique")
+		}
+		userMp[member.User] = true
+		if member.Role == RoleOwner {
+			owners++
+		}
+	}
+	if owners == 0 {
+		return errors.New("Organization must have at least one owner")
+	}
+
+	return nil
+}
+
 func ValidateFile(file File) error {
 	// File Size:  0-2GB as Bytes
 	// File Hash: SHA-256
@@ -41,32 +99,123 @@ func ValidateFile(file File) error {
 }
 
 func ValidateDatasetFile(datasetFile DatasetFile) error {
-	// File Name: 1-64 characters [not contain invalid characters]
+	// File Name: relative path of at most 16 segments separated by "/", 1-255 characters
+	//   Segment: 1-64 characters [not contain invalid characters], not "." or ".."
 	// File Hash: SHA-256
+	// Content Type: optional MIME type, at most 128 characters
+	// Description: 0-1024 characters
+	// Attributes: at most 32, key 1-64 characters [letters, numbers, _ . -], value 0-256 characters
 
-	if !utils.ValidateLength(datasetFile.FileName, 1, 64) {
-		return errors.New("File Name must be between 1 and 64 characters")
+	if !utils.ValidateLength(datasetFile.FileName, 1, 255) {
+		return errors.New("File Name must be between 1 and 255 characters")
+	}
+	segments := strings.Split(datasetFile.FileName, "/")
+	if len(segments) > 16 {
+		return errors.New("File Name must have at most 16 path segments")
 	}
-	if !utils.ValidateFileName(datasetFile.FileName) {
-		return errors.New("File Name must not contain invalid characters")
+	for _, segment := range segments {
+		if !utils.ValidateLength(segment, 1, 64) {
+			return errors.New("File Name path segments must be between 1 and 64 characters")
+		}
+		if segment == "." || segment == ".." {
+			return errors.New("File Name must be a normalized relative path")
+		}
+		if !utils.ValidateFileName(segment) {
+			return errors.New("File Name must not contain invalid characters")
+		}
 	}
 	if !utils.ValidateSHA256(datasetFile.Hash) {
 		return errors.New("File Hash must be a SHA-256 hash")
 	}
+	if datasetFile.ContentType != "" && !utils.ValidateContentType(datasetFile.ContentType) {
+		return errors.New("File Content Type must be a MIME type of at most 128 characters")
+	}
+	if !utils.ValidateLength(datasetFile.Description, 0, 1024) {
+		return errors.New("File Description must be between 0 and 1024 characters")
+	}
+	if len(datasetFile.Attributes) > 32 {
+		return errors.New("File must have at most 32 attributes")
+	}
+	for key, value := range datasetFile.Attributes {
+		if !utils.ValidateAttributeKey(key) {
+			return fmt.Errorf("File Attribute Key must be 1-64 letters, numbers, underscores, dots or hyphens: %s", key)
+		}
+		if !utils.ValidateLength(value, 0, 256) {
+			return fmt.Errorf("File Attribute %s must be between 0 and 256 characters", key)
+		}
+	}
+
+	return nil
+}
+
+func ValidateSplits(version Version) error {
+	// Splits: at most 16, unique names
+	// Split Name: 1-32 characters, only letters, numbers, and underscores
+	// Split Files: non-empty, each file in the version, no file in more than one split
+
+	if len(version.Splits) > 16 {
+		return errors.New("Version must have at most 16 splits")
+	}
+
+	fileNames := make(map[string]bool, len(version.Files))
+	for _, file := range version.Files {
+		fileNames[file.FileName] = true
+	}
+	splitNames := make(map[string]bool, len(version.Splits))
+	assigned := make(map[string]string)
+	for _, split := range version.Splits {
+		if !utils.ValidateLength(split.Name, 1, 32) {
+			return errors.New("Split Name must be between 1 and 32 characters")
+		}
+		if !utils.ValidateName(split.Name) {
+			return errors.New("Split Name must contain only letters, numbers, and underscores")
+		}
+		if splitNames[split.Name] {
+			return fmt.Errorf("Split Name must be unique: %s", split.Name)
+		}
+		splitNames[split.Name] = true
+
+		if len(split.Files) == 0 {
+			return fmt.Errorf("Split %s must contain at least one file", split.Name)
+		}
+		for _, fileName := range split.Files {
+			if !fileNames[fileName] {
+				return fmt.Errorf("Split %s refers to a file not in the version: %s", split.Name, fileName)
+			}
+			if other, ok := assigned[fileName]; ok {
+				return fmt.Errorf("File %s must not be in both split %s and %s", fileName, other, split.Name)
+			}
+			assigned[fileName] = split.Name
+		}
+	}
 
 	return nil
 }
 
 func ValidateVersion(version Version) error {
-	// Files: list of DatasetFile
+	// Files: list of DatasetFile, unique names, no file used as a directory
+	// Readme: optional SHA-256
 	// Rows: non-negative integer
 	// Creation Time: ISO 8601
 	// Change Log: 0-1024 characters
 
+	paths := make(map[string]bool, len(version.Files))
 	for _, file := range version.Files {
 		if err := ValidateDatasetFile(file); err != nil {
 			return err
 		}
+		if paths[file.FileName] {
+			return fmt.Errorf("File Name must be unique in a version: %s", file.FileName)
+		}
+		paths[file.FileName] = true
+	}
+	for _, file := range version.Files {
+		// a file must not also be used as a directory, e.g. "a" and "a/b"
+		for i := strings.Index(file.FileName, "/"); i >= 0; i = nextSlash(file.FileName, i) {
+			if paths[file.FileName[:i]] {
+				return fmt.Errorf("File Name conflicts with directory: %s", file.FileName[:i])
+			}
+		}
 	}
 	if version.Rows < 0 {
 		return errors.New("Rows must be a non-negative integer")
@@ -77,6 +226,15 @@ func ValidateVersion(version Version) error {
 	if !utils.ValidateLength(version.ChangeLog, 0, 1024) {
 		return errors.New("Changelog must be between 0 and 1024 characters")
 	}
+	if version.MerkleRoot != "" && !utils.ValidateSHA256(version.MerkleRoot) {
+		return errors.New("Merkle Root must be a SHA-256 hash")
+	}
+	if version.Readme != "" && !utils.ValidateSHA256(version.Readme) {
+		return errors.New("Readme must be a SHA-256 hash")
+	}
+	if err := ValidateSplits(version); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -103,6 +261,15 @@ func ValidateDataset(dataset Dataset) error {
 			return err
 		}
 	}
+	if dataset.License != "" && !utils.ValidateLicense(dataset.License) {
+		return errors.New("License must be an SPDX license identifier")
+	}
+	if dataset.LicenseVersion < 0 {
+		return errors.New("License Version must be a non-negative integer")
+	}
+	if dataset.RequireLicense && dataset.License == "" {
+		return errors.New("License is required when license acceptance is required")
+	}
 
 	return nil
 }
@@ -113,6 +280,7 @@ func ValidateRecord(record Record) error {
 	// User ID: existing user [3-16 characters, only letters, numbers, and underscores]
 	// Files: list of DatasetFile
 	// Time: ISO 8601
+	// Event ID: optional, 1-64 characters [only letters, numbers, and underscores]
 
 	if !utils.ValidateLength(record.DatasetOwner, 3, 16) {
 		return errors.New("Dataset Owner must be between 3 and 16 characters")
@@ -135,6 +303,12 @@ func ValidateRecord(record Record) error {
 	if !utils.ValidateTime(record.Time) {
 		return errors.New("Time must be an ISO 8601 timestamp")
 	}
+	if record.License != "" && !utils.ValidateLicense(record.License) {
+		return errors.New("License must be an SPDX license identifier")
+	}
+	if record.EventID != "" && (!utils.ValidateLength(record.EventID, 1, 64) || !utils.ValidateName(record.EventID)) {
+		return errors.New("Event ID must be 1-64 letters, numbers, and underscores")
+	}
 	for _, file := range record.Files {
 		if err := ValidateDatasetFile(file); err != nil {
 			return err
@@ -143,3 +317,121 @@ func ValidateRecord(record Record) error {
 
 	return nil
 }
+
+func ValidateAccessRequest(request AccessRequest) error {
+	// Owner ID: existing user or organization [3-16 characters, only letters, numbers, and underscores]
+	// Dataset Name: existing dataset [3-64 characters, only letters, numbers, and underscores]
+	// User ID: existing user [3-16 characters, only letters, numbers, and underscores]
+	// Justification: 1-1024 characters
+	// Status: pending, approved or denied
+	// Request Time: ISO 8601
+	// Comment: 0-1024 characters
+
+	if !utils.ValidateLength(request.DatasetOwner, 3, 16) || !utils.ValidateName(request.DatasetOwner) {
+		return errors.New("Dataset Owner must be 3-16 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLength(request.DatasetName, 3, 64) || !utils.ValidateName(request.DatasetName) {
+		return errors.New("Dataset Name must be 3-64 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLength(request.User, 3, 16) || !utils.ValidateName(request.User) {
+		return errors.New("User ID must be 3-16 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLength(request.Justification, 1, 1024) {
+		return errors.New("Justification must be between 1 and 1024 characters")
+	}
+	if request.Status != AccessPending && request.Status != AccessApproved && request.Status != AccessDenied {
+		return errors.New("Status must be one of pending, approved and denied")
+	}
+	if !utils.ValidateTime(request.RequestTime) {
+		return errors.New("Request Time must be an ISO 8601 timestamp")
+	}
+	if !utils.ValidateLength(request.Comment, 0, 1024) {
+		return errors.New("Comment must be between 0 and 1024 characters")
+	}
+	if request.Status != AccessPending && !utils.ValidateTime(request.DecisionTime) {
+		return errors.New("Decision Time must be an ISO 8601 timestamp")
+	}
+
+	return nil
+}
+
+func ValidateLicenseAcceptance(acceptance LicenseAcceptance) error {
+	// Owner ID: existing user or organization [3-16 characters, only letters, numbers, and underscores]
+	// Dataset Name: existing dataset [3-64 characters, only letters, numbers, and underscores]
+	// User ID: existing user [3-16 characters, only letters, numbers, and underscores]
+	// License: SPDX license identifier
+	// License Version: non-negative integer
+	// Time: ISO 8601
+
+	if !utils.ValidateLength(acceptance.DatasetOwner, 3, 16) || !utils.ValidateName(acceptance.DatasetOwner) {
+		return errors.New("Dataset Owner must be 3-16 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLength(acceptance.DatasetName, 3, 64) || !utils.ValidateName(acceptance.DatasetName) {
+		return errors.New("Dataset Name must be 3-64 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLength(acceptance.User, 3, 16) || !utils.ValidateName(acceptance.User) {
+		return errors.New("User ID must be 3-16 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLicense(acceptance.License) {
+		return errors.New("License must be an SPDX license identifier")
+	}
+	if acceptance.LicenseVersion < 0 {
+		return errors.New("License Version must be a non-negative integer")
+	}
+	if !utils.ValidateTime(acceptance.Time) {
+		return errors.New("Time must be an ISO 8601 timestamp")
+	}
+
+	return nil
+}
+
+func ValidateAttestation(attestation Attestation) error {
+	// Owner ID: existing user or organization [3-16 characters, only letters, numbers, and underscores]
+	// Dataset Name: existing dataset [3-64 characters, only letters, numbers, and underscores]
+	// Version: non-negative integer
+	// Attester: existing user holding the attester role [3-16 characters, only letters, numbers, and underscores]
+	// Claim: 1-64 lowercase letters, numbers, '.', '_' and '-'
+	// Result: pass, warn or fail
+	// Evidence: SHA-256
+	// Details: 0-1024 characters
+	// Time: ISO 8601
+
+	if !utils.ValidateLength(attestation.DatasetOwner, 3, 16) || !utils.ValidateName(attestation.DatasetOwner) {
+		return errors.New("Dataset Owner must be 3-16 letters, numbers, and underscores")
+	}
+	if !utils.ValidateLength(attestation.DatasetName, 3, 64) || !utils.ValidateName(attestation.DatasetName) {
+		return errors.New("Dataset Name must be 3-64 letters, numbers, and underscores")
+	}
+	if attestation.Version < 0 {
+		return errors.New("Version must be a non-negative integer")
+	}
+	if !utils.ValidateLength(attestation.Attester, 3, 16) || !utils.ValidateName(attestation.Attester) {
+		return errors.New("Attester must be 3-16 letters, numbers, and underscores")
+	}
+	if !utils.ValidateClaim(attestation.Claim) {
+		return errors.New("Claim must be 1-64 lowercase letters, numbers, '.', '_' and '-'")
+	}
+	if attestation.Result != AttestationPass && attestation.Result != AttestationWarn && attestation.Result != AttestationFail {
+		return errors.New("Result must be one of pass, warn and fail")
+	}
+	if !utils.ValidateSHA256(attestation.Evidence) {
+		return errors.New("Evidence must be a SHA-256 hash")
+	}
+	if !utils.ValidateLength(attestation.Details, 0, 1024) {
+		return errors.New("Details must be at most 1024 characters")
+	}
+	if !utils.ValidateTime(attestation.Time) {
+		return errors.New("Time must be an ISO 8601 timestamp")
+	}
+
+	return nil
+}
+
+// nextSlash returns the index of the next "/" after i, or -1
+func nextSlash(path string, i int) int {
+	j := strings.Index(path[i+1:], "/")
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
@@ -0,0 +1,164 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sort"
+)
+
+// Entry 叶子节点对应的数据集文件
+type Entry struct {
+	FileName string `json:"filename"` // 文件名
+	Hash     string `json:"hash"`     // 文件哈希 (SHA-256 hex)
+}
+
+// Step 证明路径上的一个兄弟节点
+type Step struct {
+	Hash string `json:"hash"` // 兄弟节点哈希 (hex)
+	Left bool   `json:"left"` // 兄弟节点是否在左侧
+}
+
+// Proof 文件包含证明
+type Proof struct {
+	Root  string `json:"root"`  // 默克尔根 (hex)
+	Entry Entry  `json:"entry"` // 被证明的文件
+	Index int    `json:"index"` // 叶子在排序后列表中的位置
+	Steps []Step `json:"steps"` // 自底向上的兄弟节点
+}
+
+// LeafHash 叶子哈希: SHA-256(0x00 || len(filename) || filename || hash)
+// 文件名带长度前缀，避免不同 (文件名, 哈希) 组合产生相同编码
+func LeafHash(entry Entry) ([]byte, error) {
+	fileHash, err := hex.DecodeString(entry.Hash)
+	if err != nil || len(fileHash) != sha256.Size {
+		return nil, errors.New("文件哈希必须是 SHA-256")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(0x00)
+	binary.Write(&buf, binary.BigEndian, uint32(len(entry.FileName)))
+	buf.WriteString(entry.FileName)
+	buf.Write(fileHash)
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:], nil
+}
+
+// nodeHash 内部节点哈希: SHA-256(0x01 || left || right)
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// sortEntries 按文件名排序 (返回副本)
+func sortEntries(entries []Entry) []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FileName < sorted[j].FileName
+	})
+	return sorted
+}
+
+// levels 自底向上构建整棵树，奇数个节点时最后一个节点直接提升到上一层
+func levels(entries []Entry) ([][][]byte, error) {
+	leaves := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		leaf, err := LeafHash(entry)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+
+	tree := [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, nodeHash(level[i], level[i+1]))
+			}
+		}
+		tree = append(tree, next)
+		level = next
+	}
+	return tree, nil
+}
+
+// Root 计算文件列表的默克尔根，空列表返回空字符串
+func Root(entries []Entry) (string, error) {
+	if len(entries) == 0 {
+		return "", nil
+	}
+	tree, err := levels(sortEntries(entries))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tree[len(tree)-1][0]), nil
+}
+
+// BuildProof 为指定文件名生成包含证明
+func BuildProof(entries []Entry, fileName string) (Proof, error) {
+	sorted := sortEntries(entries)
+	index := -1
+	for i, entry := range sorted {
+		if entry.FileName == fileName {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return Proof{}, errors.New("文件不存在: " + fileName)
+	}
+
+	tree, err := levels(sorted)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	proof := Proof{
+		Root:  hex.EncodeToString(tree[len(tree)-1][0]),
+		Entry: sorted[index],
+		Index: index,
+		Steps: []Step{},
+	}
+	pos := index
+	for _, level := range tree[:len(tree)-1] {
+		if pos%2 == 1 {
+			proof.Steps = append(proof.Steps, Step{Hash: hex.EncodeToString(level[pos-1]), Left: true})
+		} else if pos+1 < len(level) {
+			proof.Steps = append(proof.Steps, Step{Hash: hex.EncodeToString(level[pos+1]), Left: false})
+		}
+		pos /= 2
+	}
+	return proof, nil
+}
+
+// Verify 验证包含证明
+func Verify(proof Proof) error {
+	node, err := LeafHash(proof.Entry)
+	if err != nil {
+		return err
+	}
+	for _, step := range proof.Steps {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil || len(sibling) != sha256.Size {
+			return errors.New("证明路径哈希格式错误")
+		}
+		if step.Left {
+			node = nodeHash(sibling, node)
+		} else {
+			node = nodeHash(node, sibling)
+		}
+	}
+	if hex.EncodeToString(node) != proof.Root {
+		return errors.New("默克尔根不匹配")
+	}
+	return nil
+}
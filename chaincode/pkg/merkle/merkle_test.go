@@ -0,0 +1,134 @@
+package merkle
+
+import (
+	"testing"
+)
+
+// 测试向量按 LeafHash 与 nodeHash 注释中的编码独立计算，文件哈希为 SHA-256("a")、SHA-256("b")、SHA-256("c")
+var (
+	entryA = Entry{FileName: "a.csv", Hash: "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"}
+	entryB = Entry{FileName: "b.csv", Hash: "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"}
+	entryC = Entry{FileName: "c.csv", Hash: "2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6"}
+
+	leafA   = "3496b2cbd0f0a17b80987187170dee690955de63fb291be689c24cef3d5e26ec"
+	leafB   = "2fd6bd6bf03c8782e5347bf1bfd9f26a405bd8aa2bc53331fd5dc3ab8e54f319"
+	leafC   = "b43549192f16203e7cfe34063eb1466b5bd9953c61a9571e47e6633352928a80"
+	nodeAB  = "db89a953c9df6939f88a3571787a4ad3f9398110a3dc02a48c09b973a991019f"
+	rootABC = "82bc719dc7df4e26e831990429987d700b951a6728d7ece33271cb87b96ffb04"
+)
+
+func TestRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		root    string
+		err     bool
+	}{
+		{name: "empty", entries: nil, root: ""},
+		{name: "single file", entries: []Entry{entryA}, root: leafA},
+		{name: "two files", entries: []Entry{entryA, entryB}, root: nodeAB},
+		{name: "odd count", entries: []Entry{entryA, entryB, entryC}, root: rootABC},
+		{name: "order independent", entries: []Entry{entryC, entryA, entryB}, root: rootABC},
+		{name: "invalid hash", entries: []Entry{{FileName: "a.csv", Hash: "abc"}}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := Root(tt.entries)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("Root() = %q, want error", root)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root != tt.root {
+				t.Fatalf("Root() = %q, want %q", root, tt.root)
+			}
+		})
+	}
+}
+
+func TestProof(t *testing.T) {
+	all := []Entry{entryC, entryB, entryA}
+	tests := []struct {
+		name    string
+		entries []Entry
+		file    string
+		index   int
+		steps   []Step
+		root    string
+		err     bool
+	}{
+		{name: "empty", entries: nil, file: "a.csv", err: true},
+		{name: "missing file", entries: all, file: "d.csv", err: true},
+		{name: "single file", entries: []Entry{entryA}, file: "a.csv", index: 0, steps: []Step{}, root: leafA},
+		{name: "left leaf", entries: all, file: "a.csv", index: 0,
+			steps: []Step{{Hash: leafB, Left: false}, {Hash: leafC, Left: false}}, root: rootABC},
+		{name: "right leaf", entries: all, file: "b.csv", index: 1,
+			steps: []Step{{Hash: leafA, Left: true}, {Hash: leafC, Left: false}}, root: rootABC},
+		// 奇数个节点时最后一个叶子直接提升，第一层没有兄弟节点
+		{name: "odd count last leaf", entries: all, file: "c.csv", index: 2,
+			steps: []Step{{Hash: nodeAB, Left: true}}, root: rootABC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := BuildProof(tt.entries, tt.file)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("BuildProof() = %+v, want error", proof)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if proof.Root != tt.root || proof.Index != tt.index || proof.Entry.FileName != tt.file {
+				t.Fatalf("BuildProof() = %+v, want root %q index %d", proof, tt.root, tt.index)
+			}
+			if len(proof.Steps) != len(tt.steps) {
+				t.Fatalf("BuildProof() steps = %+v, want %+v", proof.Steps, tt.steps)
+			}
+			for i := range tt.steps {
+				if proof.Steps[i] != tt.steps[i] {
+					t.Fatalf("BuildProof() steps = %+v, want %+v", proof.Steps, tt.steps)
+				}
+			}
+			if err := Verify(proof); err != nil {
+				t.Fatalf("Verify() = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	valid := func() Proof {
+		proof, err := BuildProof([]Entry{entryA, entryB, entryC}, "b.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return proof
+	}
+	tests := []struct {
+		name   string
+		tamper func(*Proof)
+	}{
+		{name: "tampered sibling", tamper: func(p *Proof) { p.Steps[0].Hash = leafB }},
+		{name: "flipped side", tamper: func(p *Proof) { p.Steps[0].Left = !p.Steps[0].Left }},
+		{name: "missing step", tamper: func(p *Proof) { p.Steps = p.Steps[:1] }},
+		{name: "malformed sibling", tamper: func(p *Proof) { p.Steps[0].Hash = "zz" }},
+		{name: "tampered file hash", tamper: func(p *Proof) { p.Entry.Hash = entryC.Hash }},
+		{name: "renamed file", tamper: func(p *Proof) { p.Entry.FileName = "d.csv" }},
+		{name: "wrong root", tamper: func(p *Proof) { p.Root = nodeAB }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := valid()
+			tt.tamper(&proof)
+			if err := Verify(proof); err == nil {
+				t.Fatalf("Verify(%+v) = nil, want error", proof)
+			}
+		})
+	}
+}
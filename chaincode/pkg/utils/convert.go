@@ -26,3 +26,7 @@ func Str2Int64(x string) int64 {
 	res, _ := strconv.ParseInt(x, 10, 64)
 	return res
 }
+func Str2Bool(x string) bool {
+	res, _ := strconv.ParseBool(x)
+	return res
+}
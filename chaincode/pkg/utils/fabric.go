@@ -3,7 +3,9 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 )
 
@@ -141,3 +143,42 @@ func GetStateByKey_Single(stub shim.ChaincodeStubInterface, objectType string, k
 func GetStateByObjectType(stub shim.ChaincodeStubInterface, objectType string) (results [][]byte, err error) {
 	return GetStateByPartialKey(stub, objectType, []string{})
 }
+
+// SetEvent 序列化负载并设置链码事件，交易提交后由客户端订阅
+func SetEvent(stub shim.ChaincodeStubInterface, name string, payload interface{}) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s-序列化json数据失败出错: %s", name, err)
+	}
+	if err := stub.SetEvent(name, bytes); err != nil {
+		return fmt.Errorf("%s-设置链码事件出错: %s", name, err)
+	}
+	return nil
+}
+
+// GetTxTime 返回交易时间 (UTC, RFC 3339)，由客户端提交并经所有背书节点一致确认
+func GetTxTime(stub shim.ChaincodeStubInterface) (string, error) {
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("获取交易时间出错: %s", err)
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339), nil
+}
+
+// GetSubmitter 返回提交交易的客户端身份 "MSP ID/证书 ID"
+// 无法解析身份时 (如测试桩没有提交者证书) 返回空字符串
+func GetSubmitter(stub shim.ChaincodeStubInterface) string {
+	id, err := cid.New(stub)
+	if err != nil {
+		return ""
+	}
+	mspID, err := id.GetMSPID()
+	if err != nil {
+		return ""
+	}
+	certID, err := id.GetID()
+	if err != nil {
+		return ""
+	}
+	return mspID + "/" + certID
+}
@@ -0,0 +1,66 @@
+package utils
+
+// spdxLicenses 链码接受的 SPDX 许可证标识符 (https://spdx.org/licenses/)
+// 与服务端 pkg/license 的列表保持一致，新增许可证时两处同时修改
+var spdxLicenses = map[string]bool{
+	// 公共领域与宽松许可证
+	"0BSD":         true,
+	"Unlicense":    true,
+	"MIT":          true,
+	"MIT-0":        true,
+	"ISC":          true,
+	"BSD-2-Clause": true,
+	"BSD-3-Clause": true,
+	"Apache-2.0":   true,
+	"Zlib":         true,
+	"BSL-1.0":      true,
+	"PSF-2.0":      true,
+	"WTFPL":        true,
+
+	// Copyleft 许可证
+	"GPL-2.0-only":      true,
+	"GPL-2.0-or-later":  true,
+	"GPL-3.0-only":      true,
+	"GPL-3.0-or-later":  true,
+	"LGPL-2.1-only":     true,
+	"LGPL-2.1-or-later": true,
+	"LGPL-3.0-only":     true,
+	"LGPL-3.0-or-later": true,
+	"AGPL-3.0-only":     true,
+	"AGPL-3.0-or-later": true,
+	"MPL-2.0":           true,
+	"EPL-2.0":           true,
+	"EUPL-1.2":          true,
+	"GFDL-1.3-only":     true,
+	"GFDL-1.3-or-later": true,
+
+	// Creative Commons
+	"CC0-1.0":         true,
+	"CC-BY-3.0":       true,
+	"CC-BY-4.0":       true,
+	"CC-BY-SA-3.0":    true,
+	"CC-BY-SA-4.0":    true,
+	"CC-BY-NC-3.0":    true,
+	"CC-BY-NC-4.0":    true,
+	"CC-BY-NC-SA-3.0": true,
+	"CC-BY-NC-SA-4.0": true,
+	"CC-BY-ND-4.0":    true,
+	"CC-BY-NC-ND-3.0": true,
+	"CC-BY-NC-ND-4.0": true,
+	"CC-PDDC":         true,
+
+	// 开放数据许可证
+	"ODbL-1.0":            true,
+	"ODC-By-1.0":          true,
+	"PDDL-1.0":            true,
+	"CDLA-Permissive-1.0": true,
+	"CDLA-Permissive-2.0": true,
+	"CDLA-Sharing-1.0":    true,
+	"C-UDA-1.0":           true,
+	"OGL-UK-3.0":          true,
+	"OGL-Canada-2.0":      true,
+	"etalab-2.0":          true,
+	"DL-DE-BY-2.0":        true,
+	"NLOD-2.0":            true,
+	"O-UDA-1.0":           true,
+}
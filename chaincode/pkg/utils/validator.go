@@ -29,3 +29,15 @@ func ValidateTime(value string) bool {
 func ValidateFileName(value string) bool {
 	return ValidateRegex(value, `^[^<>:;,?"*|/\\]+$`)
 }
+func ValidateLicense(value string) bool {
+	return spdxLicenses[value]
+}
+func ValidateContentType(value string) bool {
+	return len(value) <= 128 && ValidateRegex(value, `^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,63}/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,63}$`)
+}
+func ValidateAttributeKey(value string) bool {
+	return ValidateRegex(value, `^[A-Za-z0-9_.-]{1,64}$`)
+}
+func ValidateClaim(value string) bool {
+	return ValidateRegex(value, `^[a-z0-9][a-z0-9_.-]{0,63}$`)
+}